@@ -0,0 +1,171 @@
+package envconf
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/format"
+	"go/token"
+	"io"
+	"path"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// envconfImportPath is the import path of this package, referenced by
+// generated code.
+const envconfImportPath = "github.com/rmerry/envconf"
+
+// WriteAccessors writes the Go source of a package named `pkg` to `w`,
+// providing a typed getter function for every tagged field of the struct (or
+// pointer to struct) `v`:
+//
+//	// HTTPPort returns the value of HTTP_PORT.
+//	func HTTPPort() int {
+//		return Store.Use("HTTPPort").HTTPPort
+//	}
+//
+// The getters read from the package's Store variable, an *envconf.Store of
+// the type of `v`, which the application populates at startup (see
+// Store.Reload). Getters panic if called before the Store is populated.
+// Nested fields are named by concatenating their path, so Server.Port becomes
+// ServerPort(). Because every read goes through Store.Use, Store.Unused
+// reports fields that are never read.
+//
+// The type of `v` must be an exported struct type declared in an importable
+// (non-main) package, and the fields' types must likewise be exported. An
+// error is returned if two getters would share a name (e.g. for Server.Port
+// and a field named ServerPort), if a getter would be named Store, or if two
+// packages referenced by the fields' types share a name.
+func WriteAccessors(w io.Writer, pkg string, v any) error {
+	t := reflect.TypeOf(v)
+	if t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return errors.New("expected struct or pointer to struct")
+	}
+	if t.Name() == "" || t.PkgPath() == "" || t.PkgPath() == "main" {
+		return fmt.Errorf("type %s must be a named type declared in an importable package", t)
+	}
+
+	fields, err := collectFields(reflect.New(t).Interface())
+	if err != nil {
+		return err
+	}
+
+	imports := map[string]string{envconfImportPath: "envconf"}
+	cfgType, err := qualifiedType(t, imports)
+	if err != nil {
+		return err
+	}
+
+	var body bytes.Buffer
+	fmt.Fprintf(&body, "// Store holds the configuration read by the accessors in this package.\n")
+	fmt.Fprintf(&body, "var Store = envconf.NewStore[%s](nil)\n", cfgType)
+	names := map[string]string{"Store": ""}
+	for _, fi := range fields {
+		name := strings.ReplaceAll(fi.Field, ".", "")
+		if other, ok := names[name]; ok {
+			if other == "" {
+				return fmt.Errorf("accessor %s of field %q collides with the Store variable",
+					name, fi.Field)
+			}
+			return fmt.Errorf("accessor %s of field %q collides with that of field %q",
+				name, fi.Field, other)
+		}
+		names[name] = fi.Field
+
+		typ, err := qualifiedType(fi.Type, imports)
+		if err != nil {
+			return fmt.Errorf("field %q: %w", fi.Field, err)
+		}
+		fmt.Fprintf(&body, "\n// %s returns the value of %s.\n", name, fi.Key)
+		fmt.Fprintf(&body, "func %s() %s {\n\treturn Store.Use(%s).%s\n}\n",
+			name, typ, strconv.Quote(fi.Field), fi.Field)
+	}
+	for p, name := range imports {
+		if field, ok := names[name]; ok {
+			return fmt.Errorf("package %s collides with the accessor of field %q", p, field)
+		}
+	}
+
+	var src bytes.Buffer
+	fmt.Fprintf(&src, "// Code generated by envconf. DO NOT EDIT.\n\npackage %s\n\n", pkg)
+	writeImports(&src, imports)
+	src.Write(body.Bytes())
+
+	out, err := format.Source(src.Bytes())
+	if err != nil {
+		return fmt.Errorf("failed to format generated source: %w", err)
+	}
+	_, err = w.Write(out)
+	return err
+}
+
+// qualifiedType returns the Go source representation of `t`, recording the
+// package of every named type it references in `imports` (import path ->
+// package name). It reports an error if `t` references an unexported type,
+// or a package sharing its name with another in `imports`.
+func qualifiedType(t reflect.Type, imports map[string]string) (string, error) {
+	if t.Name() != "" {
+		if t.PkgPath() == "" {
+			return t.Name(), nil // Predeclared type.
+		}
+		if !token.IsExported(t.Name()) {
+			return "", fmt.Errorf("type %s is not exported", t)
+		}
+		name, _, _ := strings.Cut(t.String(), ".")
+		for p, n := range imports {
+			if n == name && p != t.PkgPath() {
+				return "", fmt.Errorf("packages %s and %s share the name %s",
+					p, t.PkgPath(), name)
+			}
+		}
+		imports[t.PkgPath()] = name
+		return name + "." + t.Name(), nil
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		elem, err := qualifiedType(t.Elem(), imports)
+		return "*" + elem, err
+	case reflect.Slice:
+		elem, err := qualifiedType(t.Elem(), imports)
+		return "[]" + elem, err
+	case reflect.Array:
+		elem, err := qualifiedType(t.Elem(), imports)
+		return "[" + strconv.Itoa(t.Len()) + "]" + elem, err
+	case reflect.Map:
+		key, err := qualifiedType(t.Key(), imports)
+		if err != nil {
+			return "", err
+		}
+		elem, err := qualifiedType(t.Elem(), imports)
+		return "map[" + key + "]" + elem, err
+	}
+
+	return t.String(), nil
+}
+
+// writeImports writes an import declaration for `imports` (import path ->
+// package name) to `buf`, sorted by path.
+func writeImports(buf *bytes.Buffer, imports map[string]string) {
+	paths := make([]string, 0, len(imports))
+	for p := range imports {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	buf.WriteString("import (\n")
+	for _, p := range paths {
+		if name := imports[p]; name != path.Base(p) {
+			fmt.Fprintf(buf, "\t%s %q\n", name, p)
+		} else {
+			fmt.Fprintf(buf, "\t%q\n", p)
+		}
+	}
+	buf.WriteString(")\n\n")
+}
@@ -0,0 +1,183 @@
+package envconf
+
+import (
+	"bytes"
+	"go/ast"
+	"go/build"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"math/rand"
+	randv2 "math/rand/v2"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+type AccessorTestConfig struct {
+	HTTPPort int           `env:"HTTP_PORT,default=8080"`
+	Timeout  time.Duration `env:"TIMEOUT"`
+	Hosts    []string      `env:"HOSTS"`
+	Server   struct {
+		Started time.Time `env:"STARTED"`
+	}
+}
+
+func TestWriteAccessors(t *testing.T) {
+	tRun(t, "writes a getter per field", func(t *testing.T) {
+		// Arrange
+		var buf bytes.Buffer
+
+		// Act
+		err := WriteAccessors(&buf, "config", AccessorTestConfig{})
+
+		// Assert
+		assertEqual(t, err, nil)
+		assertEqual(t, typeCheckAccessors(buf.Bytes()), nil)
+		for _, want := range []string{
+			"// Code generated by envconf. DO NOT EDIT.\n\npackage config\n",
+			"import (\n\t\"github.com/rmerry/envconf\"\n\t\"time\"\n)\n",
+			"var Store = envconf.NewStore[envconf.AccessorTestConfig](nil)\n",
+			"// HTTPPort returns the value of HTTP_PORT.\nfunc HTTPPort() int {\n\treturn Store.Use(\"HTTPPort\").HTTPPort\n}\n",
+			"func Timeout() time.Duration {\n",
+			"func Hosts() []string {\n",
+			"func ServerStarted() time.Time {\n\treturn Store.Use(\"Server.Started\").Server.Started\n}\n",
+		} {
+			if !strings.Contains(buf.String(), want) {
+				t.Errorf("expected output to contain %q, got:\n%s", want, buf.String())
+			}
+		}
+	})
+
+	tRun(t, "anonymous types are rejected", func(t *testing.T) {
+		// Act
+		err := WriteAccessors(&bytes.Buffer{}, "config", struct {
+			Port int `env:"PORT"`
+		}{})
+
+		// Assert
+		assertErrorWithSubStr(t, err, "must be a named type declared in an importable package")
+	})
+	tRun(t, "unexported types are rejected", func(t *testing.T) {
+		// Act
+		configErr := WriteAccessors(&bytes.Buffer{}, "config", accessorLimits{})
+		fieldErr := WriteAccessors(&bytes.Buffer{}, "config", AccessorTestLevels{})
+
+		// Assert
+		assertErrorWithSubStr(t, configErr, "type envconf.accessorLimits is not exported")
+		assertErrorWithSubStr(t, fieldErr, `field "Levels": type envconf.accessorLevel is not exported`)
+	})
+
+	tRun(t, "colliding accessors are rejected", func(t *testing.T) {
+		// Act
+		err := WriteAccessors(&bytes.Buffer{}, "config", AccessorTestCollision{})
+		storeErr := WriteAccessors(&bytes.Buffer{}, "config", AccessorTestStore{})
+
+		// Assert
+		assertErrorWithSubStr(t, err,
+			`accessor ServerPort of field "Server.Port" collides with that of field "ServerPort"`)
+		assertErrorWithSubStr(t, storeErr,
+			`accessor Store of field "Store" collides with the Store variable`)
+	})
+
+	tRun(t, "colliding package names are rejected", func(t *testing.T) {
+		// Act
+		err := WriteAccessors(&bytes.Buffer{}, "config", AccessorTestPackages{})
+
+		// Assert
+		assertErrorWithSubStr(t, err, "share the name rand")
+	})
+}
+
+type accessorLimits struct {
+	Max int `env:"MAX"`
+}
+
+type accessorLevel int
+
+type AccessorTestLevels struct {
+	Levels []accessorLevel `env:"LEVELS"`
+}
+
+type AccessorTestCollision struct {
+	Server struct {
+		Port int `env:"PORT"`
+	}
+	ServerPort int `env:"SERVER_PORT"`
+}
+
+type AccessorTestStore struct {
+	Store string `env:"STORE"`
+}
+
+type AccessorTestPackages struct {
+	Source   rand.Source   `env:"SOURCE"`
+	SourceV2 randv2.Source `env:"SOURCE_V2"`
+}
+
+// typeCheckAccessors type-checks the accessors `src`, generated for
+// AccessorTestConfig, against the source of this package.
+func typeCheckAccessors(src []byte) error {
+	fset := token.NewFileSet()
+	pkg, err := build.ImportDir(".", 0)
+	if err != nil {
+		return err
+	}
+	var files []*ast.File
+	for _, name := range pkg.GoFiles {
+		f, err := parser.ParseFile(fset, name, nil, 0)
+		if err != nil {
+			return err
+		}
+		files = append(files, f)
+	}
+
+	// Add the declaration of AccessorTestConfig, which lives in this file.
+	test, err := parser.ParseFile(fset, "accessors_test.go", nil, 0)
+	if err != nil {
+		return err
+	}
+	timeImport := &ast.ImportSpec{
+		Path: &ast.BasicLit{Kind: token.STRING, Value: strconv.Quote("time")},
+	}
+	fixture := &ast.File{Name: test.Name, Decls: []ast.Decl{
+		&ast.GenDecl{Tok: token.IMPORT, Specs: []ast.Spec{timeImport}},
+	}}
+	for _, decl := range test.Decls {
+		if gd, ok := decl.(*ast.GenDecl); ok && gd.Tok == token.TYPE &&
+			gd.Specs[0].(*ast.TypeSpec).Name.Name == "AccessorTestConfig" {
+			fixture.Decls = append(fixture.Decls, gd)
+		}
+	}
+	files = append(files, fixture)
+
+	imp := importer.ForCompiler(fset, "source", nil)
+	envconf, err := (&types.Config{Importer: imp}).Check(envconfImportPath, fset, files, nil)
+	if err != nil {
+		return err
+	}
+
+	f, err := parser.ParseFile(fset, "config.go", src, 0)
+	if err != nil {
+		return err
+	}
+	conf := types.Config{Importer: importerFunc(func(path string) (*types.Package, error) {
+		if path == envconfImportPath {
+			return envconf, nil
+		}
+		return imp.Import(path)
+	})}
+	_, err = conf.Check("config", fset, []*ast.File{f}, nil)
+	return err
+}
+
+// importerFunc is an adapter allowing an ordinary function to be used as a
+// types.Importer.
+type importerFunc func(path string) (*types.Package, error)
+
+// Import calls f(path).
+func (f importerFunc) Import(path string) (*types.Package, error) {
+	return f(path)
+}
@@ -0,0 +1,30 @@
+package envconf
+
+// lookupAliased retrieves the value of the field whose variable is `key`,
+// falling back to each of the field's aliases (see the `alias` attribute) in
+// order. It returns the variable the value was read from or, if none is set,
+// `key`.
+func (o *options) lookupAliased(key string, tag fieldTag) (string, string, bool, error) {
+	val, set, err := o.lookupField(key, tag)
+	if err != nil || set {
+		return val, key, set, err
+	}
+
+	for _, alias := range tag.aliases {
+		aliasKey := o.keyPrefix + alias
+		val, set, err := o.lookupField(aliasKey, tag)
+		if err != nil || set {
+			return val, aliasKey, set, err
+		}
+	}
+
+	return "", key, false, nil
+}
+
+// recordKey reports that the field at path `field` was populated from the
+// variable `key` (see WithKeyRecorder).
+func (o *options) recordKey(field, key string) {
+	if o.keyRecorder != nil {
+		o.keyRecorder(field, key)
+	}
+}
@@ -0,0 +1,95 @@
+package envconf
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestProcess_Alias(t *testing.T) {
+	type testObj struct {
+		Port int    `env:"HTTP_PORT|PORT|LISTEN_PORT,default=8080"`
+		Host string `env:"HTTP_HOST,alias=HOST,required"`
+	}
+
+	tRun(t, "aliases are tried in order", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["PORT"] = "9090"
+		mockEnvVarMap["LISTEN_PORT"] = "7070"
+		mockEnvVarMap["HOST"] = "localhost"
+		used := make(map[string]string)
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv(), WithKeyRecorder(func(field, key string) {
+			used[field] = key
+		}))
+
+		// Assert
+		assertEqual(t, err, nil)
+		assertEqual(t, in.Port, 9090)
+		assertEqual(t, in.Host, "localhost")
+		assertEqual(t, used["Port"], "PORT")
+		assertEqual(t, used["Host"], "HOST")
+	})
+
+	tRun(t, "the field's own variable takes precedence", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["HTTP_PORT"] = "6060"
+		mockEnvVarMap["PORT"] = "9090"
+		mockEnvVarMap["HTTP_HOST"] = "example.com"
+		used := make(map[string]string)
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv(), WithKeyRecorder(func(field, key string) {
+			used[field] = key
+		}))
+
+		// Assert
+		assertEqual(t, err, nil)
+		assertEqual(t, in.Port, 6060)
+		assertEqual(t, used["Port"], "HTTP_PORT")
+	})
+
+	tRun(t, "defaults are not recorded", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["HOST"] = "localhost"
+		used := make(map[string]string)
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv(), WithKeyRecorder(func(field, key string) {
+			used[field] = key
+		}))
+
+		// Assert
+		assertEqual(t, err, nil)
+		assertEqual(t, in.Port, 8080)
+		_, ok := used["Port"]
+		assertEqual(t, ok, false)
+	})
+
+	tRun(t, "the field's own variable is reported as missing", func(t *testing.T) {
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv())
+
+		// Assert
+		var me *MissingError
+		assertEqual(t, errors.As(err, &me), true)
+		assertEqual(t, me.Var, "HTTP_HOST")
+	})
+
+	tRun(t, "aliases are not part of the key", func(t *testing.T) {
+		// Act
+		var keys []string
+		for fi := range Fields(testObj{}) {
+			keys = append(keys, fi.Key)
+		}
+
+		// Assert
+		assertEqual(t, len(keys), 2)
+		assertEqual(t, keys[0], "HTTP_PORT")
+		assertEqual(t, keys[1], "HTTP_HOST")
+	})
+}
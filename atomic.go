@@ -0,0 +1,56 @@
+package envconf
+
+import (
+	"reflect"
+)
+
+// isAtomicType reports whether `t` is one of the sync/atomic types, such as
+// atomic.Int64, atomic.Bool, atomic.Value or atomic.Pointer[T], whose values
+// are written with their Store method rather than assigned.
+func isAtomicType(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct || t.PkgPath() != "sync/atomic" {
+		return false
+	}
+	_, hasLoad := reflect.PointerTo(t).MethodByName("Load")
+	_, hasStore := reflect.PointerTo(t).MethodByName("Store")
+	return hasLoad && hasStore
+}
+
+// setAtomic parses `val` as the type stored by `fv`, an addressable atomic
+// (see isAtomicType), and stores it. atomic.Value fields store `val` as a
+// string; the others store a value of the type their Store method accepts,
+// parsed as a field of that type would be (so attributes such as `duration`
+// apply).
+func setAtomic(fv reflect.Value, val string, tag fieldTag) error {
+	store := fv.Addr().MethodByName("Store")
+	in := store.Type().In(0)
+	if in.Kind() == reflect.Interface {
+		store.Call([]reflect.Value{reflect.ValueOf(val)})
+		return nil
+	}
+
+	v := reflect.New(in).Elem()
+	if err := setField(v, val, tag); err != nil {
+		return err
+	}
+	store.Call([]reflect.Value{v})
+	return nil
+}
+
+// loadAtomic returns the value held by `v`, an atomic (see isAtomicType),
+// and whether one has been stored.
+func loadAtomic(v reflect.Value) (reflect.Value, bool) {
+	if !v.CanAddr() {
+		p := reflect.New(v.Type())
+		p.Elem().Set(v)
+		v = p.Elem()
+	}
+	loaded := v.Addr().MethodByName("Load").Call(nil)[0]
+	if loaded.Kind() == reflect.Interface {
+		if loaded.IsNil() {
+			return reflect.Value{}, false
+		}
+		loaded = loaded.Elem()
+	}
+	return loaded, true
+}
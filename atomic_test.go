@@ -0,0 +1,76 @@
+package envconf
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestProcess_Atomic(t *testing.T) {
+	type testObj struct {
+		MaxConns atomic.Int64           `env:"MAX_CONNS,default=100"`
+		Workers  atomic.Uint32          `env:"WORKERS"`
+		Draining atomic.Bool            `env:"DRAINING"`
+		Banner   atomic.Value           `env:"BANNER"`
+		Timeout  atomic.Int64           `env:"TIMEOUT,duration"`
+		Upstream atomic.Pointer[string] `env:"UPSTREAM"`
+	}
+
+	tRun(t, "values are stored in atomics", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["WORKERS"] = "8"
+		mockEnvVarMap["DRAINING"] = "true"
+		mockEnvVarMap["BANNER"] = "welcome"
+		mockEnvVarMap["TIMEOUT"] = "1m"
+		mockEnvVarMap["UPSTREAM"] = "10.0.0.1:80"
+
+		// Act
+		var in testObj
+		Process(&in, mockEnv())
+
+		// Assert
+		assertEqual(t, in.MaxConns.Load(), int64(100))
+		assertEqual(t, in.Workers.Load(), uint32(8))
+		assertEqual(t, in.Draining.Load(), true)
+		assertEqual(t, in.Banner.Load(), any("welcome"))
+		assertEqual(t, time.Duration(in.Timeout.Load()), time.Minute)
+		assertEqual(t, *in.Upstream.Load(), "10.0.0.1:80")
+	})
+
+	tRun(t, "invalid values are reported", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["WORKERS"] = "-1"
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv())
+
+		// Assert
+		var pe *ParseError
+		assertEqual(t, errors.As(err, &pe), true)
+		assertEqual(t, pe.Kind, "atomic.Uint32")
+		assertEqual(t, in.Workers.Load(), uint32(0))
+	})
+
+	tRun(t, "values are marshalled", func(t *testing.T) {
+		// Arrange
+		var in testObj
+		upstream := "db:5432"
+		in.MaxConns.Store(5)
+		in.Banner.Store("hi")
+		in.Timeout.Store(int64(2 * time.Second))
+		in.Upstream.Store(&upstream)
+
+		// Act
+		env, err := Marshal(&in)
+
+		// Assert
+		assertEqual(t, err, nil)
+		assertEqual(t, env["MAX_CONNS"], "5")
+		assertEqual(t, env["DRAINING"], "false")
+		assertEqual(t, env["BANNER"], "hi")
+		assertEqual(t, env["TIMEOUT"], "2s")
+		assertEqual(t, env["UPSTREAM"], "db:5432")
+	})
+}
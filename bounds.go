@@ -0,0 +1,68 @@
+package envconf
+
+import (
+	"cmp"
+	"fmt"
+	"reflect"
+)
+
+// isNumericKind reports whether the `min` and `max` attributes apply to
+// values of type `t`: those of the predeclared numeric kinds, including named
+// types such as time.Duration.
+func isNumericKind(t reflect.Type) bool {
+	return isOneOfKind(t) && t.Kind() != reflect.String && t.Kind() != reflect.Bool
+}
+
+// setBounded parses `val` into `fv` as setField does, provided the result
+// lies within the bounds set by the `min` and `max` attributes, each parsed
+// the same way (so `min=1s` bounds a duration and `max=1GiB` a byte size).
+// `fv` is left untouched otherwise.
+func setBounded(fv reflect.Value, val string, tag fieldTag) error {
+	lo, hi := tag.min, tag.max
+	tag.min, tag.max = "", ""
+
+	v := reflect.New(fv.Type()).Elem()
+	if err := setField(v, val, tag); err != nil {
+		return err
+	}
+	if lo != "" {
+		c, err := compareBound(v, lo, tag, tagAttrMin)
+		if err != nil {
+			return err
+		}
+		if c < 0 {
+			return fmt.Errorf("%q is less than the minimum of %s", val, lo)
+		}
+	}
+	if hi != "" {
+		c, err := compareBound(v, hi, tag, tagAttrMax)
+		if err != nil {
+			return err
+		}
+		if c > 0 {
+			return fmt.Errorf("%q is greater than the maximum of %s", val, hi)
+		}
+	}
+
+	fv.Set(v)
+	return nil
+}
+
+// compareBound returns -1, 0 or +1 as `v` is less than, equal to or greater
+// than `bound`, the argument of the attribute `attr`, parsed as a value of
+// v's type.
+func compareBound(v reflect.Value, bound string, tag fieldTag, attr string) (int, error) {
+	b := reflect.New(v.Type()).Elem()
+	if err := setField(b, bound, tag); err != nil {
+		return 0, fmt.Errorf("invalid %s struct tag attribute: %q: %w", attr, bound, err)
+	}
+
+	switch {
+	case b.CanInt():
+		return cmp.Compare(v.Int(), b.Int()), nil
+	case b.CanUint():
+		return cmp.Compare(v.Uint(), b.Uint()), nil
+	default:
+		return cmp.Compare(v.Float(), b.Float()), nil
+	}
+}
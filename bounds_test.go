@@ -0,0 +1,80 @@
+package envconf
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestProcess_Bounds(t *testing.T) {
+	type testObj struct {
+		Port    uint16        `env:"PORT,min=1,max=65535"`
+		Workers int           `env:"WORKERS,default=4,min=1"`
+		Ratio   float64       `env:"RATIO,max=1"`
+		Timeout time.Duration `env:"TIMEOUT,duration,min=1s,max=5m"`
+		Cache   int64         `env:"CACHE,bytes,max=1GiB"`
+		Delays  []int         `env:"DELAYS,min=0,max=100"`
+	}
+
+	tRun(t, "values within bounds are accepted", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["PORT"] = "65535"
+		mockEnvVarMap["RATIO"] = "-2.5"
+		mockEnvVarMap["TIMEOUT"] = "1s"
+		mockEnvVarMap["CACHE"] = "512MiB"
+		mockEnvVarMap["DELAYS"] = "0,100"
+
+		// Act
+		var in testObj
+		Process(&in, mockEnv())
+
+		// Assert
+		assertEqual(t, in.Port, uint16(65535))
+		assertEqual(t, in.Workers, 4)
+		assertEqual(t, in.Timeout, time.Second)
+		assertEqual(t, in.Cache, int64(512<<20))
+		assertEqual(t, in.Delays[1], 100)
+	})
+
+	tRun(t, "values outside bounds are reported precisely", func(t *testing.T) {
+		tests := map[string][2]string{
+			"PORT":    {"0", `"0" is less than the minimum of 1`},
+			"WORKERS": {"0", `"0" is less than the minimum of 1`},
+			"RATIO":   {"1.01", `"1.01" is greater than the maximum of 1`},
+			"TIMEOUT": {"10m", `"10m" is greater than the maximum of 5m`},
+			"CACHE":   {"2GiB", `"2GiB" is greater than the maximum of 1GiB`},
+			"DELAYS":  {"5,-1", `element 1: "-1" is less than the minimum of 0`},
+		}
+		for key, tt := range tests {
+			// Arrange
+			mockEnvVarMap[key] = tt[0]
+
+			// Act
+			var in testObj
+			err := ProcessE(&in, mockEnv())
+			delete(mockEnvVarMap, key)
+
+			// Assert
+			var pe *ParseError
+			assertEqual(t, errors.As(err, &pe), true)
+			assertEqual(t, pe.Var, key)
+			assertErrorWithSubStr(t, pe.Err, tt[1])
+		}
+	})
+
+	tRun(t, "malformed bounds are reported even when unset", func(t *testing.T) {
+		// Arrange
+		var in struct {
+			N        int             `env:"N,max=ten"`
+			Timeouts []time.Duration `env:"TIMEOUTS,duration,min=soon"`
+		}
+
+		// Act
+		err := ProcessE(&in, mockEnv())
+
+		// Assert
+		assertEqual(t, errors.Is(err, ErrParse), false)
+		assertErrorWithSubStr(t, err, `field "N": invalid max struct tag attribute: "ten"`)
+		assertErrorWithSubStr(t, err, `field "Timeouts": invalid min struct tag attribute: "soon"`)
+	})
+}
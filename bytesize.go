@@ -0,0 +1,68 @@
+package envconf
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// byteUnits maps the (lower-cased) unit suffixes accepted by the `bytes`
+// attribute to their size in bytes. Decimal units are powers of 1000 and
+// binary units powers of 1024.
+var byteUnits = map[string]uint64{
+	"":    1,
+	"b":   1,
+	"k":   1e3,
+	"kb":  1e3,
+	"kib": 1 << 10,
+	"m":   1e6,
+	"mb":  1e6,
+	"mib": 1 << 20,
+	"g":   1e9,
+	"gb":  1e9,
+	"gib": 1 << 30,
+	"t":   1e12,
+	"tb":  1e12,
+	"tib": 1 << 40,
+	"p":   1e15,
+	"pb":  1e15,
+	"pib": 1 << 50,
+	"e":   1e18,
+	"eb":  1e18,
+	"eib": 1 << 60,
+}
+
+// parseByteSize parses a human-readable byte size such as "512MiB", "10GB"
+// or "1.5 KiB" (see the `bytes` attribute) into a number of bytes no greater
+// than `limit`. Units are case-insensitive; a bare number is a count of
+// bytes. Fractional sizes must amount to a whole number of bytes.
+func parseByteSize(val string, limit uint64) (uint64, error) {
+	s := strings.TrimSpace(val)
+	i := strings.IndexFunc(s, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if i < 0 {
+		i = len(s)
+	}
+	num, unit := s[:i], strings.ToLower(strings.TrimSpace(s[i:]))
+
+	r, ok := new(big.Rat).SetString(num)
+	if num == "" || !ok {
+		return 0, errors.New("invalid byte size")
+	}
+	mult, ok := byteUnits[unit]
+	if !ok {
+		return 0, fmt.Errorf("unknown byte size unit %q", s[i:])
+	}
+	r.Mul(r, new(big.Rat).SetUint64(mult))
+	if !r.IsInt() {
+		return 0, errors.New("byte size is not a whole number of bytes")
+	}
+	n := r.Num()
+	if !n.IsUint64() || n.Uint64() > limit {
+		return 0, errors.New("byte size out of range")
+	}
+
+	return n.Uint64(), nil
+}
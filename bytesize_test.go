@@ -0,0 +1,68 @@
+package envconf
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestProcess_ByteSize(t *testing.T) {
+	type testObj struct {
+		Cache    int64    `env:"CACHE_SIZE,bytes"`
+		Upload   uint64   `env:"UPLOAD_LIMIT,bytes,default=10MB"`
+		Buffer   uint16   `env:"BUFFER,bytes"`
+		Segments []uint32 `env:"SEGMENTS,bytes"`
+	}
+
+	tRun(t, "sizes are parsed with units", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["CACHE_SIZE"] = "512MiB"
+		mockEnvVarMap["BUFFER"] = "1.5 kib"
+		mockEnvVarMap["SEGMENTS"] = "4096,1K,2GB"
+
+		// Act
+		var in testObj
+		Process(&in, mockEnv())
+
+		// Assert
+		assertEqual(t, in.Cache, int64(512<<20))
+		assertEqual(t, in.Upload, uint64(10_000_000))
+		assertEqual(t, in.Buffer, uint16(1536))
+		assertEqual(t, in.Segments[0], uint32(4096))
+		assertEqual(t, in.Segments[1], uint32(1000))
+		assertEqual(t, in.Segments[2], uint32(2_000_000_000))
+	})
+
+	tRun(t, "invalid sizes are reported", func(t *testing.T) {
+		tests := map[string]string{
+			"10XB":   `unknown byte size unit "XB"`,
+			"MiB":    `invalid byte size`,
+			"0.5B":   `not a whole number of bytes`,
+			"64KiB":  `out of range`,
+			"-10MiB": `invalid byte size`,
+		}
+		for val, msg := range tests {
+			// Arrange
+			mockEnvVarMap["BUFFER"] = val
+
+			// Act
+			var in testObj
+			err := ProcessE(&in, mockEnv())
+
+			// Assert
+			var pe *ParseError
+			assertEqual(t, errors.As(err, &pe), true)
+			assertErrorWithSubStr(t, pe.Err, msg)
+		}
+	})
+
+	tRun(t, "values round trip", func(t *testing.T) {
+		// Arrange
+		in := testObj{Cache: 1 << 30, Upload: 5, Segments: []uint32{1, 2}}
+
+		// Act
+		err := RoundTripCheck(in)
+
+		// Assert
+		assertEqual(t, err, nil)
+	})
+}
@@ -0,0 +1,49 @@
+package envconf
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Checksum returns a deterministic, hex encoded SHA-256 digest of the supplied
+// variables. The digest depends only on the set of key/value pairs, not on map
+// iteration order, so deployment tooling can use it (e.g. as a pod annotation)
+// to force a restart only when the configuration actually changes.
+func Checksum(vars map[string]string) string {
+	keys := make([]string, 0, len(vars))
+	for k := range vars {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	h := sha256.New()
+	for _, k := range keys {
+		// Length prefixing keeps ("A", "B=C") and ("A=B", "C") distinct.
+		writeLenPrefixed(h, k)
+		writeLenPrefixed(h, vars[k])
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// ChecksumEnviron is like Checksum but accepts variables in "KEY=VALUE" form,
+// as returned by os.Environ. Where a key appears more than once the last value
+// wins.
+func ChecksumEnviron(environ []string) string {
+	vars := make(map[string]string, len(environ))
+	for _, kv := range environ {
+		k, v, _ := strings.Cut(kv, "=")
+		vars[k] = v
+	}
+
+	return Checksum(vars)
+}
+
+// writeLenPrefixed writes `s` to `w` preceded by its length.
+func writeLenPrefixed(w io.Writer, s string) {
+	w.Write([]byte(strconv.Itoa(len(s)) + ":" + s))
+}
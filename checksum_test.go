@@ -0,0 +1,45 @@
+package envconf
+
+import "testing"
+
+func TestChecksum(t *testing.T) {
+	tRun(t, "is independent of insertion order", func(t *testing.T) {
+		// Arrange
+		a := map[string]string{"A": "1", "B": "2"}
+		b := map[string]string{"B": "2", "A": "1"}
+
+		// Assert
+		assertEqual(t, Checksum(a), Checksum(b))
+	})
+
+	tRun(t, "changes when a value changes", func(t *testing.T) {
+		// Arrange
+		a := map[string]string{"A": "1"}
+		b := map[string]string{"A": "2"}
+
+		// Assert
+		if Checksum(a) == Checksum(b) {
+			t.Errorf("expected checksums to differ")
+		}
+	})
+
+	tRun(t, "is not fooled by shifting separators", func(t *testing.T) {
+		// Arrange
+		a := map[string]string{"A": "B=C"}
+		b := map[string]string{"A=B": "C"}
+
+		// Assert
+		if Checksum(a) == Checksum(b) {
+			t.Errorf("expected checksums to differ")
+		}
+	})
+
+	tRun(t, "environ form matches map form", func(t *testing.T) {
+		// Arrange
+		environ := []string{"B=2", "A=0", "A=1"}
+
+		// Assert
+		assertEqual(t, ChecksumEnviron(environ),
+			Checksum(map[string]string{"A": "1", "B": "2"}))
+	})
+}
@@ -0,0 +1,236 @@
+package envconf
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CloudProvider identifies a cloud instance metadata service.
+type CloudProvider int
+
+// Supported cloud providers.
+const (
+	AWS CloudProvider = iota + 1
+	GCP
+	Azure
+)
+
+// Variables answered by a CloudLookuper regardless of provider.
+const (
+	CloudRegionKey     = "CLOUD_REGION"
+	CloudZoneKey       = "CLOUD_ZONE"
+	CloudInstanceIDKey = "CLOUD_INSTANCE_ID"
+)
+
+const (
+	cloudDefaultTimeout = 2 * time.Second
+
+	// awsTokenTTL is the lifetime requested for IMDSv2 session tokens, which
+	// are reused until shortly before they expire.
+	awsTokenTTL = 6 * time.Hour
+)
+
+// cloudPaths holds the provider specific metadata paths of the well-known
+// Cloud* keys.
+var cloudPaths = map[CloudProvider]map[string]string{
+	AWS: {
+		CloudRegionKey:     "placement/region",
+		CloudZoneKey:       "placement/availability-zone",
+		CloudInstanceIDKey: "instance-id",
+	},
+	GCP: {
+		CloudRegionKey:     "instance/zone",
+		CloudZoneKey:       "instance/zone",
+		CloudInstanceIDKey: "instance/id",
+	},
+	Azure: {
+		CloudRegionKey:     "compute/location",
+		CloudZoneKey:       "compute/zone",
+		CloudInstanceIDKey: "compute/vmId",
+	},
+}
+
+// CloudLookuper is a Lookuper backed by a cloud provider's instance metadata
+// endpoint (AWS IMDSv2, GCP metadata server or Azure IMDS).
+//
+// It answers CloudRegionKey, CloudZoneKey and CloudInstanceIDKey for every
+// provider, plus any additional variables listed in Keys. It is typically
+// placed after an OSLookuper in a MultiLookuper so that injected environment
+// variables take precedence:
+//
+//	l := envconf.MultiLookuper(
+//		envconf.OSLookuper{},
+//		&envconf.CloudLookuper{Provider: envconf.AWS},
+//	)
+//	envconf.Process(&cfg, envconf.WithLookuper(l))
+//
+// Any failure to reach the metadata endpoint is reported as the variable not
+// being present. AWS session tokens are reused across lookups until they
+// expire, so a CloudLookuper must not be copied after first use.
+type CloudLookuper struct {
+	Provider CloudProvider
+
+	// Keys maps additional variable names to metadata paths, relative to the
+	// provider's metadata root (e.g. "instance-type" for AWS or
+	// "instance/machine-type" for GCP).
+	Keys map[string]string
+
+	// Endpoint overrides the provider's metadata base URL.
+	Endpoint string
+
+	// Client is the HTTP client used for requests. If nil a client with a
+	// short timeout is used.
+	Client *http.Client
+
+	mu          sync.Mutex // Guards the fields below.
+	token       string     // The cached IMDSv2 session token.
+	tokenExpiry time.Time
+}
+
+// Lookup retrieves the metadata value corresponding to `key`.
+func (c *CloudLookuper) Lookup(key string) (string, bool) {
+	val, ok, _ := c.LookupContext(context.Background(), key)
+	return val, ok
+}
+
+// LookupContext is like Lookup but abandons the request, returning ctx.Err(),
+// if `ctx` is done first.
+func (c *CloudLookuper) LookupContext(ctx context.Context, key string) (string, bool, error) {
+	p, ok := c.Keys[key]
+	if !ok {
+		p, ok = c.wellKnownPath(key)
+	}
+	if !ok {
+		return "", false, nil
+	}
+
+	val, err := c.fetch(ctx, p)
+	if ctx.Err() != nil {
+		return "", false, ctx.Err()
+	}
+	if err != nil || val == "" {
+		return "", false, nil
+	}
+
+	// GCP only reports fully qualified zones (projects/N/zones/ZONE), the
+	// region is derived by stripping the zone suffix.
+	if c.Provider == GCP {
+		switch key {
+		case CloudZoneKey:
+			val = path.Base(val)
+		case CloudRegionKey:
+			val = path.Base(val)
+			if i := strings.LastIndex(val, "-"); i > 0 {
+				val = val[:i]
+			}
+		}
+	}
+
+	return val, true, nil
+}
+
+// wellKnownPath returns the provider specific metadata path for one of the
+// well-known Cloud* keys.
+func (c *CloudLookuper) wellKnownPath(key string) (string, bool) {
+	p, ok := cloudPaths[c.Provider][key]
+	return p, ok
+}
+
+// fetch retrieves the metadata value stored at path `p`.
+func (c *CloudLookuper) fetch(ctx context.Context, p string) (string, error) {
+	var (
+		url    string
+		header = make(http.Header)
+	)
+	switch c.Provider {
+	case AWS:
+		token, err := c.awsToken(ctx)
+		if err != nil {
+			return "", err
+		}
+		url = c.endpoint("http://169.254.169.254") + "/latest/meta-data/" + p
+		header.Set("X-aws-ec2-metadata-token", token)
+	case GCP:
+		url = c.endpoint("http://metadata.google.internal") +
+			"/computeMetadata/v1/" + p
+		header.Set("Metadata-Flavor", "Google")
+	case Azure:
+		url = c.endpoint("http://169.254.169.254") + "/metadata/instance/" + p +
+			"?api-version=2021-02-01&format=text"
+		header.Set("Metadata", "true")
+	default:
+		return "", fmt.Errorf("unknown cloud provider: %d", c.Provider)
+	}
+
+	return c.do(ctx, http.MethodGet, url, header)
+}
+
+// awsToken returns an IMDSv2 session token, requesting a new one only if the
+// cached token is missing or about to expire.
+func (c *CloudLookuper) awsToken(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.token != "" && time.Now().Before(c.tokenExpiry) {
+		return c.token, nil
+	}
+
+	header := make(http.Header)
+	header.Set("X-aws-ec2-metadata-token-ttl-seconds",
+		strconv.Itoa(int(awsTokenTTL/time.Second)))
+	requested := time.Now()
+	token, err := c.do(ctx, http.MethodPut,
+		c.endpoint("http://169.254.169.254")+"/latest/api/token", header)
+	if err != nil {
+		return "", err
+	}
+
+	// Renew a minute early so that tokens do not expire in flight.
+	c.token, c.tokenExpiry = token, requested.Add(awsTokenTTL-time.Minute)
+	return token, nil
+}
+
+// do performs an HTTP request returning the trimmed response body.
+func (c *CloudLookuper) do(ctx context.Context, method, url string, header http.Header) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header = header
+
+	client := c.Client
+	if client == nil {
+		client = &http.Client{Timeout: cloudDefaultTimeout}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status %d from %s", resp.StatusCode, url)
+	}
+
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(b)), nil
+}
+
+// endpoint returns the configured endpoint or `def` if none is set.
+func (c *CloudLookuper) endpoint(def string) string {
+	if c.Endpoint != "" {
+		return strings.TrimSuffix(c.Endpoint, "/")
+	}
+	return def
+}
@@ -0,0 +1,124 @@
+package envconf
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCloudLookuper(t *testing.T) {
+	tRun(t, "aws uses an imdsv2 session token", func(t *testing.T) {
+		// Arrange
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case r.Method == http.MethodPut && r.URL.Path == "/latest/api/token":
+				w.Write([]byte("token"))
+			case r.Header.Get("X-aws-ec2-metadata-token") != "token":
+				w.WriteHeader(http.StatusUnauthorized)
+			case r.URL.Path == "/latest/meta-data/placement/region":
+				w.Write([]byte("eu-west-1"))
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		defer srv.Close()
+		l := &CloudLookuper{Provider: AWS, Endpoint: srv.URL}
+
+		// Act
+		region, ok := l.Lookup(CloudRegionKey)
+		_, zoneOK := l.Lookup(CloudZoneKey)
+
+		// Assert
+		assertEqual(t, ok, true)
+		assertEqual(t, region, "eu-west-1")
+		assertEqual(t, zoneOK, false)
+	})
+
+	tRun(t, "aws session tokens are reused", func(t *testing.T) {
+		// Arrange
+		var tokens int
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodPut {
+				tokens++
+				w.Write([]byte("token"))
+				return
+			}
+			w.Write([]byte("value"))
+		}))
+		defer srv.Close()
+		l := &CloudLookuper{Provider: AWS, Endpoint: srv.URL}
+
+		// Act
+		l.Lookup(CloudRegionKey)
+		l.Lookup(CloudZoneKey)
+		l.Lookup(CloudInstanceIDKey)
+
+		// Assert
+		assertEqual(t, tokens, 1)
+	})
+
+	tRun(t, "gcp region is derived from zone", func(t *testing.T) {
+		// Arrange
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Metadata-Flavor") != "Google" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			w.Write([]byte("projects/123/zones/us-central1-a"))
+		}))
+		defer srv.Close()
+		l := &CloudLookuper{Provider: GCP, Endpoint: srv.URL}
+
+		// Act
+		region, _ := l.Lookup(CloudRegionKey)
+		zone, _ := l.Lookup(CloudZoneKey)
+
+		// Assert
+		assertEqual(t, region, "us-central1")
+		assertEqual(t, zone, "us-central1-a")
+	})
+
+	tRun(t, "azure custom keys are resolved", func(t *testing.T) {
+		// Arrange
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Metadata") != "true" || r.URL.Query().Get("format") != "text" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			w.Write([]byte(r.URL.Path))
+		}))
+		defer srv.Close()
+		l := &CloudLookuper{
+			Provider: Azure,
+			Endpoint: srv.URL,
+			Keys:     map[string]string{"VM_SIZE": "compute/vmSize"},
+		}
+
+		// Act
+		size, _ := l.Lookup("VM_SIZE")
+
+		// Assert
+		assertEqual(t, size, "/metadata/instance/compute/vmSize")
+	})
+
+	tRun(t, "falls back behind environment in a multi lookuper", func(t *testing.T) {
+		// Arrange
+		type testObj struct {
+			Region string `env:"CLOUD_REGION"`
+		}
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("projects/1/zones/europe-west2-b"))
+		}))
+		defer srv.Close()
+
+		// Act
+		var in testObj
+		Process(&in, WithLookuper(MultiLookuper(
+			MapLookuper{},
+			&CloudLookuper{Provider: GCP, Endpoint: srv.URL},
+		)))
+
+		// Assert
+		assertEqual(t, in.Region, "europe-west2")
+	})
+}
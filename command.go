@@ -0,0 +1,15 @@
+package envconf
+
+import (
+	"context"
+	"os/exec"
+)
+
+// runCommand runs the named program returning its standard output. It is used
+// by the lookupers and resolvers that delegate to external tools. The program
+// is killed if `ctx` is done before it exits.
+//
+// Makes unit testing easier.
+var runCommand = func(ctx context.Context, name string, args ...string) ([]byte, error) {
+	return exec.CommandContext(ctx, name, args...).Output()
+}
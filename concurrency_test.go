@@ -0,0 +1,74 @@
+package envconf
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// These tests are most useful when run with the race detector enabled
+// (go test -race).
+func TestProcess_Concurrent(t *testing.T) {
+	tRun(t, "different targets may be processed in parallel", func(t *testing.T) {
+		// Arrange
+		type testObj struct {
+			ID     int    `env:"ID,required"`
+			Name   string `env:"NAME,default=unnamed"`
+			Nested *struct {
+				Debug bool `env:"DEBUG"`
+			}
+		}
+		const n = 32
+
+		var (
+			wg   sync.WaitGroup
+			errs = make([]error, n)
+			outs = make([]testObj, n)
+		)
+
+		// Act
+		for i := 0; i < n; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				l := MapLookuper{"ID": strconv.Itoa(i), "DEBUG": "true"}
+				errs[i] = ProcessE(&outs[i], WithLookuper(l))
+			}(i)
+		}
+		wg.Wait()
+
+		// Assert
+		for i := 0; i < n; i++ {
+			assertEqual(t, errs[i], nil)
+			assertEqual(t, outs[i].ID, i)
+			assertEqual(t, outs[i].Name, "unnamed")
+			assertEqual(t, outs[i].Nested.Debug, true)
+		}
+	})
+
+	tRun(t, "a shared lookuper may be used in parallel", func(t *testing.T) {
+		// Arrange
+		type testObj struct {
+			Host string `env:"HOST"`
+		}
+		l := MultiLookuper(MapLookuper{}, MapLookuper{"HOST": "localhost"})
+
+		var wg sync.WaitGroup
+
+		// Act & Assert
+		for i := 0; i < 32; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				var in testObj
+				if err := ProcessE(&in, WithLookuper(l), WithProfilerLabels()); err != nil {
+					t.Error(err)
+				}
+				if in.Host != "localhost" {
+					t.Errorf("expected localhost, got: %q", in.Host)
+				}
+			}()
+		}
+		wg.Wait()
+	})
+}
@@ -0,0 +1,157 @@
+package envconf
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// constraintRe matches constraint expressions: two dotted field paths
+// separated by a comparison operator.
+var constraintRe = regexp.MustCompile(
+	`^\s*([A-Za-z_][\w.]*)\s*(<=|>=|==|!=|<|>)\s*([A-Za-z_][\w.]*)\s*$`)
+
+// constraint is a parsed constraint expression.
+type constraint struct {
+	expr        string
+	left, right string // Dotted field paths.
+	op          string
+}
+
+// WithConstraints adds cross-field constraints that are checked once every
+// field has been populated successfully, replacing hand-written validation
+// such as:
+//
+//	envconf.Process(&cfg, envconf.WithConstraints(
+//		"MaxConns >= MinConns",
+//		"HTTP.ReadTimeout < HTTP.IdleTimeout",
+//	))
+//
+// Each constraint compares two fields, named by their dotted paths, using one
+// of the operators <, <=, >, >=, == or !=. Fields must be of the same type;
+// the ordering operators require numeric, string, time.Time or time.Duration
+// fields. Every violated constraint is reported as a *ConstraintError. A
+// malformed constraint is reported as an error.
+func WithConstraints(exprs ...string) Option {
+	return func(o *options) {
+		o.constraints = append(o.constraints, exprs...)
+	}
+}
+
+// checkConstraints evaluates each of `exprs` against the struct `v`.
+func (o *options) checkConstraints(v reflect.Value, exprs []string) error {
+	var errs []error
+	for _, expr := range exprs {
+		m := constraintRe.FindStringSubmatch(expr)
+		if m == nil {
+			errs = append(errs, fmt.Errorf("invalid constraint %q", expr))
+			continue
+		}
+		c := constraint{expr: strings.TrimSpace(expr), left: m[1], op: m[2], right: m[3]}
+		if err := c.check(o, v); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// check evaluates `c` against the struct `v`, whose tags are parsed per `o`.
+func (c constraint) check(o *options, v reflect.Value) error {
+	left, leftSecret, err := o.fieldByPath(v, c.left)
+	if err != nil {
+		return fmt.Errorf("invalid constraint %q: %w", c.expr, err)
+	}
+	right, rightSecret, err := o.fieldByPath(v, c.right)
+	if err != nil {
+		return fmt.Errorf("invalid constraint %q: %w", c.expr, err)
+	}
+	if left.Type() != right.Type() {
+		return fmt.Errorf("invalid constraint %q: cannot compare %s with %s",
+			c.expr, left.Type(), right.Type())
+	}
+
+	ok, err := compareValues(left, right, c.op)
+	if err != nil {
+		return fmt.Errorf("invalid constraint %q: %w", c.expr, err)
+	}
+	if ok {
+		return nil
+	}
+
+	e := &ConstraintError{Constraint: c.expr, Left: c.left, Right: c.right}
+	if !leftSecret && !rightSecret {
+		e.LeftValue = fmt.Sprint(left.Interface())
+		e.RightValue = fmt.Sprint(right.Interface())
+	}
+	return e
+}
+
+// fieldByPath returns the field of the struct `v` at the dotted `path`,
+// dereferencing struct pointers, and whether it is marked `secret`.
+func (o *options) fieldByPath(v reflect.Value, path string) (reflect.Value, bool, error) {
+	var secret bool
+	for _, name := range strings.Split(path, ".") {
+		if v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				return reflect.Value{}, false, fmt.Errorf("field %q is nil", path)
+			}
+			v = v.Elem()
+		}
+		if v.Kind() != reflect.Struct {
+			return reflect.Value{}, false, fmt.Errorf("no field %q", path)
+		}
+		sf, ok := v.Type().FieldByName(name)
+		if !ok || !sf.IsExported() {
+			return reflect.Value{}, false, fmt.Errorf("no field %q", path)
+		}
+		if tag, err := o.parseTag(sf.Tag); err == nil {
+			secret = tag.secret
+		}
+		v = v.FieldByIndex(sf.Index)
+	}
+
+	return v, secret, nil
+}
+
+// compareValues reports whether `a op b` holds.
+func compareValues(a, b reflect.Value, op string) (bool, error) {
+	var c int
+	switch {
+	case a.Type() == timeType:
+		c = a.Interface().(time.Time).Compare(b.Interface().(time.Time))
+	case a.CanInt():
+		c = cmp.Compare(a.Int(), b.Int())
+	case a.CanUint():
+		c = cmp.Compare(a.Uint(), b.Uint())
+	case a.CanFloat():
+		c = cmp.Compare(a.Float(), b.Float())
+	case a.Kind() == reflect.String:
+		c = cmp.Compare(a.String(), b.String())
+	case op == "==" || op == "!=":
+		if !a.Comparable() {
+			return false, fmt.Errorf("%s values are not comparable", a.Type())
+		}
+		return a.Equal(b) == (op == "=="), nil
+	default:
+		return false, fmt.Errorf("%s values are not ordered", a.Type())
+	}
+
+	switch op {
+	case "<":
+		return c < 0, nil
+	case "<=":
+		return c <= 0, nil
+	case ">":
+		return c > 0, nil
+	case ">=":
+		return c >= 0, nil
+	case "==":
+		return c == 0, nil
+	}
+	return c != 0, nil
+}
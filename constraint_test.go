@@ -0,0 +1,95 @@
+package envconf
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestProcess_Constraints(t *testing.T) {
+	type testObj struct {
+		MinConns int `env:"MIN_CONNS,default=1"`
+		MaxConns int `env:"MAX_CONNS,default=10"`
+		HTTP     struct {
+			ReadTimeout time.Duration `env:"READ_TIMEOUT,default=5"`
+			IdleTimeout time.Duration `env:"IDLE_TIMEOUT,default=60"`
+		}
+		Primary   string `env:"PRIMARY,secret"`
+		Secondary string `env:"SECONDARY,secret"`
+	}
+
+	tRun(t, "satisfied constraints pass", func(t *testing.T) {
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv(), WithConstraints(
+			"MaxConns >= MinConns",
+			"HTTP.ReadTimeout < HTTP.IdleTimeout",
+		))
+
+		// Assert
+		assertEqual(t, err, nil)
+	})
+
+	tRun(t, "violations name both fields", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["MIN_CONNS"] = "20"
+		mockEnvVarMap["IDLE_TIMEOUT"] = "5"
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv(), WithConstraints(
+			"MaxConns >= MinConns",
+			"HTTP.ReadTimeout < HTTP.IdleTimeout",
+		))
+
+		// Assert
+		assertEqual(t, errors.Is(err, ErrConstraint), true)
+		assertErrorWithSubStr(t, err, `constraint "MaxConns >= MinConns" violated: MaxConns is 10, MinConns is 20`)
+		var ce *ConstraintError
+		assertEqual(t, errors.As(err, &ce), true)
+		assertEqual(t, ce.Left, "MaxConns")
+		assertEqual(t, ce.Right, "MinConns")
+		assertErrorWithSubStr(t, err, `constraint "HTTP.ReadTimeout < HTTP.IdleTimeout" violated`)
+	})
+
+	tRun(t, "secret values are omitted", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["PRIMARY"] = "hunter2"
+		mockEnvVarMap["SECONDARY"] = "hunter2"
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv(), WithConstraints("Primary != Secondary"))
+
+		// Assert
+		assertEqual(t, err.Error(), `constraint "Primary != Secondary" violated`)
+	})
+
+	tRun(t, "constraints are not checked after field errors", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["MIN_CONNS"] = "x"
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv(), WithConstraints("MaxConns < MinConns"))
+
+		// Assert
+		assertEqual(t, errors.Is(err, ErrParse), true)
+		assertEqual(t, errors.Is(err, ErrConstraint), false)
+	})
+
+	tRun(t, "malformed constraints are reported", func(t *testing.T) {
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv(), WithConstraints(
+			"MaxConns >> MinConns",
+			"MaxConns > Missing",
+			"MaxConns > HTTP.ReadTimeout",
+		))
+
+		// Assert
+		assertErrorWithSubStr(t, err, `invalid constraint "MaxConns >> MinConns"`)
+		assertErrorWithSubStr(t, err, `invalid constraint "MaxConns > Missing": no field "Missing"`)
+		assertErrorWithSubStr(t, err, `cannot compare int with time.Duration`)
+	})
+}
@@ -0,0 +1,160 @@
+package envconf
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CronSpec is a cron schedule, validated when parsed so that malformed
+// schedules are reported by Process rather than at the scheduler's first
+// tick. It holds the expression unchanged, ready to pass to a scheduler:
+//
+//	c.AddFunc(string(cfg.Schedule), rotateLogs)
+//
+// Expressions have five fields (minute, hour, day of month, month and day of
+// week) or six, with a leading seconds field. Each field is "*" or a
+// comma-separated list of values and ranges ("1-5"), each optionally
+// followed by a step ("*/15", "0-30/10"). Months and days of the week may be
+// given by name ("JAN", "mon"), Sunday is 0 or 7, and "?" may stand for "*"
+// in the day fields. The descriptors @yearly (or @annually), @monthly,
+// @weekly, @daily (or @midnight), @hourly and "@every DURATION" are also
+// accepted.
+type CronSpec string
+
+// cronField describes a field of a cron expression.
+type cronField struct {
+	name     string
+	min, max int
+	names    []string // Names for the values from min, if any.
+	question bool     // Whether "?" may stand for "*".
+}
+
+var (
+	cronSeconds = cronField{name: "second", max: 59}
+	cronFields  = []cronField{
+		{name: "minute", max: 59},
+		{name: "hour", max: 23},
+		{name: "day of month", min: 1, max: 31, question: true},
+		{name: "month", min: 1, max: 12, names: []string{
+			"jan", "feb", "mar", "apr", "may", "jun",
+			"jul", "aug", "sep", "oct", "nov", "dec"}},
+		{name: "day of week", max: 7, names: []string{
+			"sun", "mon", "tue", "wed", "thu", "fri", "sat"}, question: true},
+	}
+)
+
+// cronDescriptors are the predefined schedules accepted in place of fields.
+var cronDescriptors = map[string]bool{
+	"@yearly": true, "@annually": true, "@monthly": true, "@weekly": true,
+	"@daily": true, "@midnight": true, "@hourly": true,
+}
+
+// ParseCronSpec validates `s` as a cron expression (see CronSpec).
+func ParseCronSpec(s string) (CronSpec, error) {
+	expr := strings.TrimSpace(s)
+	if err := validateCron(expr); err != nil {
+		return "", fmt.Errorf("invalid cron expression %q: %w", s, err)
+	}
+	return CronSpec(expr), nil
+}
+
+// validateCron returns an error describing the first problem with `expr`.
+func validateCron(expr string) error {
+	if strings.HasPrefix(expr, "@") {
+		if every, ok := strings.CutPrefix(expr, "@every "); ok {
+			d, err := time.ParseDuration(strings.TrimSpace(every))
+			if err != nil || d <= 0 {
+				return fmt.Errorf("invalid @every duration %q", every)
+			}
+			return nil
+		}
+		if !cronDescriptors[strings.ToLower(expr)] {
+			return fmt.Errorf("unknown descriptor %q", expr)
+		}
+		return nil
+	}
+
+	fields := strings.Fields(expr)
+	specs := cronFields
+	switch len(fields) {
+	case 5:
+	case 6:
+		specs = append([]cronField{cronSeconds}, cronFields...)
+	default:
+		return fmt.Errorf("expected 5 or 6 fields, got %d", len(fields))
+	}
+	for i, f := range fields {
+		if err := specs[i].validate(f); err != nil {
+			return fmt.Errorf("%s field: %w", specs[i].name, err)
+		}
+	}
+
+	return nil
+}
+
+// validate returns an error if `s` is not a valid value of the field.
+func (f cronField) validate(s string) error {
+	for _, item := range strings.Split(s, ",") {
+		rng, step, hasStep := strings.Cut(item, "/")
+		if hasStep {
+			if n, err := strconv.Atoi(step); err != nil || n < 1 {
+				return fmt.Errorf("invalid step %q", step)
+			}
+		}
+
+		if rng == "*" || rng == "?" && f.question {
+			continue
+		}
+		lo, hi, isRange := strings.Cut(rng, "-")
+		start, err := f.value(lo)
+		if err != nil {
+			return err
+		}
+		if !isRange {
+			continue
+		}
+		end, err := f.value(hi)
+		if err != nil {
+			return err
+		}
+		if end < start {
+			return fmt.Errorf("invalid range %q", rng)
+		}
+	}
+
+	return nil
+}
+
+// value parses a single value of the field, a number or a name.
+func (f cronField) value(s string) (int, error) {
+	for i, name := range f.names {
+		if strings.EqualFold(s, name) {
+			return f.min + i, nil
+		}
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", s)
+	}
+	if n < f.min || n > f.max {
+		return 0, fmt.Errorf("value %d out of range [%d, %d]", n, f.min, f.max)
+	}
+	return n, nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (c *CronSpec) UnmarshalText(b []byte) error {
+	parsed, err := ParseCronSpec(string(b))
+	if err != nil {
+		return err
+	}
+	*c = parsed
+	return nil
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (c CronSpec) MarshalText() ([]byte, error) {
+	return []byte(c), nil
+}
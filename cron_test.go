@@ -0,0 +1,95 @@
+package envconf
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseCronSpec(t *testing.T) {
+	tRun(t, "valid expressions are accepted", func(t *testing.T) {
+		for _, s := range []string{
+			"*/15 * * * *",
+			"0 3 * * MON-FRI",
+			"30 0 1,15 jan,jul ?",
+			"0 0-30/10 9-17 * * 1-5",
+			"0 0 * * 7",
+			"@daily",
+			"@every 90m",
+		} {
+			// Act
+			c, err := ParseCronSpec(s)
+
+			// Assert
+			assertEqual(t, err, nil)
+			assertEqual(t, string(c), s)
+		}
+	})
+
+	tRun(t, "malformed expressions are rejected", func(t *testing.T) {
+		tests := map[string]string{
+			"* * * *":         "expected 5 or 6 fields, got 4",
+			"60 * * * *":      "minute field: value 60 out of range [0, 59]",
+			"0 0 0 * *":       "day of month field: value 0 out of range [1, 31]",
+			"0 0 * foo *":     `month field: invalid value "foo"`,
+			"0 0 * * 5-1":     `day of week field: invalid range "5-1"`,
+			"*/0 * * * *":     `minute field: invalid step "0"`,
+			"? * * * *":       `minute field: invalid value "?"`,
+			"@fortnightly":    `unknown descriptor "@fortnightly"`,
+			"@every sometime": `invalid @every duration "sometime"`,
+		}
+		for s, msg := range tests {
+			// Act
+			_, err := ParseCronSpec(s)
+
+			// Assert
+			assertErrorWithSubStr(t, err, msg)
+		}
+	})
+}
+
+func TestProcess_CronSpec(t *testing.T) {
+	type testObj struct {
+		Rotate  CronSpec  `env:"ROTATE_SCHEDULE,default=@daily"`
+		Backups *CronSpec `env:"BACKUP_SCHEDULE"`
+	}
+
+	tRun(t, "schedules are validated", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["BACKUP_SCHEDULE"] = "0 2 * * SUN"
+
+		// Act
+		var in testObj
+		Process(&in, mockEnv())
+
+		// Assert
+		assertEqual(t, in.Rotate, CronSpec("@daily"))
+		assertEqual(t, *in.Backups, CronSpec("0 2 * * SUN"))
+	})
+
+	tRun(t, "malformed schedules fail processing", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["BACKUP_SCHEDULE"] = "0 25 * * *"
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv())
+
+		// Assert
+		var pe *ParseError
+		assertEqual(t, errors.As(err, &pe), true)
+		assertEqual(t, pe.Var, "BACKUP_SCHEDULE")
+		assertErrorWithSubStr(t, pe.Err, "hour field: value 25 out of range [0, 23]")
+	})
+
+	tRun(t, "values round trip", func(t *testing.T) {
+		// Arrange
+		backups := CronSpec("*/5 * * * *")
+		in := testObj{Rotate: "0 0 1 * *", Backups: &backups}
+
+		// Act
+		err := RoundTripCheck(in)
+
+		// Assert
+		assertEqual(t, err, nil)
+	})
+}
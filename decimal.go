@@ -0,0 +1,123 @@
+package envconf
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Decimal is an arbitrary-precision decimal number, parsed from values such
+// as "19.99", "-0.0005" or "1.25e3" without the rounding of float64, and
+// keeping the scale it was written with ("12.50" stays "12.50"). The zero
+// value is 0.
+//
+// Applications using a third-party decimal type may instead register its
+// constructor (see RegisterParser), e.g. for github.com/shopspring/decimal:
+//
+//	envconf.RegisterParser(decimal.NewFromString)
+type Decimal struct {
+	unscaled *big.Int // The value is unscaled × 10^-scale; nil for zero.
+	scale    int32
+}
+
+// maxDecimalExponent bounds the power of ten by which an exponent may scale
+// a parsed Decimal up or down, limiting the memory a value (or its string
+// form) can demand.
+const maxDecimalExponent = 1 << 16
+
+// ParseDecimal parses `s`, an optionally signed decimal number with an
+// optional exponent, as a Decimal.
+func ParseDecimal(s string) (Decimal, error) {
+	mantissa, exp, hasExp := strings.Cut(strings.ToLower(s), "e")
+	intPart, frac, _ := strings.Cut(mantissa, ".")
+
+	digits := strings.TrimLeft(intPart, "+-")
+	if len(intPart)-len(digits) > 1 || digits+frac == "" ||
+		!isDecimalDigits(digits) || !isDecimalDigits(frac) {
+		return Decimal{}, fmt.Errorf("invalid decimal %q", s)
+	}
+
+	scale := int64(len(frac))
+	if hasExp {
+		e, err := strconv.ParseInt(exp, 10, 32)
+		if err != nil {
+			return Decimal{}, fmt.Errorf("invalid decimal %q", s)
+		}
+		scale -= e
+	}
+
+	if scale < -maxDecimalExponent || scale > maxDecimalExponent {
+		return Decimal{}, fmt.Errorf("decimal %q out of range", s)
+	}
+
+	unscaled, _ := new(big.Int).SetString(intPart+frac, 10)
+	if scale < 0 {
+		// Exponents beyond the digits given scale the value up.
+		pow := new(big.Int).Exp(big.NewInt(10), big.NewInt(-scale), nil)
+		unscaled.Mul(unscaled, pow)
+		scale = 0
+	}
+
+	return Decimal{unscaled: unscaled, scale: int32(scale)}, nil
+}
+
+// isDecimalDigits reports whether `s` consists only of decimal digits.
+func isDecimalDigits(s string) bool {
+	return strings.Trim(s, "0123456789") == ""
+}
+
+// String returns the number in plain decimal notation, with as many
+// fractional digits as its scale.
+func (d Decimal) String() string {
+	if d.unscaled == nil {
+		return "0"
+	}
+
+	digits := new(big.Int).Abs(d.unscaled).String()
+	if d.scale > 0 {
+		if pad := int(d.scale) - len(digits) + 1; pad > 0 {
+			digits = strings.Repeat("0", pad) + digits
+		}
+		digits = digits[:len(digits)-int(d.scale)] + "." + digits[len(digits)-int(d.scale):]
+	}
+	if d.unscaled.Sign() < 0 {
+		return "-" + digits
+	}
+	return digits
+}
+
+// Rat returns the exact value of `d` as a big.Rat.
+func (d Decimal) Rat() *big.Rat {
+	if d.unscaled == nil {
+		return new(big.Rat)
+	}
+	denom := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(d.scale)), nil)
+	return new(big.Rat).SetFrac(d.unscaled, denom)
+}
+
+// Float64 returns the nearest float64 to `d`, and whether it is exact.
+func (d Decimal) Float64() (float64, bool) {
+	return d.Rat().Float64()
+}
+
+// Cmp compares `d` and `e`, returning -1, 0 or +1 as `d` is less than, equal
+// to or greater than `e`. Numbers of different scales may be equal.
+func (d Decimal) Cmp(e Decimal) int {
+	return d.Rat().Cmp(e.Rat())
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (d *Decimal) UnmarshalText(b []byte) error {
+	parsed, err := ParseDecimal(string(b))
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (d Decimal) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
@@ -0,0 +1,126 @@
+package envconf
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseDecimal(t *testing.T) {
+	tRun(t, "decimals are parsed exactly", func(t *testing.T) {
+		tests := map[string]string{
+			"19.99":     "19.99",
+			"12.50":     "12.50",
+			"-0.0005":   "-0.0005",
+			"+7":        "7",
+			".5":        "0.5",
+			"3.":        "3",
+			"1.25e3":    "1250",
+			"125E-4":    "0.0125",
+			"0.1000001": "0.1000001",
+		}
+		for s, want := range tests {
+			// Act
+			d, err := ParseDecimal(s)
+
+			// Assert
+			assertEqual(t, err, nil)
+			assertEqual(t, d.String(), want)
+		}
+	})
+
+	tRun(t, "malformed decimals are rejected", func(t *testing.T) {
+		for _, s := range []string{"", ".", "1.2.3", "--1", "1e", "1e1.5", "12a", "0x10", "1,000"} {
+			// Act
+			_, err := ParseDecimal(s)
+
+			// Assert
+			assertErrorWithSubStr(t, err, "invalid decimal")
+		}
+	})
+
+	tRun(t, "huge exponents are rejected", func(t *testing.T) {
+		// Act
+		_, err := ParseDecimal("1e999999999")
+
+		// Assert
+		assertErrorWithSubStr(t, err, `decimal "1e999999999" out of range`)
+	})
+
+	tRun(t, "huge negative exponents are rejected", func(t *testing.T) {
+		// Act
+		_, err := ParseDecimal("1e-2000000000")
+
+		// Assert
+		assertErrorWithSubStr(t, err, `decimal "1e-2000000000" out of range`)
+	})
+
+	tRun(t, "values compare and convert exactly", func(t *testing.T) {
+		// Arrange
+		a, _ := ParseDecimal("0.10")
+		b, _ := ParseDecimal("1e-1")
+		c, _ := ParseDecimal("0.3")
+
+		// Act
+		f, exact := c.Float64()
+
+		// Assert
+		assertEqual(t, a.Cmp(b), 0)
+		assertEqual(t, a.Cmp(c), -1)
+		assertEqual(t, c.Rat().String(), "3/10")
+		assertEqual(t, f, 0.3)
+		assertEqual(t, exact, false)
+		assertEqual(t, Decimal{}.String(), "0")
+	})
+}
+
+func TestProcess_Decimal(t *testing.T) {
+	type testObj struct {
+		Price Decimal   `env:"PRICE"`
+		Fee   *Decimal  `env:"FEE,default=0.30"`
+		Tiers []Decimal `env:"TIERS"`
+	}
+
+	tRun(t, "decimals are parsed", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["PRICE"] = "19.99"
+		mockEnvVarMap["TIERS"] = "0.1,0.2"
+
+		// Act
+		var in testObj
+		Process(&in, mockEnv())
+
+		// Assert
+		assertEqual(t, in.Price.String(), "19.99")
+		assertEqual(t, in.Fee.String(), "0.30")
+		assertEqual(t, in.Tiers[1].Rat().String(), "1/5")
+	})
+
+	tRun(t, "invalid decimals are reported", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["PRICE"] = "£19.99"
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv())
+
+		// Assert
+		var pe *ParseError
+		assertEqual(t, errors.As(err, &pe), true)
+		assertEqual(t, pe.Kind, "envconf.Decimal")
+	})
+
+	tRun(t, "values round trip", func(t *testing.T) {
+		// Arrange
+		price, _ := ParseDecimal("1234567890.123456789")
+		fee, _ := ParseDecimal("-0.05")
+		in := testObj{Price: price, Fee: &fee}
+
+		// Act
+		env, err := Marshal(in)
+
+		// Assert
+		assertEqual(t, err, nil)
+		assertEqual(t, env["PRICE"], "1234567890.123456789")
+		assertEqual(t, env["FEE"], "-0.05")
+	})
+}
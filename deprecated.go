@@ -0,0 +1,58 @@
+package envconf
+
+import (
+	"log/slog"
+)
+
+// DeprecationWarning reports that a value was read from a variable marked
+// with the `deprecated` attribute, so that operators can migrate before the
+// variable is removed.
+type DeprecationWarning struct {
+	Var         string // The deprecated variable, e.g. "LISTEN_PORT".
+	Field       string // The dotted path of the struct field.
+	Replacement string // The variable to use instead; empty if there is none.
+}
+
+// logDeprecation is the default deprecation handler (see
+// WithDeprecationHandler), logging `w` with slog.Warn.
+func logDeprecation(w DeprecationWarning) {
+	attrs := []any{slog.String("var", w.Var), slog.String("field", w.Field)}
+	if w.Replacement != "" {
+		attrs = append(attrs, slog.String("replacement", w.Replacement))
+	}
+	slog.Warn("envconf: deprecated environment variable in use", attrs...)
+}
+
+// lookupDeprecated retrieves the value of the field at path `field` whose
+// variable is `key`, preferring the replacement named by the field's
+// `deprecated` attribute, if any, and reporting a DeprecationWarning if the
+// value is read from `key` or one of its aliases. It returns the variable the
+// value was read from or, if neither is set, the one operators should set.
+func (o *options) lookupDeprecated(key, field string, tag fieldTag) (string, string, bool, error) {
+	if tag.replacement != "" {
+		newKey := o.keyPrefix + tag.replacement
+		val, set, err := o.lookupField(newKey, tag)
+		if err != nil || set {
+			return val, newKey, set, err
+		}
+	}
+
+	val, key, set, err := o.lookupAliased(key, tag)
+	if err != nil || !set {
+		if tag.replacement != "" {
+			key = o.keyPrefix + tag.replacement
+		}
+		return val, key, set, err
+	}
+
+	w := DeprecationWarning{Var: key, Field: field}
+	if tag.replacement != "" {
+		w.Replacement = o.keyPrefix + tag.replacement
+	}
+	if o.warnDeprecated != nil {
+		o.warnDeprecated(w)
+	} else {
+		logDeprecation(w)
+	}
+	return val, key, true, nil
+}
@@ -0,0 +1,84 @@
+package envconf
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestProcess_Deprecated(t *testing.T) {
+	type testObj struct {
+		Port  int    `env:"LISTEN_PORT,deprecated=HTTP_PORT,default=8080"`
+		Host  string `env:"LISTEN_HOST,deprecated=HTTP_HOST,required"`
+		Debug bool   `env:"VERBOSE,deprecated"`
+	}
+
+	var warnings []DeprecationWarning
+	record := WithDeprecationHandler(func(w DeprecationWarning) {
+		warnings = append(warnings, w)
+	})
+
+	tRun(t, "deprecated variables are read with a warning", func(t *testing.T) {
+		// Arrange
+		warnings = nil
+		mockEnvVarMap["LISTEN_PORT"] = "9090"
+		mockEnvVarMap["LISTEN_HOST"] = "localhost"
+		mockEnvVarMap["VERBOSE"] = "true"
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv(), record)
+
+		// Assert
+		assertEqual(t, err, nil)
+		assertEqual(t, in.Port, 9090)
+		assertEqual(t, in.Debug, true)
+		assertEqual(t, len(warnings), 3)
+		assertEqual(t, warnings[0], DeprecationWarning{
+			Var: "LISTEN_PORT", Field: "Port", Replacement: "HTTP_PORT"})
+		assertEqual(t, warnings[2], DeprecationWarning{Var: "VERBOSE", Field: "Debug"})
+	})
+
+	tRun(t, "replacements are preferred without a warning", func(t *testing.T) {
+		// Arrange
+		warnings = nil
+		mockEnvVarMap["LISTEN_PORT"] = "9090"
+		mockEnvVarMap["HTTP_PORT"] = "7070"
+		mockEnvVarMap["HTTP_HOST"] = "localhost"
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv(), record)
+
+		// Assert
+		assertEqual(t, err, nil)
+		assertEqual(t, in.Port, 7070)
+		assertEqual(t, in.Host, "localhost")
+		assertEqual(t, len(warnings), 0)
+	})
+
+	tRun(t, "unset variables do not warn", func(t *testing.T) {
+		// Arrange
+		warnings = nil
+		mockEnvVarMap["HTTP_HOST"] = "localhost"
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv(), record)
+
+		// Assert
+		assertEqual(t, err, nil)
+		assertEqual(t, in.Port, 8080)
+		assertEqual(t, len(warnings), 0)
+	})
+
+	tRun(t, "the replacement is reported as missing", func(t *testing.T) {
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv(), record)
+
+		// Assert
+		var me *MissingError
+		assertEqual(t, errors.As(err, &me), true)
+		assertEqual(t, me.Var, "HTTP_HOST")
+	})
+}
@@ -0,0 +1,53 @@
+package envconf
+
+import (
+	"io/fs"
+	"strings"
+)
+
+// DirLookuper is a Lookuper that reads each variable from a file of the same
+// name in FS, trimming a single trailing newline. This is the layout used by
+// Kubernetes secret volumes and Docker's /run/secrets.
+//
+// All reads are confined to FS, so supplying a scoped file system sandboxes
+// lookups to a directory even when keys originate from untrusted input:
+//
+//	envconf.DirLookuper{FS: os.DirFS("/run/secrets")}
+//
+// or, where symlinks must not escape the directory either, the fs.FS of an
+// os.Root. Keys that are not valid fs.FS paths (see fs.ValidPath), such as
+// those containing "..", are reported as not present.
+type DirLookuper struct {
+	FS fs.FS
+}
+
+// Lookup returns the contents of the file named `key`.
+func (d DirLookuper) Lookup(key string) (string, bool) {
+	if !fs.ValidPath(key) || strings.Contains(key, "/") {
+		return "", false
+	}
+
+	b, err := fs.ReadFile(d.FS, key)
+	if err != nil {
+		return "", false
+	}
+
+	return strings.TrimSuffix(string(b), "\n"), true
+}
+
+// Keys returns the names of the regular files in the root of FS.
+func (d DirLookuper) Keys() []string {
+	entries, err := fs.ReadDir(d.FS, ".")
+	if err != nil {
+		return nil
+	}
+
+	var keys []string
+	for _, e := range entries {
+		if e.Type().IsRegular() {
+			keys = append(keys, e.Name())
+		}
+	}
+
+	return keys
+}
@@ -0,0 +1,52 @@
+package envconf
+
+import (
+	"slices"
+	"testing"
+	"testing/fstest"
+)
+
+func TestDirLookuper(t *testing.T) {
+	// Pre Arrange
+	fsys := fstest.MapFS{
+		"DB_PASSWORD":    {Data: []byte("s3cret\n")},
+		"API_KEY":        {Data: []byte("key")},
+		"nested/API_KEY": {Data: []byte("nested")},
+	}
+	l := DirLookuper{FS: fsys}
+
+	tRun(t, "reads files named after keys", func(t *testing.T) {
+		// Arrange
+		type testObj struct {
+			Password string `env:"DB_PASSWORD"`
+			APIKey   string `env:"API_KEY"`
+		}
+
+		// Act
+		var in testObj
+		Process(&in, WithLookuper(l))
+
+		// Assert
+		assertEqual(t, in.Password, "s3cret")
+		assertEqual(t, in.APIKey, "key")
+	})
+
+	tRun(t, "paths outside the root are not present", func(t *testing.T) {
+		// Act
+		_, escaped := l.Lookup("../etc/passwd")
+		_, nested := l.Lookup("nested/API_KEY")
+
+		// Assert
+		assertEqual(t, escaped, false)
+		assertEqual(t, nested, false)
+	})
+
+	tRun(t, "keys lists regular files", func(t *testing.T) {
+		// Act
+		keys := l.Keys()
+		slices.Sort(keys)
+
+		// Assert
+		assertEqual(t, slices.Equal(keys, []string{"API_KEY", "DB_PASSWORD"}), true)
+	})
+}
@@ -0,0 +1,100 @@
+package envconf
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sync"
+)
+
+// Document formats accepted as tag attributes, decoding the whole value into
+// the field.
+const (
+	formatJSON = "json"
+	formatYAML = "yaml"
+)
+
+// documentCodec converts between a document format and Go values.
+type documentCodec struct {
+	marshal   func(any) ([]byte, error)
+	unmarshal func([]byte, any) error
+}
+
+var (
+	codecsMu sync.RWMutex
+	codecs   = map[string]documentCodec{
+		formatJSON: {json.Marshal, json.Unmarshal},
+	}
+)
+
+// RegisterYAML enables the `yaml` attribute, which decodes YAML documents
+// (such as values rendered by Helm charts) into struct, map and slice fields.
+// envconf has no YAML implementation of its own, so the application supplies
+// one:
+//
+//	func init() {
+//		envconf.RegisterYAML(yaml.Marshal, yaml.Unmarshal)
+//	}
+//
+// `marshal` is used by Marshal and the generators. Registering again replaces
+// the previous functions. RegisterYAML is safe for concurrent use but is
+// intended to be called during initialisation.
+func RegisterYAML(marshal func(any) ([]byte, error), unmarshal func([]byte, any) error) {
+	codecsMu.Lock()
+	defer codecsMu.Unlock()
+
+	codecs[formatYAML] = documentCodec{marshal, unmarshal}
+}
+
+// lookupCodec returns the codec registered for the document `format`.
+func lookupCodec(format string) (documentCodec, bool) {
+	codecsMu.RLock()
+	defer codecsMu.RUnlock()
+
+	c, ok := codecs[format]
+	return c, ok
+}
+
+// documentFormat returns the document format of values of a field of type
+// `t` with `tag`: that named by the `json` or `yaml` attribute, JSON for
+// json.RawMessage fields, or "" if the value is not a document.
+func documentFormat(t reflect.Type, tag fieldTag) string {
+	if tag.format == "" && t == rawMessageType {
+		return formatJSON
+	}
+	return tag.format
+}
+
+// decodeDocument unmarshals `val` into `fv` with the codec of `format`.
+func decodeDocument(fv reflect.Value, val, format string) error {
+	c, ok := lookupCodec(format)
+	if !ok {
+		return errors.New("no decoder registered for " + format)
+	}
+	p := reflect.New(fv.Type())
+	if err := c.unmarshal([]byte(val), p.Interface()); err != nil {
+		return err
+	}
+	fv.Set(p.Elem())
+	return nil
+}
+
+// encodeDocument marshals `v` with the codec of `format`. Nil pointers, maps
+// and slices are formatted as empty values, which are processed as unset.
+func encodeDocument(v reflect.Value, format string) (string, bool) {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice:
+		if v.IsNil() {
+			return "", true
+		}
+	}
+	c, ok := lookupCodec(format)
+	if !ok {
+		return "", false
+	}
+	b, err := c.marshal(v.Interface())
+	if err != nil {
+		return "", false
+	}
+	return string(b), true
+}
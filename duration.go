@@ -0,0 +1,72 @@
+package envconf
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// durationUnits maps the units accepted by the `duration` attribute beyond
+// those of time.ParseDuration to their length in hours.
+var durationUnits = map[string]time.Duration{
+	"d": 24,
+	"w": 7 * 24,
+}
+
+// parseDuration parses `val` as time.ParseDuration does, also accepting days
+// ("d") and weeks ("w") in any combination with its units (e.g. "7d", "2w" or
+// "1d12h"). A day is always 24 hours, regardless of daylight saving time.
+func parseDuration(val string) (time.Duration, error) {
+	s, neg := val, false
+	if s != "" && (s[0] == '-' || s[0] == '+') {
+		s, neg = s[1:], s[0] == '-'
+	}
+	if s == "0" {
+		return 0, nil
+	}
+	if s == "" {
+		return 0, fmt.Errorf("invalid duration %q", val)
+	}
+
+	var total time.Duration
+	for s != "" {
+		// Each component is a decimal number followed by a unit.
+		i := strings.IndexFunc(s, func(r rune) bool { return (r < '0' || r > '9') && r != '.' })
+		if i <= 0 {
+			return 0, fmt.Errorf("invalid duration %q", val)
+		}
+		j := strings.IndexFunc(s[i:], func(r rune) bool { return r >= '0' && r <= '9' || r == '.' })
+		if j < 0 {
+			j = len(s) - i
+		}
+		num, unit := s[:i], s[i:i+j]
+		s = s[i+j:]
+
+		var (
+			d   time.Duration
+			err error
+		)
+		if hours, ok := durationUnits[unit]; ok {
+			d, err = time.ParseDuration(num + "h")
+			if err == nil && d > math.MaxInt64/hours {
+				err = fmt.Errorf("duration %q out of range", val)
+			}
+			d *= hours
+		} else {
+			d, err = time.ParseDuration(num + unit)
+		}
+		if err != nil {
+			return 0, err
+		}
+		if total > math.MaxInt64-d {
+			return 0, fmt.Errorf("duration %q out of range", val)
+		}
+		total += d
+	}
+	if neg {
+		total = -total
+	}
+
+	return total, nil
+}
@@ -0,0 +1,83 @@
+package envconf
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestProcess_Duration(t *testing.T) {
+	type testObj struct {
+		Retention time.Duration   `env:"RETENTION,duration"`
+		Rotation  time.Duration   `env:"ROTATION,duration,default=2w"`
+		Grace     *time.Duration  `env:"GRACE,duration"`
+		Backoff   []time.Duration `env:"BACKOFF,duration"`
+		Raw       time.Duration   `env:"RAW"`
+	}
+
+	tRun(t, "days and weeks are accepted alongside standard units", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["RETENTION"] = "1d12h"
+		mockEnvVarMap["GRACE"] = "-1.5d"
+		mockEnvVarMap["BACKOFF"] = "100ms,1w2d3h4m5s,0"
+		mockEnvVarMap["RAW"] = "1000"
+
+		// Act
+		var in testObj
+		Process(&in, mockEnv())
+
+		// Assert
+		assertEqual(t, in.Retention, 36*time.Hour)
+		assertEqual(t, in.Rotation, 14*24*time.Hour)
+		assertEqual(t, *in.Grace, -36*time.Hour)
+		assertEqual(t, in.Backoff[0], 100*time.Millisecond)
+		assertEqual(t, in.Backoff[1], 9*24*time.Hour+3*time.Hour+4*time.Minute+5*time.Second)
+		assertEqual(t, in.Backoff[2], time.Duration(0))
+		assertEqual(t, in.Raw, time.Microsecond) // Nanoseconds without the attribute.
+	})
+
+	tRun(t, "invalid durations are reported", func(t *testing.T) {
+		tests := map[string]string{
+			"7":      `invalid duration "7"`,
+			"d":      `invalid duration "d"`,
+			"3y":     `unknown unit "y"`,
+			"20000w": `out of range`,
+			"1d.":    `invalid duration`,
+		}
+		for val, msg := range tests {
+			// Arrange
+			mockEnvVarMap["RETENTION"] = val
+
+			// Act
+			var in testObj
+			err := ProcessE(&in, mockEnv())
+
+			// Assert
+			var pe *ParseError
+			assertEqual(t, errors.As(err, &pe), true)
+			assertEqual(t, pe.Var, "RETENTION")
+			assertErrorWithSubStr(t, pe.Err, msg)
+		}
+	})
+
+	tRun(t, "values round trip", func(t *testing.T) {
+		// Arrange
+		grace := 90 * time.Second
+		in := testObj{
+			Retention: 30 * 24 * time.Hour,
+			Grace:     &grace,
+			Backoff:   []time.Duration{time.Millisecond, -time.Hour},
+			Raw:       5,
+		}
+
+		// Act
+		env, err := Marshal(in)
+		rtErr := RoundTripCheck(in)
+
+		// Assert
+		assertEqual(t, err, nil)
+		assertEqual(t, env["RETENTION"], "720h0m0s")
+		assertEqual(t, env["RAW"], "5")
+		assertEqual(t, rtErr, nil)
+	})
+}
@@ -20,6 +20,45 @@ The following basic types are supported:
   - float64
   - complex64
   - complex128
+  - time.Time
+  - *time.Location, from an IANA zone name (e.g. "Europe/London"), "UTC" or
+    "Local"
+  - url.URL
+  - os.FileMode (fs.FileMode), from octal permissions such as "0640" or
+    "2775", including the setuid, setgid and sticky bits
+  - mail.Address, from an RFC 5322 address (e.g. "Alerts <alerts@example.com>");
+    []mail.Address fields are parsed as an address list, so commas within
+    quoted display names do not split addresses
+  - net.TCPAddr and HostPort, from "host:port" values (TCPAddr hosts must be
+    IP addresses)
+  - net.IPNet, from CIDR notation (net.IP, netip.Addr and netip.Prefix are
+    supported as encoding.TextUnmarshaler implementations)
+  - big.Int, big.Float and big.Rat (big.Float values get enough precision
+    to hold every digit supplied)
+  - []byte
+  - json.RawMessage, from a JSON document (see also the `json` attribute)
+  - any type for which a parser is registered (see RegisterParser), which
+    takes precedence over all others but the `json` attribute
+  - interface types with implementations registered by name (see
+    RegisterImplementation)
+  - any type implementing Setter
+  - any type implementing encoding.TextUnmarshaler (e.g. netip.Addr)
+  - any type implementing encoding.BinaryUnmarshaler, from a value decoded
+    per the `encoding` attribute
+  - slices of any of the above (other than []byte), from delimited values
+  - arrays of any of the above, from delimited values with exactly as many
+    elements as the array's length
+  - maps with keys and values of any of the types above other than slices,
+    arrays and maps, from delimited key:value pairs (e.g. "a:1,b:2"), each
+    converted as a field of its type would be (so attributes such as
+    `duration` apply to both)
+  - pointers to any of the above, allocated only when a value is supplied
+  - sync/atomic types (atomic.Int64, atomic.Bool, atomic.Pointer[T] and so
+    on), written with Store as the type they hold; atomic.Value fields hold
+    strings
+
+Integer and floating-point values may separate digits with underscores, e.g.
+"1_000_000".
 
 Usage:
 
@@ -37,33 +76,364 @@ Usage:
 
 Supported Tag Attributes:
 
+Attributes are separated by commas. An argument containing commas may be
+enclosed in single quotes, as in `default='a,b'`, or have its commas escaped
+with a backslash, written `\\,` within the struct tag's quotes, as in
+`default=a\\,b`. Equals signs within arguments need no escaping.
+
   - default=VALUE - use VALUE when environment variable not set.
 
+    The default may instead be given by a separate envDefault tag, as in
+    `env:"PORT" envDefault:"8080"`, for compatibility with structs written
+    for github.com/caarlos0/env. It needs no quoting (see above).
+
+  - defaultFrom=VAR - use the value of the variable VAR, if set, when the
+    environment variable is not, before falling back to any `default`, e.g.
+    `env:"METRICS_ADDR,defaultFrom=LISTEN_ADDR"`. The value is treated as a
+    default. Within a struct with a `prefix`, VAR is prefixed likewise.
+
   - required - panic if environment variable not set.
 
     Note: If both `required` and `default` are
     provided the `required` tag is ignored.
+
+  - allowempty - treat a variable set to the empty string as a value rather
+    than as unset: the field is set to its zero value, in preference to any
+    default, and satisfies `required`.
+
+  - group=NAME, required_group - place the field in the named group of
+    alternatives; if any of a group's fields is marked `required_group`,
+    processing fails unless at least one of the group's variables is set
+    (defaults do not count), reporting a MissingGroupError naming them all,
+    e.g. `env:"API_KEY,group=auth,required_group"` alongside
+    `env:"OAUTH_TOKEN,group=auth"`. Groups may span nested structs.
+
+  - required_if=VAR[=VALUE] - treat the field as required only when the
+    variable VAR is set (to VALUE, if given), e.g.
+    `env:"TLS_KEY,required_if=TLS_ENABLED=true"`. Within a struct with a
+    `prefix`, VAR is prefixed likewise. If another field of the struct is
+    read from VAR, VAR is read as that field is (see `source` and `alias`).
+
+  - layout=LAYOUT - parse time.Time values with LAYOUT, either a Go reference
+    layout (e.g. "2006-01-02") or one of the named layouts RFC3339,
+    RFC3339Nano, RFC1123, RFC1123Z, RFC822, RFC822Z, RFC850, ANSIC, Kitchen,
+    DateTime, DateOnly, TimeOnly, unix, unixmilli, unixmicro or unixnano.
+    Without it RFC 3339 and common zone-less date/time forms are accepted.
+
+  - encoding=ENCODING - decode []byte and string values (and values passed
+    to UnmarshalBinary) as "base64", "base64url", "hex" or "raw" (the
+    default). Types implementing both encoding.TextUnmarshaler and
+    encoding.BinaryUnmarshaler are only decoded when ENCODING is given.
+
+  - base64 - shorthand for encoding=base64, e.g. for PEM blobs and other
+    multiline secrets transported base64-encoded. Line breaks in the encoded
+    value are ignored.
+
+  - separator=SEP - split slice values and map pairs on SEP rather than ",",
+    e.g. when elements themselves contain commas. Go escapes are interpreted
+    (`separator=\n` splits on newlines), and `separator=whitespace` splits on
+    runs of spaces, tabs and newlines, ignoring any at either end.
+
+  - kvseparator=SEP - split map pairs into key and value on SEP rather than
+    ":".
+
+  - expand - substitute references to other variables (${VAR} or $VAR, see
+    os.Expand) in the value, or the default, with their values, so that
+    composite values such as "${HOST}:${PORT}" need no glue code. See also
+    WithWindowsExpansion.
+
+  - file - treat the value as the path of a file, such as a mounted Docker
+    or Kubernetes secret, and use the file's contents, with leading and
+    trailing whitespace trimmed, instead, e.g. `env:"DB_PASSWORD_FILE,file"`.
+    Files are opened in the file system set by WithFileSystem, if any, and
+    are read no further than the limit set by WithMaxValueLength.
+
+  - unset - remove the variable from the process environment (see
+    os.Unsetenv) once the field has been populated from it, narrowing the
+    window in which child processes and /proc expose a secret. The variable
+    is kept if its value fails to parse, and defaults unset nothing. Only
+    values read from the process environment (see OSLookuper) are removed,
+    so Validate and other sources leave the environment untouched.
+
+  - alias=NAME[|NAME...] - fall back to the named variables, in order, if
+    the field's own is unset, e.g. when renaming a variable without breaking
+    existing deployments. The aliases may instead follow the key, as in
+    `env:"HTTP_PORT|PORT"`. See WithKeyRecorder to learn which supplied the
+    value.
+
+  - deprecated[=NEW] - mark the variable as deprecated: values are still
+    read from it, but each use is reported (see WithDeprecationHandler) so
+    that operators can migrate. If NEW is given it names the variable
+    replacing it, which is read first and reported as missing if neither is
+    set, e.g. `env:"LISTEN_PORT,deprecated=HTTP_PORT"`.
+
+  - desc=TEXT - describe the variable for humans. Descriptions are exposed
+    as FieldInfo.Description and included in the output of Usage,
+    WriteMarkdown, WriteDotEnv and WriteTerraformVariables.
+
+  - transform=NAME[|NAME...] - pass the value through the named
+    transformers, in order, before it is parsed, e.g.
+    `env:"CA_BUNDLE,transform=trim|base64|gunzip"`. The transformers "trim",
+    "lower", "upper", "base64", "base64url", "hex" and "gunzip" are built in;
+    others may be registered with RegisterTransformer. Transforms apply to
+    defaults too. "gunzip" rejects values decompressing to more than 16 MiB,
+    or to more than the maximum value length (see WithMaxValueLength).
+
+  - trim, lower, upper - shorthand for the transformers of the same names,
+    which remove leading and trailing whitespace from the value or convert it
+    to lower or upper case, e.g. `env:"LOG_FORMAT,trim,lower,oneof=json|text"`.
+    They may be combined with each other and with `transform`, applying in
+    the order given.
+
+  - secret[=STRATEGY] - mark the field as holding a secret, exempting it
+    from secret scanning (see WithSecretScanners) and masking its value in
+    reports, exports (see Redacted) and parse errors using the named
+    redaction strategy (see RegisterRedactor).
+
+  - bytes - parse integer values as human-readable byte sizes such as
+    "512MiB" or "10GB" (decimal units are powers of 1000, binary units
+    powers of 1024). Bare numbers are counts of bytes.
+
+  - base=N - parse integer values in base 2, 8, 10 or 16, or, with base=0,
+    in the base implied by a "0x", "0o" or "0b" prefix (e.g. "0xFF" or
+    "0o755"), decimal otherwise. Values are marshalled in the same base
+    (decimal for base=0).
+
+  - duration - parse integer values (typically time.Duration) as durations
+    in the form accepted by time.ParseDuration, extended with days ("d") and
+    weeks ("w"), e.g. "7d", "2w" or "1d12h". A day is always 24 hours.
+    Without it time.Duration values are counts of nanoseconds.
+
+  - percent - parse float values as fractions in [0, 1], given either as
+    percentages ("75%") or as fractions ("0.75").
+
+  - lenientbool - additionally accept "yes"/"no", "y"/"n", "on"/"off" and
+    "enabled"/"disabled", in any case, for bool values.
+
+  - oneof=A|B|... - require string, boolean and numeric values (and the
+    elements of slices, arrays and map values of those types) to equal one
+    of the listed values, compared after parsing, e.g.
+    `env:"LOG_LEVEL,oneof=debug|info|warn|error"`.
+
+  - min=N, max=N - require numeric values (and the elements of slices,
+    arrays and map values of numeric types) to lie within the bounds, each
+    parsed as the field's values are, e.g. `env:"PORT,min=1,max=65535"` or
+    `env:"TIMEOUT,duration,min=1s,max=5m"`.
+
+  - uuid - require values to be UUIDs, in canonical form (optionally
+    prefixed with "urn:uuid:" or enclosed in braces) or as 32 hex digits.
+    String fields receive the canonical lower-case form and [16]byte fields
+    (such as uuid.UUID) the raw bytes.
+
+  - constraint=EXPR - require SemVer values, or semantic versions held by
+    string fields, to satisfy EXPR: space-separated comparisons, each an
+    operator (=, !=, >, >=, <, <=, ~ or ^) and a version, e.g.
+    "constraint=>=1.2.0 <2".
+
+  - url=KIND - require url.URL values to be "absolute" (with a scheme and
+    host) or "relative".
+
+  - indexed - populate a slice from the variables KEY_0, KEY_1, and so on,
+    stopping at the first missing index. Defaults are delimited lists. The
+    elements of slices of structs are populated from KEY_0_FIELD,
+    KEY_1_FIELD, and so on, an index being present if any of its
+    variables is set.
+
+  - gap=N - for indexed slices, stop only after N consecutive missing
+    indexes, skipping those missing in between.
+
+  - prefix=PREFIX - on a nested struct field, prepend PREFIX to the keys of
+    the struct's fields (and those of any structs nested within it), so
+    that a shared struct can be reused under several prefixes, e.g.
+    `env:",prefix=DB_"` and `env:",prefix=REPLICA_DB_"`.
+
+  - noprefix - on a nested struct field, discard the prefixes of enclosing
+    structs (see `prefix`), so that a shared struct (e.g. telemetry
+    settings) keeps global variable names wherever it is embedded. It may be
+    combined with a `prefix` of its own.
+
+  - count=VAR - for indexed slices, read exactly as many elements as VAR
+    specifies (at most 65536), reporting any that are missing. Within a
+    struct with a `prefix`, VAR is prefixed likewise.
+
+  - prefixmap - populate a map from every variable whose name starts with
+    the tag's key (e.g. `env:"FEATURE_,prefixmap"`), keyed by the rest of the
+    name. The Lookuper (or sources) must implement Enumerator. Defaults are
+    delimited lists of pairs.
+
+  - json - decode the value as JSON into the field (e.g. a struct, map or
+    slice) with encoding/json, rather than recursing into or splitting it.
+    json.RawMessage fields always hold JSON and need no attribute.
+
+  - yaml - decode the value as YAML into the field, like `json`. A YAML
+    implementation must first be registered with RegisterYAML.
+
+  - source=NAME[,NAME...] - look the variable up in the named sources (see
+    WithSource), in order, instead of the configured Lookuper.
+
+  - tz=ZONE - interpret time.Time values lacking a zone offset in ZONE
+    ("UTC", "Local" or an IANA name such as "Europe/London"). Defaults to UTC.
+
+Lookupers:
+
+Values are read from the process environment by default. Any type
+implementing Lookuper may be supplied with WithLookuper to read values from
+elsewhere; MultiLookuper chains several together.
+
+Concurrency:
+
+Process and its variants may be called concurrently from multiple goroutines,
+provided each call populates a different target. Their only shared mutable
+state is held by the package-level registries: the parsers, implementations,
+redaction strategies, transformers and YAML codec registered with
+RegisterParser, RegisterImplementation, RegisterRedactor, RegisterTransformer
+and RegisterYAML. These are safe for concurrent use but are intended to be
+populated during initialisation. Lookupers and Resolvers shared between
+concurrent calls must themselves be safe for concurrent use; all of those
+provided by this package are.
+
+Error Handling:
+
+Process panics when a struct cannot be populated. ProcessE performs the same
+work but returns an error instead, for callers that prefer to handle
+configuration failures gracefully.
+
+Service Metadata:
+
+Structs may embed (or contain a field of type) Metadata to have conventional
+service metadata populated. Any Metadata fields not supplied by the
+environment are filled from the binary's build information.
 */
 package envconf
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"reflect"
+	"runtime/debug"
 	"strconv"
 	"strings"
+	"time"
 )
 
 const (
 	tagKey = "env"
 
+	// tagKeyDefault names a companion tag that may hold a field's default,
+	// as in caarlos0/env, e.g. `env:"PORT" envDefault:"8080"`.
+	tagKeyDefault = "envDefault"
+
 	tagAttrAssignmentSymbol = "="
 	tagAttrDefault          = "default"
 	tagAttrRequired         = "required"
+	tagAttrRequiredIf       = "required_if"
+	tagAttrGroup            = "group"
+	tagAttrRequiredGroup    = "required_group"
+	tagAttrTZ               = "tz"
+	tagAttrLayout           = "layout"
+	tagAttrEncoding         = "encoding"
+	tagAttrBase64           = "base64"
+	tagAttrSeparator        = "separator"
+	tagAttrKVSeparator      = "kvseparator"
+	tagAttrSecret           = "secret"
+	tagAttrExpand           = "expand"
+	tagAttrURL              = "url"
+	tagAttrSource           = "source"
+	tagAttrIndexed          = "indexed"
+	tagAttrGap              = "gap"
+	tagAttrCount            = "count"
+	tagAttrPrefixMap        = "prefixmap"
+	tagAttrBytes            = "bytes"
+	tagAttrBase             = "base"
+	tagAttrLenientBool      = "lenientbool"
+	tagAttrDuration         = "duration"
+	tagAttrPercent          = "percent"
+	tagAttrPrefix           = "prefix"
+	tagAttrNoPrefix         = "noprefix"
+	tagAttrOneOf            = "oneof"
+	tagAttrMin              = "min"
+	tagAttrMax              = "max"
+	tagAttrAllowEmpty       = "allowempty"
+	tagAttrFile             = "file"
+	tagAttrUnset            = "unset"
+	tagAttrDesc             = "desc"
+	tagAttrDeprecated       = "deprecated"
+	tagAttrAlias            = "alias"
+	tagAttrDefaultFrom      = "defaultFrom"
+	tagAttrTrim             = "trim"
+	tagAttrLower            = "lower"
+	tagAttrUpper            = "upper"
+	tagAttrTransform        = "transform"
+	tagAliasSeparator       = "|"
+	tagAttrUUID             = "uuid"
+	tagAttrConstraint       = "constraint"
+	tagAttrJSON             = "json"
+	tagAttrYAML             = "yaml"
+
+	defaultSeparator   = ","
+	defaultKVSeparator = ":"
+
+	// whitespaceSeparator, given as `separator=whitespace`, splits values on
+	// runs of whitespace, including newlines.
+	whitespaceSeparator = "whitespace"
 )
 
-// Makes unit testing easier.
-var getEnvFunc func(string) string = os.Getenv
+// tagFlags holds the names of the tag attributes that take no argument.
+var tagFlags = map[string]bool{
+	tagAttrRequired:      true,
+	tagAttrRequiredGroup: true,
+	tagAttrSecret:        true,
+	tagAttrExpand:        true,
+	tagAttrIndexed:       true,
+	tagAttrPrefixMap:     true,
+	tagAttrBytes:         true,
+	tagAttrBase64:        true,
+	tagAttrLenientBool:   true,
+	tagAttrDuration:      true,
+	tagAttrPercent:       true,
+	tagAttrAllowEmpty:    true,
+	tagAttrFile:          true,
+	tagAttrUUID:          true,
+	tagAttrUnset:         true,
+	tagAttrDeprecated:    true,
+	tagAttrNoPrefix:      true,
+	tagAttrTrim:          true,
+	tagAttrLower:         true,
+	tagAttrUpper:         true,
+	tagAttrJSON:          true,
+	tagAttrYAML:          true,
+}
+
+// tagArgs holds the names of the tag attributes that take an argument.
+var tagArgs = map[string]bool{
+	tagAttrDefault:     true,
+	tagAttrDefaultFrom: true,
+	tagAttrRequiredIf:  true,
+	tagAttrGroup:       true,
+	tagAttrTZ:          true,
+	tagAttrLayout:      true,
+	tagAttrEncoding:    true,
+	tagAttrSeparator:   true,
+	tagAttrKVSeparator: true,
+	tagAttrSecret:      true,
+	tagAttrURL:         true,
+	tagAttrSource:      true,
+	tagAttrGap:         true,
+	tagAttrCount:       true,
+	tagAttrBase:        true,
+	tagAttrPrefix:      true,
+	tagAttrOneOf:       true,
+	tagAttrMin:         true,
+	tagAttrMax:         true,
+	tagAttrDesc:        true,
+	tagAttrDeprecated:  true,
+	tagAttrAlias:       true,
+	tagAttrTransform:   true,
+	tagAttrConstraint:  true,
+}
 
 // Process populates the fields of a struct based on environment variables
 // defined in struct tags.
@@ -85,13 +455,86 @@ var getEnvFunc func(string) string = os.Getenv
 // environment variable is not set and no default value is provided. - A value
 // retrieved from the environment cannot be converted to the field's type (e.g.,
 // non-numeric string for an int).
-func Process(v any) {
+//
+// The behaviour of Process may be customised by supplying one or more
+// Option values (see WithLookuper).
+//
+// Process is a panicking wrapper around ProcessE. It is safe for concurrent
+// use on distinct targets.
+func Process(v any, opts ...Option) {
+	if err := ProcessE(v, opts...); err != nil {
+		panic(err)
+	}
+}
+
+// ProcessE behaves like Process but returns an error rather than panicking,
+// allowing callers to handle, wrap or log configuration errors before exiting.
+func ProcessE(v any, opts ...Option) error {
+	return ProcessContext(context.Background(), v, opts...)
+}
+
+// ProcessContext behaves like ProcessE but threads `ctx` into every lookup and
+// resolution (see ContextLookuper and ContextResolver), allowing slow secret
+// backends to be cancelled and startup deadlines to be enforced. Processing
+// stops as soon as `ctx` is done, returning ctx.Err() alongside any failures
+// collected so far.
+func ProcessContext(ctx context.Context, v any, opts ...Option) error {
+	o := newOptions(opts)
+	o.ctx = ctx
+
+	return process(v, o)
+}
+
+// Load allocates a T, populates it as ProcessE would and returns it:
+//
+//	cfg, err := envconf.Load[Config]()
+//
+// T must be a struct type. Options are handled exactly as for Process.
+func Load[T any](opts ...Option) (*T, error) {
+	v := new(T)
+	if err := ProcessE(v, opts...); err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// process populates `v` according to the already assembled options `o`.
+func process(v any, o *options) (err error) {
+	if o.safe {
+		defer func() {
+			if r := recover(); r != nil {
+				err = &PanicError{Value: r, Stack: debug.Stack()}
+			}
+		}()
+	}
+
 	rv := reflect.ValueOf(v)
 	if rv.Kind() != reflect.Pointer || rv.Elem().Kind() != reflect.Struct {
-		panic("expected pointer to struct")
+		return errors.New("expected pointer to struct")
+	}
+
+	if o.schemaCheck {
+		if err := o.checkSchemaVersion(rv.Elem().Type()); err != nil {
+			return err
+		}
+	}
+
+	o.groups = &fieldGroups{}
+	err = processFields(rv, o, "")
+	if o.ctx.Err() == nil { // Groups are incomplete if processing was cut short.
+		if groupErrs := o.groups.check(); len(groupErrs) > 0 {
+			err = errors.Join(append([]error{err}, groupErrs...)...)
+		}
+	}
+	if err == nil && len(o.constraints) > 0 {
+		err = o.checkConstraints(rv.Elem(), o.constraints)
+	}
+	if ctxErr := o.ctx.Err(); ctxErr != nil {
+		return errors.Join(err, ctxErr)
 	}
 
-	processFields(rv)
+	return err
 }
 
 // processFields takes a `[]reflect.StructField` a `reflect.Value` and iterates
@@ -100,18 +543,39 @@ func Process(v any) {
 //
 // This function is recursive and will also iterate through all levels of struct
 // nesting (struct embedding) so long as the structs are exported. Fields that
-// are unexported or that do not contain a valid tag are skipped. This function
-// will panic if a required environment variable is not supplied.
-func processFields(v reflect.Value) {
+// are unexported or that do not contain a valid tag are skipped.
+//
+// Processing continues past fields that fail (e.g., a required environment
+// variable is not supplied or a value cannot be converted to its field's
+// type); every such failure is collected and returned as a single joined error
+// (see errors.Join). `path` is the dotted path of the struct being processed
+// (empty for the top level) and is used when reporting errors.
+func processFields(v reflect.Value, o *options, path string) error {
+	var errs []error
 	for _, field := range reflect.VisibleFields(v.Elem().Type()) {
-		if !field.IsExported() {
+		// Fields promoted from embedded structs are handled when recursing
+		// into the embedded struct itself.
+		if !field.IsExported() || len(field.Index) > 1 {
 			continue
 		}
-		// Recurse into structs and struct pointers.
+		if o.ctx.Err() != nil {
+			break // Reported by process.
+		}
+		tag, err := o.parseTag(field.Tag)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		// Recurse into structs and struct pointers (other than those parsed
+		// as a single value, decoded as JSON or excluded by WithStopTypes,
+		// see isLeaf).
 		var (
-			isStruct    = field.Type.Kind() == reflect.Struct
+			isStruct = field.Type.Kind() == reflect.Struct &&
+				!o.isLeaf(field.Type) && tag.format == ""
 			isStructPtr = field.Type.Kind() == reflect.Pointer &&
-				field.Type.Elem().Kind() == reflect.Struct
+				field.Type.Elem().Kind() == reflect.Struct &&
+				!o.isLeaf(field.Type.Elem()) && tag.format == ""
 		)
 		if isStruct || isStructPtr {
 			fV := v.Elem().FieldByIndex(field.Index)
@@ -123,145 +587,588 @@ func processFields(v reflect.Value) {
 				fV = fV.Elem()
 			}
 
-			processFields(fV.Addr())
+			section := func(o *options) {
+				if tag.prefix != "" || tag.noPrefix {
+					po := *o
+					po.keyPrefix = tag.childPrefix(o.keyPrefix)
+					o = &po
+				}
+				if err := processFields(fV.Addr(), o,
+					path+field.Name+"."); err != nil {
+					errs = append(errs, err)
+				}
+			}
+			if path == "" && o.profileLabels {
+				o.profileSection(field.Name, section)
+			} else {
+				section(o)
+			}
+			if fV.Type() == metadataType {
+				fV.Addr().Interface().(*Metadata).fillFromBuildInfo()
+			}
 			continue
 		}
 
-		key, required, defaultVal := parseTag(field.Tag)
-		if key == "" {
+		if tag.prefix != "" || tag.noPrefix {
+			attr := tagAttrPrefix
+			if tag.noPrefix {
+				attr = tagAttrNoPrefix
+			}
+			errs = append(errs, fmt.Errorf(
+				"%s struct tag attribute on non-struct field %q", attr, path+field.Name))
+			continue
+		}
+		if tag.key == "" {
 			continue // Ignore any field with no tag.
 		}
+		if err := checkConstraintArgs(field.Type, tag); err != nil {
+			errs = append(errs, fmt.Errorf("field %q: %w", path+field.Name, err))
+			continue
+		}
+		key := o.keyPrefix + tag.key
+		o.groups.join(key, tag)
 
-		val := getEnvFunc(key)
-		if val == "" && defaultVal != "" {
-			val = defaultVal
-		} else if val == "" && required {
-			panic(fmt.Sprintf("env var %q not set", key))
-		} else if val == "" {
+		if tag.requiredIf != nil && !tag.required {
+			met, err := tag.requiredIf.met(o, v.Elem().Type())
+			if err != nil {
+				errs = append(errs, &SourceError{
+					Var: o.keyPrefix + tag.requiredIf.key, Op: opLookup, Err: err})
+				continue
+			}
+			tag.required = met
+		}
+
+		fieldPtr := v.Elem().FieldByIndex(field.Index)
+		isZero := fieldPtr.IsZero()
+		if o.overwrite == OverwriteNever && !isZero {
+			o.groups.satisfy(tag)
+			continue // Preserve values assigned by the caller.
+		}
+		if o.diagnostics && tag.secret {
+			o.groups.satisfy(tag)
+			continue // Secret stores are not consulted in diagnostics mode.
+		}
+
+		if tag.indexed {
+			if err := o.processIndexed(fieldPtr, key, path+field.Name, tag, isZero); err != nil {
+				errs = append(errs, err)
+			}
+			if !fieldPtr.IsZero() {
+				o.groups.satisfy(tag)
+			}
+			continue
+		}
+		if tag.prefixMap {
+			if err := o.processPrefixMap(fieldPtr, key, path+field.Name, tag, isZero); err != nil {
+				errs = append(errs, err)
+			}
+			if !fieldPtr.IsZero() {
+				o.groups.satisfy(tag)
+			}
 			continue
 		}
 
 		var (
-			err      error
-			fieldPtr = v.Elem().FieldByIndex(field.Index)
+			val string
+			set bool
 		)
-		switch field.Type.Kind() {
-		case reflect.String:
-			fieldPtr.SetString(val)
-
-		case reflect.Int:
-			var (
-				bitSize int = strconv.IntSize
-				i       int64
-			)
-			i, err = strconv.ParseInt(val, 10, bitSize)
-			fieldPtr.SetInt(int64(i))
-		case reflect.Uint:
-			var (
-				bitSize int = strconv.IntSize
-				i       uint64
-			)
-			i, err = strconv.ParseUint(val, 10, bitSize)
-			fieldPtr.SetUint(i)
-
-		case reflect.Int8:
-			var i int64
-			i, err = strconv.ParseInt(val, 10, 8)
-			fieldPtr.SetInt(i)
-		case reflect.Int16:
-			var i int64
-			i, err = strconv.ParseInt(val, 10, 16)
-			fieldPtr.SetInt(i)
-		case reflect.Int32:
-			var i int64
-			i, err = strconv.ParseInt(val, 10, 32)
-			fieldPtr.SetInt(i)
-		case reflect.Int64:
-			var i int64
-			i, err = strconv.ParseInt(val, 10, 64)
-			fieldPtr.SetInt(i)
-		case reflect.Uint8:
-			var i uint64
-			i, err = strconv.ParseUint(val, 10, 8)
-			fieldPtr.SetUint(i)
-		case reflect.Uint16:
-			var i uint64
-			i, err = strconv.ParseUint(val, 10, 16)
-			fieldPtr.SetUint(i)
-		case reflect.Uint32:
-			var i uint64
-			i, err = strconv.ParseUint(val, 10, 32)
-			fieldPtr.SetUint(i)
-		case reflect.Uint64:
-			var i uint64
-			i, err = strconv.ParseUint(val, 10, 64)
-			fieldPtr.SetUint(i)
-		case reflect.Float32:
-			var f float64
-			f, err = strconv.ParseFloat(val, 32)
-			fieldPtr.SetFloat(f)
-		case reflect.Float64:
-			var f float64
-			f, err = strconv.ParseFloat(val, 64)
-			fieldPtr.SetFloat(f)
-		case reflect.Bool:
-			var b bool
-			b, err = strconv.ParseBool(val)
-			fieldPtr.SetBool(b)
-		case reflect.Complex64:
-			var v complex128
-			v, err = strconv.ParseComplex(val, 64)
-			fieldPtr.SetComplex(v)
-		case reflect.Complex128:
-			var v complex128
-			v, err = strconv.ParseComplex(val, 128)
-			fieldPtr.SetComplex(v)
+		if tag.deprecated {
+			val, key, set, err = o.lookupDeprecated(key, path+field.Name, tag)
+		} else {
+			val, key, set, err = o.lookupAliased(key, tag)
 		}
 		if err != nil {
-			panic(fmt.Sprintf("invalid %s value supplied: %q",
-				field.Type.Kind().String(), val))
+			errs = append(errs, &SourceError{Var: key, Op: opLookup, Err: err})
+			continue
+		}
+		if val != "" || set && tag.allowEmpty {
+			o.groups.satisfy(tag)
+		}
+		def := tag.defaultVal
+		if val == "" && tag.defaultFrom != "" {
+			fromKey := o.keyPrefix + tag.defaultFrom
+			from, _, err := o.lookupField(fromKey, tag)
+			if err != nil {
+				errs = append(errs, &SourceError{Var: fromKey, Op: opLookup, Err: err})
+				continue
+			}
+			if from != "" {
+				def = from
+			}
+		}
+		if val == "" && set && tag.allowEmpty {
+			fieldPtr.Set(reflect.Zero(field.Type)) // Explicitly empty.
+			o.recordKey(path+field.Name, key)
+			if err := o.unset(key, tag); err != nil {
+				errs = append(errs, err)
+			}
+			continue
+		} else if val == "" && o.overwrite == OverwriteIfSet && !isZero {
+			o.groups.satisfy(tag)
+			continue // Only a value from the source may replace this one.
+		} else if val == "" && def != "" {
+			val = def
+		} else if val == "" && tag.required {
+			errs = append(errs, &MissingError{Var: key, Field: path + field.Name})
+			continue
+		} else if val == "" {
+			continue
+		}
+
+		val, ok, err := o.prepareValue(key, path+field.Name, val, tag, field.Type)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if !ok {
+			continue
+		}
+
+		if err := setField(fieldPtr, val, tag); err != nil {
+			errs = append(errs, &ParseError{
+				Var:   key,
+				Field: path + field.Name,
+				Value: tag.redact(val),
+				Kind:  typeName(field.Type),
+				Err:   tag.redactErr(err),
+			})
+			continue
+		}
+		if set {
+			o.recordKey(path+field.Name, key)
+			if err := o.unset(key, tag); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// unset removes `key` from the process environment if the field's tag has
+// the `unset` attribute, so that the value is not inherited by child
+// processes or exposed through /proc. Values supplied by other sources, and
+// those read by Validate, are left alone.
+func (o *options) unset(key string, tag fieldTag) error {
+	if !tag.unset || o.validating {
+		return nil
+	}
+
+	l := o.lookuper
+	if len(tag.sources) > 0 {
+		ls := make(multiLookuper, 0, len(tag.sources))
+		for _, name := range tag.sources {
+			ls = append(ls, o.sources[name])
 		}
+		l = ls
+	}
+	envKey, ok := processEnvKey(o.ctx, l, key)
+	if !ok {
+		return nil
 	}
+	if err := os.Unsetenv(envKey); err != nil {
+		return &SourceError{Var: envKey, Op: opUnset, Err: err}
+	}
+	return nil
 }
 
-// parseTag takes a `reflect.StructTag` and parses it for the presence of
-// `tagKey`. The function returns a 3-tuple of (key, required, default value).
-//
-// If `tagKey` is not present `key` will be an empty string. If an invalid tag
-// attribute is provided the function will panic.
-func parseTag(st reflect.StructTag) (string, bool, string) {
+// prepareValue runs the value `val` of `key`, destined for the field at path
+// `field` of type `t`, through resolution, expansion and the configured
+// checks, returning the value to parse. The boolean result is false if the
+// field must be left untouched.
+func (o *options) prepareValue(key, field, val string, tag fieldTag, t reflect.Type) (string, bool, error) {
+	if err := o.checkLength(key, val); err != nil {
+		return "", false, err
+	}
+	if _, ok := o.resolverFor(val); ok && o.diagnostics {
+		return "", false, nil // Secret stores are not consulted in diagnostics mode.
+	}
+
+	val, err := o.resolve(val)
+	if err != nil {
+		return "", false, &SourceError{Var: key, Op: opResolve, Err: err}
+	}
+	if tag.expand {
+		if val, err = o.expand(val); err != nil {
+			return "", false, fmt.Errorf("failed to expand env var %q: %w", key, err)
+		}
+	}
+	if tag.file {
+		if val, err = o.readFile(key, val); err != nil {
+			return "", false, err
+		}
+	}
+	if val, err = o.transform(val, tag.transforms); err != nil {
+		return "", false, fmt.Errorf("failed to transform env var %q: %w", key, err)
+	}
+	if err := o.checkLength(key, val); err != nil {
+		return "", false, err
+	}
+	if err := o.checkDecodedSize(key, val, tag, t); err != nil {
+		return "", false, err
+	}
+
+	if !tag.secret && o.warnSecret != nil {
+		o.scanSecrets(key, field, val)
+	}
+
+	return val, true, nil
+}
+
+// readFile returns the contents, with surrounding whitespace trimmed, of the
+// file at `path`, named by the value of `key` (see the `file` attribute). The
+// file is opened in the configured file system (see WithFileSystem), and no
+// more than the maximum value length is read.
+func (o *options) readFile(key, path string) (string, error) {
 	var (
-		key        string
-		required   bool
-		defaultVal string
+		f   io.ReadCloser
+		err error
 	)
+	if o.fileSystem != nil {
+		f, err = o.fileSystem.Open(path)
+	} else {
+		f, err = os.Open(path)
+	}
+	if err != nil {
+		return "", &SourceError{Var: key, Op: opReadFile, Err: err}
+	}
+	defer f.Close()
 
-	val := st.Get(tagKey)
-	// Tag does not contain `tagKey`.
-	if val == "" {
-		return key, required, defaultVal
+	r := io.Reader(f)
+	if o.maxLength > 0 {
+		r = io.LimitReader(f, int64(o.maxLength)+1)
+	}
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return "", &SourceError{Var: key, Op: opReadFile, Err: err}
+	}
+	if o.maxLength > 0 && len(b) > o.maxLength {
+		return "", fmt.Errorf("env var %q: file exceeds limit of %d bytes: %w",
+			key, o.maxLength, ErrValueTooLarge)
 	}
 
-	splits := strings.Split(val, ",")
-	key = splits[0]
+	return strings.TrimSpace(string(b)), nil
+}
+
+// fieldTag holds the parsed contents of a field's struct tag.
+type fieldTag struct {
+	key           string
+	required      bool
+	defaultVal    string
+	defaultFrom   string            // Set by the `defaultFrom` attribute.
+	loc           *time.Location    // Set by the `tz` attribute.
+	layout        string            // Set by the `layout` attribute.
+	encoding      string            // Set by the `encoding` and `base64` attributes.
+	separator     string            // Set by the `separator` attribute.
+	kvSeparator   string            // Set by the `kvseparator` attribute.
+	secret        bool              // Set by the `secret` attribute.
+	redaction     string            // Set by the `secret` attribute.
+	expand        bool              // Set by the `expand` attribute.
+	urlKind       string            // Set by the `url` attribute.
+	sources       []string          // Set by the `source` attribute.
+	indexed       bool              // Set by the `indexed` attribute.
+	gap           int               // Set by the `gap` attribute.
+	count         string            // Set by the `count` attribute.
+	prefixMap     bool              // Set by the `prefixmap` attribute.
+	byteSize      bool              // Set by the `bytes` attribute.
+	base          string            // Set by the `base` attribute.
+	lenientBool   bool              // Set by the `lenientbool` attribute.
+	duration      bool              // Set by the `duration` attribute.
+	percent       bool              // Set by the `percent` attribute.
+	prefix        string            // Set by the `prefix` attribute.
+	noPrefix      bool              // Set by the `noprefix` attribute.
+	requiredIf    *requiredIf       // Set by the `required_if` attribute.
+	group         string            // Set by the `group` attribute.
+	requiredGroup bool              // Set by the `required_group` attribute.
+	oneOf         []string          // Set by the `oneof` attribute.
+	min           string            // Set by the `min` attribute.
+	max           string            // Set by the `max` attribute.
+	allowEmpty    bool              // Set by the `allowempty` attribute.
+	file          bool              // Set by the `file` attribute.
+	unset         bool              // Set by the `unset` attribute.
+	desc          string            // Set by the `desc` attribute.
+	deprecated    bool              // Set by the `deprecated` attribute.
+	replacement   string            // Set by the `deprecated` attribute.
+	aliases       []string          // Set by the `alias` attribute or key.
+	transforms    []string          // Set by the `transform`, `trim`, `lower` and `upper` attributes.
+	uuid          bool              // Set by the `uuid` attribute.
+	versions      *semVerConstraint // Set by the `constraint` attribute.
+	format        string            // Set by the `json` and `yaml` attributes.
+}
+
+// parseTag takes a `reflect.StructTag` and parses it for the presence of
+// `tagKey`, returning its contents as a fieldTag.
+//
+// If `tagKey` is not present the returned key will be an empty string. If an
+// invalid tag attribute is provided an error is returned.
+func parseTag(st reflect.StructTag) (fieldTag, error) {
+	var o options
+	return o.parseTag(st)
+}
 
-	// Only key is supplied in tag (i.e., no additional attributes).
-	if len(splits) == 1 {
-		return key, required, defaultVal
+// parseTag is like the function of the same name, but reads the configured
+// tag key (see WithTagName) and skips unrecognised attributes if configured
+// to (see WithIgnoreUnknownAttributes).
+func (o *options) parseTag(st reflect.StructTag) (fieldTag, error) {
+	var tag fieldTag
+
+	val := st.Get(o.tagKey())
+	// Tag does not contain the tag key.
+	if val == "" {
+		return tag, nil
+	}
+
+	splits, err := splitTagAttrs(val)
+	if err != nil {
+		return fieldTag{}, err
+	}
+	key, aliases, _ := strings.Cut(splits[0], tagAliasSeparator)
+	tag.key = key
+	if aliases != "" {
+		tag.aliases = strings.Split(aliases, tagAliasSeparator)
 	}
 
 	// Extract and process all tag attributes.
+	var inSources bool
 	for _, attr := range splits[1:] {
-		if attr == tagAttrRequired {
-			required = true
-		} else if strings.HasPrefix(attr,
-			tagAttrDefault+tagAttrAssignmentSymbol) {
-			defaultVal = strings.TrimPrefix(attr,
-				tagAttrDefault+tagAttrAssignmentSymbol)
-		} else {
-			panic(fmt.Sprintf("unrecognised struct tag attribute: %q", attr))
+		name, arg, hasArg := strings.Cut(attr, tagAttrAssignmentSymbol)
+		// Bare words following `source=` continue the list of sources.
+		if inSources && !hasArg && !tagFlags[attr] {
+			tag.sources = append(tag.sources, attr)
+			continue
 		}
+		inSources = false
+
+		switch {
+		case attr == tagAttrRequired:
+			tag.required = true
+		case attr == tagAttrSecret:
+			tag.secret = true
+		case name == tagAttrSecret && hasArg:
+			if _, ok := lookupRedactor(arg); !ok {
+				return fieldTag{}, fmt.Errorf(
+					"invalid %s struct tag attribute: unknown redaction strategy %q",
+					tagAttrSecret, arg)
+			}
+			tag.secret, tag.redaction = true, arg
+		case attr == tagAttrExpand:
+			tag.expand = true
+		case attr == tagAttrIndexed:
+			tag.indexed = true
+		case attr == tagAttrPrefixMap:
+			tag.prefixMap = true
+		case attr == tagAttrBytes:
+			tag.byteSize = true
+		case attr == tagAttrLenientBool:
+			tag.lenientBool = true
+		case attr == tagAttrDuration:
+			tag.duration = true
+		case attr == tagAttrPercent:
+			tag.percent = true
+		case attr == tagAttrAllowEmpty:
+			tag.allowEmpty = true
+		case attr == tagAttrFile:
+			tag.file = true
+		case attr == tagAttrUnset:
+			tag.unset = true
+		case attr == tagAttrTrim || attr == tagAttrLower || attr == tagAttrUpper:
+			tag.transforms = append(tag.transforms, attr)
+		case name == tagAttrTransform && hasArg && arg != "":
+			for _, t := range strings.Split(arg, "|") {
+				if _, ok := lookupTransformer(t); !ok {
+					return fieldTag{}, fmt.Errorf(
+						"invalid %s struct tag attribute: unknown transformer %q",
+						tagAttrTransform, t)
+				}
+				tag.transforms = append(tag.transforms, t)
+			}
+		case attr == tagAttrUUID:
+			tag.uuid = true
+		case attr == tagAttrJSON || attr == tagAttrYAML:
+			if tag.format != "" && tag.format != attr {
+				return fieldTag{}, fmt.Errorf(
+					"%s and %s struct tag attributes are mutually exclusive",
+					tag.format, attr)
+			}
+			if _, ok := lookupCodec(attr); !ok {
+				return fieldTag{}, fmt.Errorf(
+					"invalid %s struct tag attribute: no decoder registered (see RegisterYAML)",
+					attr)
+			}
+			tag.format = attr
+		case name == tagAttrGap && hasArg:
+			gap, err := strconv.Atoi(arg)
+			if err != nil || gap < 1 {
+				return fieldTag{}, fmt.Errorf(
+					"invalid %s struct tag attribute: %q", tagAttrGap, arg)
+			}
+			tag.gap = gap
+		case name == tagAttrBase && hasArg:
+			switch arg {
+			case "0", "2", "8", "10", "16":
+				tag.base = arg
+			default:
+				return fieldTag{}, fmt.Errorf(
+					"invalid %s struct tag attribute: %q", tagAttrBase, arg)
+			}
+		case name == tagAttrRequiredIf && hasArg && arg != "":
+			tag.requiredIf = parseRequiredIf(arg)
+		case name == tagAttrGroup && hasArg && arg != "":
+			tag.group = arg
+		case attr == tagAttrRequiredGroup:
+			tag.requiredGroup = true
+		case name == tagAttrOneOf && hasArg && arg != "":
+			tag.oneOf = strings.Split(arg, "|")
+		case name == tagAttrMin && hasArg && arg != "":
+			tag.min = arg
+		case name == tagAttrMax && hasArg && arg != "":
+			tag.max = arg
+		case attr == tagAttrDeprecated:
+			tag.deprecated = true
+		case name == tagAttrDeprecated && hasArg && arg != "":
+			tag.deprecated, tag.replacement = true, arg
+		case name == tagAttrAlias && hasArg && arg != "":
+			tag.aliases = append(tag.aliases, strings.Split(arg, tagAliasSeparator)...)
+		case name == tagAttrDesc && hasArg:
+			tag.desc = arg
+		case name == tagAttrPrefix && hasArg && arg != "":
+			tag.prefix = arg
+		case attr == tagAttrNoPrefix:
+			tag.noPrefix = true
+		case name == tagAttrCount && hasArg && arg != "":
+			tag.count = arg
+		case name == tagAttrDefault && hasArg:
+			tag.defaultVal = arg
+		case name == tagAttrDefaultFrom && hasArg && arg != "":
+			tag.defaultFrom = arg
+		case name == tagAttrTZ && hasArg:
+			loc, err := loadLocation(arg)
+			if err != nil {
+				return fieldTag{}, fmt.Errorf(
+					"invalid %s struct tag attribute: %w", tagAttrTZ, err)
+			}
+			tag.loc = loc
+		case name == tagAttrSeparator && hasArg && arg != "":
+			tag.separator = arg
+		case name == tagAttrKVSeparator && hasArg && arg != "":
+			tag.kvSeparator = arg
+		case name == tagAttrURL && (arg == urlAbsolute || arg == urlRelative):
+			tag.urlKind = arg
+		case name == tagAttrSource && hasArg && arg != "":
+			tag.sources = []string{arg}
+			inSources = true
+		case name == tagAttrConstraint && hasArg:
+			c, err := parseSemVerConstraint(arg)
+			if err != nil {
+				return fieldTag{}, fmt.Errorf(
+					"invalid %s struct tag attribute: %w", tagAttrConstraint, err)
+			}
+			tag.versions = c
+		case name == tagAttrLayout && hasArg:
+			tag.layout = arg
+		case name == tagAttrEncoding && hasArg:
+			if _, ok := byteEncodings[arg]; !ok {
+				return fieldTag{}, fmt.Errorf(
+					"invalid %s struct tag attribute: unknown encoding %q",
+					tagAttrEncoding, arg)
+			}
+			if tag.encoding != "" && tag.encoding != arg {
+				return fieldTag{}, fmt.Errorf(
+					"%s and %s struct tag attributes are mutually exclusive",
+					tagAttrBase64, tagAttrEncoding)
+			}
+			tag.encoding = arg
+		case attr == tagAttrBase64:
+			if tag.encoding != "" && tag.encoding != tagAttrBase64 {
+				return fieldTag{}, fmt.Errorf(
+					"%s and %s struct tag attributes are mutually exclusive",
+					tagAttrBase64, tagAttrEncoding)
+			}
+			tag.encoding = tagAttrBase64
+		case o.ignoreUnknownAttrs && !tagFlags[name] && !tagArgs[name]:
+			continue // Presumably meaningful to another library.
+		default:
+			return fieldTag{}, fmt.Errorf(
+				"unrecognised struct tag attribute: %q", attr)
+		}
+	}
+
+	if tag.indexed && tag.prefixMap {
+		return fieldTag{}, fmt.Errorf(
+			"%s and %s struct tag attributes are mutually exclusive",
+			tagAttrIndexed, tagAttrPrefixMap)
+	}
+
+	if tag.requiredGroup && tag.group == "" {
+		return fieldTag{}, fmt.Errorf("%s struct tag attribute requires a %s attribute",
+			tagAttrRequiredGroup, tagAttrGroup)
+	}
+
+	if def, ok := st.Lookup(tagKeyDefault); ok {
+		if tag.defaultVal != "" {
+			return fieldTag{}, fmt.Errorf(
+				"%s struct tag attribute and %s tag are mutually exclusive",
+				tagAttrDefault, tagKeyDefault)
+		}
+		tag.defaultVal = def
+	}
+
+	return tag, nil
+}
+
+// childPrefix returns the key prefix of the fields of the nested struct
+// described by `t`, given `prefix`, the key prefix of its parent (see the
+// `prefix` and `noprefix` attributes).
+func (t fieldTag) childPrefix(prefix string) string {
+	if t.noPrefix {
+		prefix = ""
 	}
+	return prefix + t.prefix
+}
+
+// splitTagAttrs splits `val`, the contents of a struct tag, into the key and
+// attributes on commas, except for commas escaped with a backslash ("\,")
+// and those within an argument enclosed in single quotes (default='a,b'). A
+// quoted argument must make up the rest of its attribute. Quotes and escaping
+// backslashes are removed.
+func splitTagAttrs(val string) ([]string, error) {
+	var (
+		attrs []string
+		attr  strings.Builder
+	)
+	for i := 0; i < len(val); i++ {
+		c := val[i]
+		switch {
+		case c == '\\' && i+1 < len(val) && val[i+1] == ',':
+			attr.WriteByte(',')
+			i++
+		case c == '\'' && len(attrs) > 0 && isStartOfArg(attr.String()):
+			end := strings.IndexByte(val[i+1:], '\'')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated quote in struct tag attribute: %q",
+					attr.String()+val[i:])
+			}
+			quoted := val[i+1 : i+1+end]
+			i += end + 1
+			if i+1 < len(val) && val[i+1] != ',' {
+				return nil, fmt.Errorf("unexpected text after quoted struct tag attribute: %q",
+					attr.String()+val[i-end-1:])
+			}
+			attr.WriteString(quoted)
+		case c == ',':
+			attrs = append(attrs, attr.String())
+			attr.Reset()
+		default:
+			attr.WriteByte(c)
+		}
+	}
+
+	return append(attrs, attr.String()), nil
+}
 
-	return key, required, defaultVal
+// isStartOfArg reports whether `attr`, a partially split attribute, ends
+// with the symbol introducing its argument.
+func isStartOfArg(attr string) bool {
+	name, arg, hasArg := strings.Cut(attr, tagAttrAssignmentSymbol)
+	return hasArg && arg == "" && name != ""
 }
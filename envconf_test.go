@@ -1,27 +1,32 @@
 package envconf
 
 import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
 	"math"
 	"math/big"
+	"os"
+	"path/filepath"
+	"reflect"
 	"strconv"
 	"strings"
 	"testing"
+	"testing/fstest"
 )
 
-func init() {
-	getEnvFunc = mockGetEnvFunc
-}
-
-var mockEnvVarMap = make(map[string]string)
+var mockEnvVarMap = make(MapLookuper)
 
-var mockGetEnvFunc = func(in string) string {
-	return mockEnvVarMap[in]
+// mockEnv returns an Option resolving variables from mockEnvVarMap.
+func mockEnv() Option {
+	return WithLookuper(mockEnvVarMap)
 }
 
 func tRun(t *testing.T, name string, testFunc func(t *testing.T)) {
 	// Teardown
 	defer func() {
-		mockEnvVarMap = make(map[string]string)
+		mockEnvVarMap = make(MapLookuper)
 	}()
 
 	t.Run(name, testFunc)
@@ -58,6 +63,17 @@ func assertPanicWithSubStr(t *testing.T, msg string) {
 	}
 }
 
+func assertErrorWithSubStr(t *testing.T, err error, msg string) {
+	t.Helper()
+
+	if err == nil {
+		t.Fatalf("expected error containing %q, got nil", msg)
+	}
+	if !strings.Contains(err.Error(), msg) {
+		t.Errorf("expected error to contain string %q, got: %q", msg, err.Error())
+	}
+}
+
 func TestProcess_DefaultValues(t *testing.T) {
 	// Pre Arrange
 	type testObj struct {
@@ -67,7 +83,7 @@ func TestProcess_DefaultValues(t *testing.T) {
 	tRun(t, "where no value is supplied default is used", func(t *testing.T) {
 		// Act
 		var in *testObj = &testObj{}
-		Process(in)
+		Process(in, mockEnv())
 
 		// Assert
 		assertEqual(t, in.Port, "8080")
@@ -79,7 +95,7 @@ func TestProcess_DefaultValues(t *testing.T) {
 
 		// Act
 		var in testObj
-		Process(&in)
+		Process(&in, mockEnv())
 
 		// Assert
 		assertEqual(t, in.Port, "9999")
@@ -102,7 +118,7 @@ func TestProcess_EmbeddedStructs(t *testing.T) {
 
 		// Act
 		var in testObj
-		Process(&in)
+		Process(&in, mockEnv())
 
 		// Assert
 		assertEqual(t, in.A.Field, "test")
@@ -124,7 +140,7 @@ func TestProcess_EmbeddedStructs(t *testing.T) {
 
 		// Act
 		var in testObj
-		Process(&in)
+		Process(&in, mockEnv())
 
 		// Assert
 		assertEqual(t, in.A.Field, "test")
@@ -133,6 +149,257 @@ func TestProcess_EmbeddedStructs(t *testing.T) {
 	})
 }
 
+func TestProcess_AllowEmpty(t *testing.T) {
+	type testObj struct {
+		Banner  string   `env:"BANNER,allowempty,default=welcome"`
+		Proxy   string   `env:"PROXY,allowempty,required"`
+		Retries int      `env:"RETRIES,allowempty,default=3"`
+		Hosts   []string `env:"HOSTS,allowempty,separator=;,default=a;b"`
+		Region  string   `env:"REGION,default=eu"`
+	}
+
+	tRun(t, "explicitly empty values clear defaults", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["BANNER"] = ""
+		mockEnvVarMap["PROXY"] = ""
+		mockEnvVarMap["RETRIES"] = ""
+		mockEnvVarMap["HOSTS"] = ""
+		mockEnvVarMap["REGION"] = ""
+
+		// Act
+		in := testObj{Retries: 7, Hosts: []string{"x"}}
+		err := ProcessE(&in, mockEnv())
+
+		// Assert
+		assertEqual(t, err, nil)
+		assertEqual(t, in.Banner, "")
+		assertEqual(t, in.Retries, 0)
+		assertEqual(t, in.Hosts == nil, true)
+		assertEqual(t, in.Region, "eu") // Empty means unset without the attribute.
+	})
+
+	tRun(t, "unset values still use defaults", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["PROXY"] = "http://proxy:3128"
+
+		// Act
+		var in testObj
+		Process(&in, mockEnv())
+
+		// Assert
+		assertEqual(t, in.Banner, "welcome")
+		assertEqual(t, in.Retries, 3)
+		assertEqual(t, in.Hosts[1], "b")
+	})
+
+	tRun(t, "unset required values are still reported", func(t *testing.T) {
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv())
+
+		// Assert
+		assertErrorWithSubStr(t, err, `env var "PROXY" not set`)
+	})
+}
+
+func TestProcess_RequiredIf(t *testing.T) {
+	type tlsConfig struct {
+		Enabled bool   `env:"ENABLED"`
+		Key     string `env:"KEY,required_if=ENABLED=true"`
+	}
+	type testObj struct {
+		CAFile string    `env:"CA_FILE,required_if=MTLS_MODE"`
+		TLS    tlsConfig `env:",prefix=TLS_"`
+	}
+
+	tRun(t, "fields are optional while the condition is unmet", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["TLS_ENABLED"] = "false"
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv())
+
+		// Assert
+		assertEqual(t, err, nil)
+	})
+
+	tRun(t, "fields are required once the condition is met", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["TLS_ENABLED"] = "true"
+		mockEnvVarMap["MTLS_MODE"] = "strict"
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv())
+
+		// Assert
+		assertErrorWithSubStr(t, err, `env var "CA_FILE" not set`)
+		assertErrorWithSubStr(t, err, `env var "TLS_KEY" not set`)
+	})
+
+	tRun(t, "satisfied requirements populate normally", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["TLS_ENABLED"] = "true"
+		mockEnvVarMap["TLS_KEY"] = "/etc/tls/key.pem"
+
+		// Act
+		var in testObj
+		Process(&in, mockEnv())
+
+		// Assert
+		assertEqual(t, in.TLS.Key, "/etc/tls/key.pem")
+	})
+
+	tRun(t, "conditions are read as the field they refer to is", func(t *testing.T) {
+		// Arrange
+		type testObj struct {
+			Mode   string `env:"MTLS_MODE,source=file,alias=TLS_MODE"`
+			CAFile string `env:"CA_FILE,required_if=MTLS_MODE"`
+		}
+		file := MapLookuper{"TLS_MODE": "strict"}
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv(), WithSource("file", file))
+
+		// Assert
+		assertErrorWithSubStr(t, err, `env var "CA_FILE" not set`)
+	})
+}
+
+func TestProcess_NestedPrefix(t *testing.T) {
+	type databaseConfig struct {
+		Host string `env:"HOST,required"`
+		Port int    `env:"PORT,default=5432"`
+		Pool struct {
+			Size int `env:"SIZE"`
+		} `env:",prefix=POOL_"`
+	}
+	type testObj struct {
+		DB      databaseConfig  `env:",prefix=DB_"`
+		Replica *databaseConfig `env:",prefix=REPLICA_DB_"`
+	}
+
+	tRun(t, "child keys are prefixed", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["DB_HOST"] = "primary"
+		mockEnvVarMap["DB_POOL_SIZE"] = "10"
+		mockEnvVarMap["REPLICA_DB_HOST"] = "replica"
+		mockEnvVarMap["REPLICA_DB_PORT"] = "5433"
+
+		// Act
+		var in testObj
+		Process(&in, mockEnv())
+
+		// Assert
+		assertEqual(t, in.DB.Host, "primary")
+		assertEqual(t, in.DB.Port, 5432)
+		assertEqual(t, in.DB.Pool.Size, 10)
+		assertEqual(t, in.Replica.Host, "replica")
+		assertEqual(t, in.Replica.Port, 5433)
+	})
+
+	tRun(t, "errors name the prefixed variable", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["DB_HOST"] = "primary"
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv())
+
+		// Assert
+		var me *MissingError
+		assertEqual(t, errors.As(err, &me), true)
+		assertEqual(t, me.Var, "REPLICA_DB_HOST")
+		assertEqual(t, me.Field, "Replica.Host")
+	})
+
+	tRun(t, "fields and marshalled keys are prefixed", func(t *testing.T) {
+		// Arrange
+		in := testObj{DB: databaseConfig{Host: "a"}, Replica: &databaseConfig{Host: "b"}}
+
+		// Act
+		env, err := Marshal(in)
+
+		// Assert
+		assertEqual(t, err, nil)
+		assertEqual(t, env["DB_HOST"], "a")
+		assertEqual(t, env["REPLICA_DB_HOST"], "b")
+		assertEqual(t, env["REPLICA_DB_POOL_SIZE"], "0")
+	})
+
+	tRun(t, "prefix on a non-struct field is rejected", func(t *testing.T) {
+		// Arrange
+		var in struct {
+			Host string `env:"HOST,prefix=DB_"`
+		}
+
+		// Act
+		err := ProcessE(&in, mockEnv())
+
+		// Assert
+		assertErrorWithSubStr(t, err, `prefix struct tag attribute on non-struct field "Host"`)
+	})
+}
+
+func TestProcess_NoPrefix(t *testing.T) {
+	type telemetryConfig struct {
+		Endpoint string `env:"OTEL_ENDPOINT"`
+	}
+	type serviceConfig struct {
+		Port      int             `env:"PORT"`
+		Telemetry telemetryConfig `env:",noprefix"`
+		Tracing   telemetryConfig `env:",noprefix,prefix=TRACE_"`
+	}
+	type testObj struct {
+		API serviceConfig `env:",prefix=API_"`
+	}
+
+	tRun(t, "enclosing prefixes are discarded", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["API_PORT"] = "8080"
+		mockEnvVarMap["OTEL_ENDPOINT"] = "collector:4317"
+		mockEnvVarMap["TRACE_OTEL_ENDPOINT"] = "tracer:4317"
+
+		// Act
+		var in testObj
+		Process(&in, mockEnv())
+
+		// Assert
+		assertEqual(t, in.API.Port, 8080)
+		assertEqual(t, in.API.Telemetry.Endpoint, "collector:4317")
+		assertEqual(t, in.API.Tracing.Endpoint, "tracer:4317")
+	})
+
+	tRun(t, "fields are not prefixed", func(t *testing.T) {
+		// Act
+		var keys []string
+		for fi := range Fields(testObj{}) {
+			keys = append(keys, fi.Key)
+		}
+
+		// Assert
+		assertEqual(t, len(keys), 3)
+		assertEqual(t, keys[0], "API_PORT")
+		assertEqual(t, keys[1], "OTEL_ENDPOINT")
+		assertEqual(t, keys[2], "TRACE_OTEL_ENDPOINT")
+	})
+
+	tRun(t, "noprefix on a non-struct field is rejected", func(t *testing.T) {
+		// Arrange
+		var in struct {
+			Host string `env:"HOST,noprefix"`
+		}
+
+		// Act
+		err := ProcessE(&in, mockEnv())
+
+		// Assert
+		assertErrorWithSubStr(t, err, `noprefix struct tag attribute on non-struct field "Host"`)
+	})
+}
+
 func TestProcess_RequiredFields(t *testing.T) {
 	// Pre Arrange
 	type testObj struct {
@@ -145,7 +412,7 @@ func TestProcess_RequiredFields(t *testing.T) {
 
 		// Act
 		var in *testObj = &testObj{}
-		Process(in)
+		Process(in, mockEnv())
 
 		// Assert
 		assertEqual(t, in.Port, "8080")
@@ -153,11 +420,11 @@ func TestProcess_RequiredFields(t *testing.T) {
 
 	tRun(t, "where required field is missing", func(t *testing.T) {
 		// Arrange
-		defer assertPanicWithSubStr(t, "test")
+		defer assertPanicWithSubStr(t, `env var "PORT" not set`)
 
 		// Act
 		var in testObj
-		Process(in)
+		Process(&in, mockEnv())
 	})
 }
 
@@ -174,7 +441,7 @@ func TestProcess_BasicTypes(t *testing.T) {
 
 			// Act
 			var in testObj
-			Process(&in)
+			Process(&in, mockEnv())
 
 			// Assert
 			assertEqual(t, in.FieldInt, math.MaxInt)
@@ -188,7 +455,7 @@ func TestProcess_BasicTypes(t *testing.T) {
 
 			// Act
 			var in testObj
-			Process(&in)
+			Process(&in, mockEnv())
 		})
 	})
 
@@ -203,7 +470,7 @@ func TestProcess_BasicTypes(t *testing.T) {
 			mockEnvVarMap["FIELD_INT8"] = strconv.Itoa(math.MaxInt8)
 
 			// Act
-			Process(&in)
+			Process(&in, mockEnv())
 
 			// Assert
 			assertEqual(t, in.FieldInt8, int8(math.MaxInt8))
@@ -217,7 +484,7 @@ func TestProcess_BasicTypes(t *testing.T) {
 			defer assertPanicWithSubStr(t, "invalid int8 value supplied")
 
 			// Act
-			Process(&in)
+			Process(&in, mockEnv())
 		})
 	})
 
@@ -232,7 +499,7 @@ func TestProcess_BasicTypes(t *testing.T) {
 			mockEnvVarMap["FIELD_INT16"] = strconv.Itoa(math.MaxInt16)
 
 			// Act
-			Process(&in)
+			Process(&in, mockEnv())
 
 			// Assert
 			assertEqual(t, in.FieldInt16, int16(math.MaxInt16))
@@ -246,7 +513,7 @@ func TestProcess_BasicTypes(t *testing.T) {
 			defer assertPanicWithSubStr(t, "invalid int16 value supplied")
 
 			// Act
-			Process(&in)
+			Process(&in, mockEnv())
 		})
 	})
 
@@ -261,7 +528,7 @@ func TestProcess_BasicTypes(t *testing.T) {
 			mockEnvVarMap["FIELD_INT32"] = strconv.Itoa(math.MaxInt32)
 
 			// Act
-			Process(&in)
+			Process(&in, mockEnv())
 
 			// Assert
 			assertEqual(t, in.FieldInt32, int32(math.MaxInt32))
@@ -275,7 +542,7 @@ func TestProcess_BasicTypes(t *testing.T) {
 			defer assertPanicWithSubStr(t, "invalid int32 value supplied")
 
 			// Act
-			Process(&in)
+			Process(&in, mockEnv())
 		})
 	})
 
@@ -290,7 +557,7 @@ func TestProcess_BasicTypes(t *testing.T) {
 			mockEnvVarMap["FIELD_INT64"] = strconv.FormatInt(math.MaxInt64, 10)
 
 			// Act
-			Process(&in)
+			Process(&in, mockEnv())
 
 			// Assert
 			assertEqual(t, in.FieldInt64, int64(math.MaxInt64))
@@ -305,7 +572,7 @@ func TestProcess_BasicTypes(t *testing.T) {
 			defer assertPanicWithSubStr(t, "invalid int64 value supplied")
 
 			// Act
-			Process(&in)
+			Process(&in, mockEnv())
 		})
 	})
 
@@ -320,7 +587,7 @@ func TestProcess_BasicTypes(t *testing.T) {
 			var in testObj
 
 			// Act
-			Process(&in)
+			Process(&in, mockEnv())
 
 			// Assert
 			assertEqual(t, in.FieldUint, uint(math.MaxUint64>>1))
@@ -335,7 +602,7 @@ func TestProcess_BasicTypes(t *testing.T) {
 			defer assertPanicWithSubStr(t, "invalid uint value supplied")
 
 			// Act
-			Process(&in)
+			Process(&in, mockEnv())
 		})
 	})
 
@@ -350,7 +617,7 @@ func TestProcess_BasicTypes(t *testing.T) {
 			mockEnvVarMap["FIELD_UINT8"] = strconv.FormatUint(uint64(math.MaxUint8), 10)
 
 			// Act
-			Process(&in)
+			Process(&in, mockEnv())
 
 			// Assert
 			assertEqual(t, in.FieldUint8, uint8(math.MaxUint8))
@@ -364,7 +631,7 @@ func TestProcess_BasicTypes(t *testing.T) {
 			defer assertPanicWithSubStr(t, "invalid uint8 value supplied")
 
 			// Act
-			Process(&in)
+			Process(&in, mockEnv())
 		})
 	})
 
@@ -379,7 +646,7 @@ func TestProcess_BasicTypes(t *testing.T) {
 			mockEnvVarMap["FIELD_UINT16"] = strconv.FormatUint(uint64(math.MaxUint16), 10)
 
 			// Act
-			Process(&in)
+			Process(&in, mockEnv())
 
 			// Assert
 			assertEqual(t, in.FieldUint16, uint16(math.MaxUint16))
@@ -393,7 +660,7 @@ func TestProcess_BasicTypes(t *testing.T) {
 			defer assertPanicWithSubStr(t, "invalid uint16 value supplied")
 
 			// Act
-			Process(&in)
+			Process(&in, mockEnv())
 		})
 	})
 
@@ -408,7 +675,7 @@ func TestProcess_BasicTypes(t *testing.T) {
 			mockEnvVarMap["FIELD_UINT32"] = strconv.FormatUint(uint64(math.MaxUint32), 10)
 
 			// Act
-			Process(&in)
+			Process(&in, mockEnv())
 
 			// Assert
 			assertEqual(t, in.FieldUint32, uint32(math.MaxUint32))
@@ -422,7 +689,7 @@ func TestProcess_BasicTypes(t *testing.T) {
 			defer assertPanicWithSubStr(t, "invalid uint32 value supplied")
 
 			// Act
-			Process(&in)
+			Process(&in, mockEnv())
 		})
 	})
 
@@ -437,7 +704,7 @@ func TestProcess_BasicTypes(t *testing.T) {
 			mockEnvVarMap["FIELD_UINT64"] = strconv.FormatUint(math.MaxUint64, 10)
 
 			// Act
-			Process(&in)
+			Process(&in, mockEnv())
 
 			// Assert
 			assertEqual(t, in.FieldUint64, uint(math.MaxUint64))
@@ -452,7 +719,7 @@ func TestProcess_BasicTypes(t *testing.T) {
 			defer assertPanicWithSubStr(t, "invalid uint value supplied")
 
 			// Act
-			Process(&in)
+			Process(&in, mockEnv())
 		})
 	})
 
@@ -467,7 +734,7 @@ func TestProcess_BasicTypes(t *testing.T) {
 			mockEnvVarMap["FIELD_FLOAT32"] = strconv.FormatFloat(math.MaxFloat32, 'f', -1, 32)
 
 			// Act
-			Process(&in)
+			Process(&in, mockEnv())
 
 			// Assert
 			assertEqual(t, in.FieldFloat32, float32(math.MaxFloat32))
@@ -481,7 +748,7 @@ func TestProcess_BasicTypes(t *testing.T) {
 			defer assertPanicWithSubStr(t, "invalid float32 value supplied")
 
 			// Act
-			Process(&in)
+			Process(&in, mockEnv())
 		})
 	})
 
@@ -496,7 +763,7 @@ func TestProcess_BasicTypes(t *testing.T) {
 			mockEnvVarMap["FIELD_FLOAT64"] = strconv.FormatFloat(math.MaxFloat64, 'f', -1, 64)
 
 			// Act
-			Process(&in)
+			Process(&in, mockEnv())
 
 			// Assert
 			assertEqual(t, in.FieldFloat64, float64(math.MaxFloat64))
@@ -510,7 +777,7 @@ func TestProcess_BasicTypes(t *testing.T) {
 			defer assertPanicWithSubStr(t, "invalid float64 value supplied")
 
 			// Act
-			Process(&in)
+			Process(&in, mockEnv())
 		})
 	})
 
@@ -525,7 +792,7 @@ func TestProcess_BasicTypes(t *testing.T) {
 			mockEnvVarMap["FIELD_BOOL"] = "true"
 
 			// Act
-			Process(&in)
+			Process(&in, mockEnv())
 
 			// Assert
 			assertEqual(t, in.FieldBool, true)
@@ -535,7 +802,7 @@ func TestProcess_BasicTypes(t *testing.T) {
 			mockEnvVarMap["FIELD_BOOL"] = "false"
 
 			// Act
-			Process(&in)
+			Process(&in, mockEnv())
 
 			// Assert
 			assertEqual(t, in.FieldBool, false)
@@ -548,7 +815,7 @@ func TestProcess_BasicTypes(t *testing.T) {
 			defer assertPanicWithSubStr(t, "invalid bool value supplied")
 
 			// Act
-			Process(&in)
+			Process(&in, mockEnv())
 		})
 	})
 
@@ -563,7 +830,7 @@ func TestProcess_BasicTypes(t *testing.T) {
 			mockEnvVarMap["FIELD_COMPLEX64"] = "1+2i"
 
 			// Arrange
-			Process(&in)
+			Process(&in, mockEnv())
 
 			// Assert
 			assertEqual(t, in.FieldComplex64, complex64(complex(1, 2)))
@@ -577,7 +844,7 @@ func TestProcess_BasicTypes(t *testing.T) {
 			defer assertPanicWithSubStr(t, "invalid complex64 value supplied")
 
 			// Act
-			Process(&in)
+			Process(&in, mockEnv())
 		})
 	})
 
@@ -592,7 +859,7 @@ func TestProcess_BasicTypes(t *testing.T) {
 			mockEnvVarMap["FIELD_COMPLEX128"] = "3.14-1.5i"
 
 			// Arrange
-			Process(&in)
+			Process(&in, mockEnv())
 
 			// Assert
 			assertEqual(t, in.FieldComplex128, complex(3.14, -1.5))
@@ -606,7 +873,7 @@ func TestProcess_BasicTypes(t *testing.T) {
 			defer assertPanicWithSubStr(t, "invalid complex128 value supplied")
 
 			// Act
-			Process(&in)
+			Process(&in, mockEnv())
 		})
 	})
 }
@@ -622,7 +889,7 @@ func TestProcess_UnexportedFields(t *testing.T) {
 
 		// Act
 		var in testObj
-		Process(&in)
+		Process(&in, mockEnv())
 
 		// Assert
 		assertEqual(t, in.Field1, "test")
@@ -644,6 +911,908 @@ func TestProcess_UnrecognisedTagAttributes(t *testing.T) {
 		defer assertPanicWithSubStr(t, "unrecognised struct tag attribute: \"bad_attr\"")
 
 		// Act
-		Process(&in)
+		Process(&in, mockEnv())
+	})
+
+	tRun(t, "are skipped if configured", func(t *testing.T) {
+		// Arrange
+		type testObj struct {
+			Port int    `env:"PORT,default=8080,omitempty,notEmpty,envSeparator=:"`
+			Name string `env:"NAME,required,bad_attr"`
+		}
+		mockEnvVarMap["NAME"] = "app"
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv(), WithIgnoreUnknownAttributes())
+
+		// Assert
+		assertEqual(t, err, nil)
+		assertEqual(t, in.Port, 8080)
+		assertEqual(t, in.Name, "app")
+	})
+
+	tRun(t, "misused attributes are reported even if configured", func(t *testing.T) {
+		// Arrange
+		type testObj struct {
+			Key []byte `env:"KEY,encoding=rot13,separator="`
+		}
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv(), WithIgnoreUnknownAttributes())
+
+		// Assert
+		assertErrorWithSubStr(t, err, `unknown encoding "rot13"`)
+	})
+}
+
+func TestParseTag_Quoting(t *testing.T) {
+	tRun(t, "quoted arguments may contain commas", func(t *testing.T) {
+		// Act
+		tag, err := parseTag(`env:"HOSTS,default='a,b',desc='Hosts, in order',required"`)
+
+		// Assert
+		assertEqual(t, err, nil)
+		assertEqual(t, tag.defaultVal, "a,b")
+		assertEqual(t, tag.desc, "Hosts, in order")
+		assertEqual(t, tag.required, true)
+	})
+
+	tRun(t, "escaped commas do not separate attributes", func(t *testing.T) {
+		// Act
+		tag, err := parseTag(`env:"DSN,default=host=db\\,port=5432,required"`)
+
+		// Assert
+		assertEqual(t, err, nil)
+		assertEqual(t, tag.defaultVal, "host=db,port=5432")
+		assertEqual(t, tag.required, true)
+	})
+
+	tRun(t, "quotes within arguments are literal", func(t *testing.T) {
+		// Act
+		tag, err := parseTag(`env:"GREETING,default=it's"`)
+
+		// Assert
+		assertEqual(t, err, nil)
+		assertEqual(t, tag.defaultVal, "it's")
+	})
+
+	tRun(t, "unterminated quotes are rejected", func(t *testing.T) {
+		// Act
+		_, err := parseTag(`env:"HOSTS,default='a,b"`)
+
+		// Assert
+		assertErrorWithSubStr(t, err, `unterminated quote in struct tag attribute: "default='a,b"`)
+	})
+
+	tRun(t, "text after a closing quote is rejected", func(t *testing.T) {
+		// Act
+		_, err := parseTag(`env:"HOSTS,default='a,b'c,required"`)
+
+		// Assert
+		assertErrorWithSubStr(t, err,
+			`unexpected text after quoted struct tag attribute: "default='a,b'c,required"`)
+	})
+
+	tRun(t, "quoted defaults are split as values", func(t *testing.T) {
+		// Arrange
+		type testObj struct {
+			Hosts []string `env:"HOSTS,default='a.example.com,b.example.com'"`
+		}
+
+		// Act
+		var in testObj
+		Process(&in, mockEnv())
+
+		// Assert
+		assertEqual(t, len(in.Hosts), 2)
+		assertEqual(t, in.Hosts[1], "b.example.com")
+	})
+}
+
+func TestProcessE(t *testing.T) {
+	// Pre Arrange
+	type testObj struct {
+		Port int `env:"PORT,required"`
+	}
+
+	tRun(t, "returns nil on success", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["PORT"] = "8080"
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv())
+
+		// Assert
+		assertEqual(t, err, nil)
+		assertEqual(t, in.Port, 8080)
+	})
+
+	tRun(t, "returns error for non pointer", func(t *testing.T) {
+		// Act
+		var in testObj
+		err := ProcessE(in, mockEnv())
+
+		// Assert
+		assertErrorWithSubStr(t, err, "expected pointer to struct")
+	})
+
+	tRun(t, "returns error for missing required field", func(t *testing.T) {
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv())
+
+		// Assert
+		assertErrorWithSubStr(t, err, `env var "PORT" not set`)
+	})
+
+	tRun(t, "returns error for invalid value", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["PORT"] = "eighty"
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv())
+
+		// Assert
+		assertErrorWithSubStr(t, err, `invalid int value supplied: "eighty"`)
+	})
+}
+
+func TestProcessE_AggregatesErrors(t *testing.T) {
+	tRun(t, "every failing field is reported", func(t *testing.T) {
+		// Arrange
+		type testObj struct {
+			Host   string `env:"HOST,required"`
+			Port   int    `env:"PORT"`
+			Nested struct {
+				Debug bool   `env:"DEBUG"`
+				User  string `env:"USER,required"`
+			}
+		}
+		mockEnvVarMap["PORT"] = "eighty"
+		mockEnvVarMap["DEBUG"] = "maybe"
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv())
+
+		// Assert
+		assertErrorWithSubStr(t, err, `env var "HOST" not set`)
+		assertErrorWithSubStr(t, err, `invalid int value supplied: "eighty"`)
+		assertErrorWithSubStr(t, err, `invalid bool value supplied: "maybe"`)
+		assertErrorWithSubStr(t, err, `env var "USER" not set`)
+	})
+
+	tRun(t, "embedded struct fields are reported once", func(t *testing.T) {
+		// Arrange
+		type Embedded struct {
+			Host string `env:"HOST,required"`
+		}
+		type testObj struct {
+			Embedded
+		}
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv())
+
+		// Assert
+		assertEqual(t, strings.Count(err.Error(), `env var "HOST" not set`), 1)
+	})
+}
+
+func TestLoad(t *testing.T) {
+	// Pre Arrange
+	type testObj struct {
+		Port int `env:"PORT,required"`
+	}
+
+	tRun(t, "returns populated struct", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["PORT"] = "8080"
+
+		// Act
+		cfg, err := Load[testObj](mockEnv())
+
+		// Assert
+		assertEqual(t, err, nil)
+		assertEqual(t, cfg.Port, 8080)
+	})
+
+	tRun(t, "honours options", func(t *testing.T) {
+		// Act
+		cfg, err := Load[testObj](WithLookuper(MapLookuper{"PORT": "9090"}))
+
+		// Assert
+		assertEqual(t, err, nil)
+		assertEqual(t, cfg.Port, 9090)
+	})
+
+	tRun(t, "returns error and nil struct on failure", func(t *testing.T) {
+		// Act
+		cfg, err := Load[testObj](mockEnv())
+
+		// Assert
+		assertErrorWithSubStr(t, err, `env var "PORT" not set`)
+		assertEqual(t, cfg == nil, true)
+	})
+
+	tRun(t, "non struct type errors", func(t *testing.T) {
+		// Act
+		_, err := Load[int]()
+
+		// Assert
+		assertErrorWithSubStr(t, err, "expected pointer to struct")
+	})
+}
+
+func TestProcessContext(t *testing.T) {
+	// Pre Arrange
+	type testObj struct {
+		Host string `env:"HOST"`
+		Port int    `env:"PORT"`
+	}
+
+	tRun(t, "context is passed to context lookupers", func(t *testing.T) {
+		// Arrange
+		type ctxKey struct{}
+		ctx := context.WithValue(context.Background(), ctxKey{}, "localhost")
+		l := ctxLookuperFunc(func(ctx context.Context, key string) (string, bool, error) {
+			if key != "HOST" {
+				return "", false, nil
+			}
+			val, ok := ctx.Value(ctxKey{}).(string)
+			return val, ok, nil
+		})
+
+		// Act
+		var in testObj
+		err := ProcessContext(ctx, &in, WithLookuper(l))
+
+		// Assert
+		assertEqual(t, err, nil)
+		assertEqual(t, in.Host, "localhost")
+	})
+
+	tRun(t, "cancellation stops processing", func(t *testing.T) {
+		// Arrange
+		ctx, cancel := context.WithCancel(context.Background())
+		var calls int
+		l := ctxLookuperFunc(func(ctx context.Context, key string) (string, bool, error) {
+			calls++
+			cancel()
+			return "", false, ctx.Err()
+		})
+
+		// Act
+		var in testObj
+		err := ProcessContext(ctx, &in, WithLookuper(l))
+
+		// Assert
+		assertEqual(t, errors.Is(err, context.Canceled), true)
+		assertErrorWithSubStr(t, err, `failed to look up env var "HOST"`)
+		assertEqual(t, calls, 1)
+	})
+}
+
+// ctxLookuperFunc adapts a function to the ContextLookuper interface.
+type ctxLookuperFunc func(ctx context.Context, key string) (string, bool, error)
+
+func (f ctxLookuperFunc) Lookup(key string) (string, bool) {
+	val, ok, _ := f(context.Background(), key)
+	return val, ok
+}
+
+func (f ctxLookuperFunc) LookupContext(ctx context.Context, key string) (string, bool, error) {
+	return f(ctx, key)
+}
+
+func TestProcess_FillOnly(t *testing.T) {
+	// Pre Arrange
+	type testObj struct {
+		Host string `env:"HOST,required"`
+		Port int    `env:"PORT,default=8080"`
+		User string `env:"USER"`
+	}
+
+	tRun(t, "only zero fields are assigned", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["HOST"] = "env-host"
+		mockEnvVarMap["USER"] = "env-user"
+		in := testObj{Host: "override", Port: 9090}
+
+		// Act
+		Process(&in, mockEnv(), WithFillOnly())
+
+		// Assert
+		assertEqual(t, in.Host, "override")
+		assertEqual(t, in.Port, 9090)
+		assertEqual(t, in.User, "env-user")
+	})
+
+	tRun(t, "non zero fields satisfy required", func(t *testing.T) {
+		// Arrange
+		in := testObj{Host: "override"}
+
+		// Act
+		err := ProcessE(&in, mockEnv(), WithFillOnly())
+
+		// Assert
+		assertEqual(t, err, nil)
+		assertEqual(t, in.Port, 8080)
+	})
+}
+
+func TestProcess_OverwritePolicy(t *testing.T) {
+	// Pre Arrange
+	type testObj struct {
+		Host string `env:"HOST,required"`
+		Port int    `env:"PORT,default=8080"`
+		User string `env:"USER"`
+	}
+	defaults := MapLookuper{"HOST": "default-host", "PORT": "1000", "USER": "default-user"}
+
+	tRun(t, "always overwrites with values and defaults", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["HOST"] = "env-host"
+		var in testObj
+		Process(&in, WithLookuper(defaults))
+
+		// Act
+		Process(&in, mockEnv(), WithOverwrite(OverwriteAlways))
+
+		// Assert
+		assertEqual(t, in.Host, "env-host")
+		assertEqual(t, in.Port, 8080)
+		assertEqual(t, in.User, "default-user")
+	})
+
+	tRun(t, "if set only overwrites with source values", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["USER"] = "env-user"
+		var in testObj
+		Process(&in, WithLookuper(defaults))
+
+		// Act
+		err := ProcessE(&in, mockEnv(), WithOverwrite(OverwriteIfSet))
+
+		// Assert
+		assertEqual(t, err, nil)
+		assertEqual(t, in.Host, "default-host")
+		assertEqual(t, in.Port, 1000)
+		assertEqual(t, in.User, "env-user")
+	})
+
+	tRun(t, "if set still applies defaults to zero fields", func(t *testing.T) {
+		// Arrange
+		in := testObj{Host: "host"}
+
+		// Act
+		Process(&in, mockEnv(), WithOverwrite(OverwriteIfSet))
+
+		// Assert
+		assertEqual(t, in.Port, 8080)
+	})
+
+	tRun(t, "never keeps non zero fields", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["HOST"] = "env-host"
+		mockEnvVarMap["USER"] = "env-user"
+		var in testObj
+		Process(&in, WithLookuper(defaults))
+
+		// Act
+		Process(&in, mockEnv(), WithOverwrite(OverwriteNever))
+
+		// Assert
+		assertEqual(t, in.Host, "default-host")
+		assertEqual(t, in.Port, 1000)
+		assertEqual(t, in.User, "default-user")
+	})
+}
+
+func TestProcess_Layers(t *testing.T) {
+	// Pre Arrange
+	type testObj struct {
+		Host  string `env:"HOST,required"`
+		Port  int    `env:"PORT,default=8080"`
+		Debug bool   `env:"DEBUG,default=true"`
+		User  string `env:"USER"`
+	}
+	base := MapLookuper{"PORT": "1000", "USER": "base-user", "DEBUG": "false"}
+	site := MapLookuper{"USER": "site-user"}
+
+	tRun(t, "later layers override earlier ones", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["HOST"] = "env-host"
+		mockEnvVarMap["PORT"] = "9000"
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, WithLayers(base, site, mockEnvVarMap))
+
+		// Assert
+		assertEqual(t, err, nil)
+		assertEqual(t, in.Host, "env-host")
+		assertEqual(t, in.Port, 9000)
+		assertEqual(t, in.User, "site-user")
+	})
+
+	tRun(t, "defaults do not replace values from earlier layers", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["HOST"] = "env-host"
+
+		// Act
+		var in testObj
+		Process(&in, WithLayers(base, mockEnvVarMap))
+
+		// Assert
+		assertEqual(t, in.Debug, false)
+		assertEqual(t, in.Port, 1000)
+	})
+
+	tRun(t, "required variables are checked against the merged layers", func(t *testing.T) {
+		// Act
+		var in testObj
+		err := ProcessE(&in, WithLayers(base, site, mockEnvVarMap))
+
+		// Assert
+		assertEqual(t, errors.Is(err, ErrMissing), true)
+		assertEqual(t, in.User, "site-user")
+	})
+}
+
+func TestProcess_StopTypes(t *testing.T) {
+	type message struct {
+		Name string `env:"NAME"`
+	}
+	type testObj struct {
+		Msg    message
+		MsgPtr *message
+		Other  string `env:"OTHER"`
+	}
+
+	tRun(t, "stop types are not recursed into", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["NAME"] = "name"
+		mockEnvVarMap["OTHER"] = "other"
+
+		// Act
+		var in testObj
+		Process(&in, mockEnv(), WithStopTypes(reflect.TypeOf(message{})))
+
+		// Assert
+		assertEqual(t, in.Msg.Name, "")
+		assertEqual(t, in.MsgPtr == nil, true)
+		assertEqual(t, in.Other, "other")
+	})
+
+	tRun(t, "other structs are still recursed into", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["NAME"] = "name"
+
+		// Act
+		var in testObj
+		Process(&in, mockEnv())
+
+		// Assert
+		assertEqual(t, in.Msg.Name, "name")
+		assertEqual(t, in.MsgPtr.Name, "name")
+	})
+
+	tRun(t, "exports and round trips do not recurse into stop types", func(t *testing.T) {
+		// Arrange
+		in := testObj{Msg: message{Name: "name"}, Other: "other"}
+		opt := WithStopTypes(reflect.TypeOf(message{}))
+
+		// Act
+		env, err := Marshal(in, opt)
+		roundTripErr := RoundTripCheck(testObj{Other: "other"}, opt)
+
+		// Assert
+		assertEqual(t, err, nil)
+		assertEqual(t, len(env), 1)
+		assertEqual(t, env["OTHER"], "other")
+		assertEqual(t, roundTripErr, nil)
+	})
+}
+
+func TestProcess_File(t *testing.T) {
+	type testObj struct {
+		Password string `env:"DB_PASSWORD_FILE,file"`
+		Port     int    `env:"PORT_FILE,file,default=/nonexistent/port"`
+	}
+
+	tRun(t, "values are read from the named files", func(t *testing.T) {
+		// Arrange
+		dir := t.TempDir()
+		os.WriteFile(filepath.Join(dir, "password"), []byte("s3cret\n"), 0o600)
+		os.WriteFile(filepath.Join(dir, "port"), []byte(" 5432 "), 0o600)
+		mockEnvVarMap["DB_PASSWORD_FILE"] = filepath.Join(dir, "password")
+		mockEnvVarMap["PORT_FILE"] = filepath.Join(dir, "port")
+
+		// Act
+		var in testObj
+		Process(&in, mockEnv())
+
+		// Assert
+		assertEqual(t, in.Password, "s3cret")
+		assertEqual(t, in.Port, 5432)
+	})
+
+	tRun(t, "unreadable files are reported", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["DB_PASSWORD_FILE"] = filepath.Join(t.TempDir(), "missing")
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv())
+
+		// Assert
+		var se *SourceError
+		assertEqual(t, errors.As(err, &se), true)
+		assertEqual(t, se.Var, "DB_PASSWORD_FILE")
+		assertErrorWithSubStr(t, err, `failed to read the file named by env var "PORT_FILE"`) // Defaults name files too.
+		assertEqual(t, errors.Is(err, fs.ErrNotExist), true)
+	})
+
+	tRun(t, "files are confined to the configured file system", func(t *testing.T) {
+		// Arrange
+		fsys := fstest.MapFS{
+			"db/password": {Data: []byte("s3cret\n")},
+		}
+		mockEnvVarMap["DB_PASSWORD_FILE"] = "db/password"
+		mockEnvVarMap["PORT_FILE"] = "../etc/passwd"
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv(), WithFileSystem(fsys))
+
+		// Assert
+		assertEqual(t, in.Password, "s3cret")
+		assertEqual(t, in.Port, 0)
+		assertErrorWithSubStr(t, err, `failed to read the file named by env var "PORT_FILE"`)
+	})
+
+	tRun(t, "files are read no further than the maximum value length", func(t *testing.T) {
+		// Arrange
+		path := filepath.Join(t.TempDir(), "password")
+		os.WriteFile(path, []byte(strings.Repeat("x", 1<<20)), 0o600)
+		mockEnvVarMap["DB_PASSWORD_FILE"] = path
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv(), WithMaxValueLength(1024))
+
+		// Assert
+		assertEqual(t, errors.Is(err, ErrValueTooLarge), true)
+		assertErrorWithSubStr(t, err, `env var "DB_PASSWORD_FILE": file exceeds limit of 1024 bytes`)
+		assertEqual(t, in.Password, "")
+	})
+}
+
+func TestProcess_Unset(t *testing.T) {
+	type testObj struct {
+		Token   string `env:"ENVCONF_TEST_TOKEN,unset"`
+		Port    int    `env:"ENVCONF_TEST_PORT,unset"`
+		Region  string `env:"ENVCONF_TEST_REGION"`
+		Profile string `env:"ENVCONF_TEST_PROFILE,unset,allowempty"`
+	}
+
+	tRun(t, "variables are removed once read", func(t *testing.T) {
+		// Arrange
+		t.Setenv("ENVCONF_TEST_TOKEN", "s3cret")
+		t.Setenv("ENVCONF_TEST_REGION", "eu-west-1")
+		t.Setenv("ENVCONF_TEST_PROFILE", "")
+
+		// Act
+		var in testObj
+		err := ProcessE(&in)
+
+		// Assert
+		assertEqual(t, err, nil)
+		assertEqual(t, in.Token, "s3cret")
+		_, ok := os.LookupEnv("ENVCONF_TEST_TOKEN")
+		assertEqual(t, ok, false)
+		_, ok = os.LookupEnv("ENVCONF_TEST_PROFILE")
+		assertEqual(t, ok, false)
+		_, ok = os.LookupEnv("ENVCONF_TEST_REGION")
+		assertEqual(t, ok, true) // Without the attribute.
+	})
+
+	tRun(t, "variables whose values fail to parse are kept", func(t *testing.T) {
+		// Arrange
+		t.Setenv("ENVCONF_TEST_PORT", "eighty")
+
+		// Act
+		var in testObj
+		err := ProcessE(&in)
+
+		// Assert
+		assertErrorWithSubStr(t, err, `invalid int value supplied`)
+		val, _ := os.LookupEnv("ENVCONF_TEST_PORT")
+		assertEqual(t, val, "eighty")
+	})
+
+	tRun(t, "variables read from a snapshot of the environment are removed", func(t *testing.T) {
+		// Arrange
+		t.Setenv("ENVCONF_TEST_TOKEN", "s3cret")
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, WithSnapshot())
+
+		// Assert
+		assertEqual(t, err, nil)
+		assertEqual(t, in.Token, "s3cret")
+		_, ok := os.LookupEnv("ENVCONF_TEST_TOKEN")
+		assertEqual(t, ok, false)
+	})
+
+	tRun(t, "variables supplied by other sources leave the environment untouched", func(t *testing.T) {
+		// Arrange
+		t.Setenv("ENVCONF_TEST_TOKEN", "from-env")
+		mockEnvVarMap["ENVCONF_TEST_TOKEN"] = "from-map"
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, WithLayers(OSLookuper{}, mockEnvVarMap))
+
+		// Assert
+		assertEqual(t, err, nil)
+		assertEqual(t, in.Token, "from-map")
+		val, _ := os.LookupEnv("ENVCONF_TEST_TOKEN")
+		assertEqual(t, val, "from-env")
+	})
+
+	tRun(t, "Validate leaves the environment untouched", func(t *testing.T) {
+		// Arrange
+		t.Setenv("ENVCONF_TEST_TOKEN", "s3cret")
+
+		// Act
+		err := Validate(testObj{}, OSLookuper{})
+
+		// Assert
+		assertEqual(t, err, nil)
+		val, _ := os.LookupEnv("ENVCONF_TEST_TOKEN")
+		assertEqual(t, val, "s3cret")
+	})
+
+	tRun(t, "RoundTripCheck leaves the environment untouched", func(t *testing.T) {
+		// Arrange
+		t.Setenv("ENVCONF_TEST_TOKEN", "s3cret")
+
+		// Act
+		err := RoundTripCheck(testObj{Token: "t0ken"})
+
+		// Assert
+		assertEqual(t, err, nil)
+		val, _ := os.LookupEnv("ENVCONF_TEST_TOKEN")
+		assertEqual(t, val, "s3cret")
+	})
+
+	tRun(t, "tenant variables are removed by their prefixed names", func(t *testing.T) {
+		// Arrange
+		t.Setenv("ENVCONF_TEST_TOKEN", "shared")
+		t.Setenv("ENVCONF_TENANT_acme_ENVCONF_TEST_TOKEN", "acme-s3cret")
+
+		// Act
+		tenants, err := ProcessTenants[testObj]("ENVCONF_TENANT_<ID>_")
+
+		// Assert
+		assertEqual(t, err, nil)
+		assertEqual(t, tenants["acme"].Token, "acme-s3cret")
+		_, ok := os.LookupEnv("ENVCONF_TENANT_acme_ENVCONF_TEST_TOKEN")
+		assertEqual(t, ok, false)
+		val, _ := os.LookupEnv("ENVCONF_TEST_TOKEN")
+		assertEqual(t, val, "shared")
+	})
+}
+
+func TestProcess_DefaultFrom(t *testing.T) {
+	type testObj struct {
+		ListenAddr  string `env:"LISTEN_ADDR"`
+		MetricsAddr string `env:"METRICS_ADDR,defaultFrom=LISTEN_ADDR,default=:9090"`
+		AdminAddr   string `env:"ADMIN_ADDR,defaultFrom=LISTEN_ADDR,required"`
+	}
+
+	tRun(t, "unset variables default to the other variable", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["LISTEN_ADDR"] = ":8080"
+
+		// Act
+		var in testObj
+		Process(&in, mockEnv())
+
+		// Assert
+		assertEqual(t, in.MetricsAddr, ":8080")
+		assertEqual(t, in.AdminAddr, ":8080")
+	})
+
+	tRun(t, "set variables take precedence", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["LISTEN_ADDR"] = ":8080"
+		mockEnvVarMap["METRICS_ADDR"] = ":2112"
+
+		// Act
+		var in testObj
+		Process(&in, mockEnv())
+
+		// Assert
+		assertEqual(t, in.MetricsAddr, ":2112")
+	})
+
+	tRun(t, "the literal default is the last resort", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["ADMIN_ADDR"] = ":8081"
+
+		// Act
+		var in testObj
+		Process(&in, mockEnv())
+
+		// Assert
+		assertEqual(t, in.MetricsAddr, ":9090")
+	})
+
+	tRun(t, "required fields are missing if neither is set", func(t *testing.T) {
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv())
+
+		// Assert
+		var me *MissingError
+		assertEqual(t, errors.As(err, &me), true)
+		assertEqual(t, me.Var, "ADMIN_ADDR")
+	})
+}
+
+func TestProcess_TagName(t *testing.T) {
+	type testObj struct {
+		Port  int    `config:"PORT,default=8080" env:"IGNORED_PORT"`
+		Name  string `config:"NAME,required"`
+		Other string `env:"OTHER"`
+		DB    struct {
+			Host string `config:"HOST"`
+		} `config:",prefix=DB_"`
+	}
+
+	tRun(t, "fields are configured by the named tag", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["NAME"] = "app"
+		mockEnvVarMap["IGNORED_PORT"] = "9090"
+		mockEnvVarMap["OTHER"] = "other"
+		mockEnvVarMap["DB_HOST"] = "db.example.com"
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv(), WithTagName("config"))
+
+		// Assert
+		assertEqual(t, err, nil)
+		assertEqual(t, in.Port, 8080)
+		assertEqual(t, in.Name, "app")
+		assertEqual(t, in.Other, "")
+		assertEqual(t, in.DB.Host, "db.example.com")
+	})
+
+	tRun(t, "round trips use the named tag", func(t *testing.T) {
+		// Arrange
+		in := testObj{Port: 9090, Name: "app"}
+
+		// Act
+		err := RoundTripCheck(in, WithTagName("config"))
+
+		// Assert
+		assertEqual(t, err, nil)
+	})
+
+	tRun(t, "exports and generators use the named tag", func(t *testing.T) {
+		// Arrange
+		in := testObj{Port: 9090, Name: "app"}
+		opt := WithTagName("config")
+
+		// Act
+		var keys []string
+		for fi := range Fields(in, opt) {
+			keys = append(keys, fi.Key)
+		}
+		env, err := Marshal(in, opt)
+		redacted, redactErr := Redacted(in, opt)
+		generated := make(map[string]string)
+		for name, gen := range map[string]func(io.Writer, any, ...Option) error{
+			"Usage":                   Usage,
+			"WriteDotEnv":             WriteDotEnv,
+			"WriteManifest":           WriteManifest,
+			"WriteMarkdown":           WriteMarkdown,
+			"WriteTerraformVariables": WriteTerraformVariables,
+			"WriteTFVars":             WriteTFVars,
+		} {
+			var b strings.Builder
+			if err := gen(&b, in, opt); err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			generated[name] = strings.ToUpper(b.String())
+		}
+
+		// Assert
+		assertEqual(t, strings.Join(keys, ","), "PORT,NAME,DB_HOST")
+		assertEqual(t, err, nil)
+		assertEqual(t, env["PORT"], "9090")
+		assertEqual(t, redactErr, nil)
+		assertEqual(t, redacted["NAME"], "app")
+		for name, out := range generated {
+			if !strings.Contains(out, "DB_HOST") || strings.Contains(out, "IGNORED_PORT") {
+				t.Errorf("%s did not use the named tag:\n%s", name, out)
+			}
+		}
+	})
+}
+
+func TestProcess_EnvDefaultTag(t *testing.T) {
+	tRun(t, "the companion tag supplies the default", func(t *testing.T) {
+		// Arrange
+		type testObj struct {
+			Port  int      `env:"PORT" envDefault:"8080"`
+			Hosts []string `env:"HOSTS" envDefault:"a,b"`
+			Name  string   `env:"NAME,required" envDefault:"app"`
+		}
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv())
+
+		// Assert
+		assertEqual(t, err, nil)
+		assertEqual(t, in.Port, 8080)
+		assertEqual(t, len(in.Hosts), 2)
+		assertEqual(t, in.Name, "app")
+	})
+
+	tRun(t, "set variables take precedence", func(t *testing.T) {
+		// Arrange
+		type testObj struct {
+			Port int `env:"PORT" envDefault:"8080"`
+		}
+		mockEnvVarMap["PORT"] = "9090"
+
+		// Act
+		var in testObj
+		Process(&in, mockEnv())
+
+		// Assert
+		assertEqual(t, in.Port, 9090)
+	})
+
+	tRun(t, "is exclusive with the default attribute", func(t *testing.T) {
+		// Arrange
+		type testObj struct {
+			Port int `env:"PORT,default=80" envDefault:"8080"`
+		}
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv())
+
+		// Assert
+		assertErrorWithSubStr(t, err, "default struct tag attribute and envDefault tag are mutually exclusive")
+	})
+
+	tRun(t, "appears in generated outputs", func(t *testing.T) {
+		// Arrange
+		type testObj struct {
+			Port int `env:"PORT" envDefault:"8080"`
+		}
+
+		// Act
+		var got []FieldInfo
+		for fi := range Fields(testObj{}) {
+			got = append(got, fi)
+		}
+
+		// Assert
+		assertEqual(t, got[0].Default, "8080")
 	})
 }
@@ -0,0 +1,111 @@
+// Package envconftest runs configuration structs through envconf against the
+// real process environment, exercising the same os.LookupEnv path that
+// applications use in production rather than a mock Lookuper.
+//
+// Variables are set with testing.T.Setenv, so tests using this package
+// cannot run in parallel and the environment is restored when each test ends:
+//
+//	func TestConfig(t *testing.T) {
+//		envconftest.Run(t, []envconftest.Case[Config]{{
+//			Name: "defaults",
+//			Want: Config{Port: 8080},
+//		}, {
+//			Name:    "port must be numeric",
+//			Env:     map[string]string{"PORT": "http"},
+//			WantErr: `invalid int value supplied: "http"`,
+//		}})
+//	}
+package envconftest
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/rmerry/envconf"
+)
+
+// Case describes a single entry of the table passed to Run.
+type Case[T any] struct {
+	Name string
+
+	// Env holds the variables set for the case. Every other variable read by
+	// T is unset, so the case does not depend on the environment the tests
+	// happen to run in.
+	Env map[string]string
+
+	// Want is the expected result, compared with reflect.DeepEqual. It is
+	// ignored when WantErr or Check is set.
+	Want T
+
+	// WantErr, if not empty, is a substring of the error expected from
+	// envconf.ProcessE.
+	WantErr string
+
+	// Check, if not nil, is called with the result in place of comparing it
+	// with Want, for types reflect.DeepEqual cannot usefully compare.
+	Check func(t *testing.T, got *T)
+}
+
+// Run runs each of `cases` as a subtest of `t`: it replaces the environment
+// as described by Case.Env, processes a zero T with envconf.ProcessE and
+// `opts`, and checks the outcome. `opts` should not include a Lookuper, or
+// the real environment is not consulted.
+func Run[T any](t *testing.T, cases []Case[T], opts ...envconf.Option) {
+	t.Helper()
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			Setenv(t, new(T), c.Env)
+
+			var got T
+			err := envconf.ProcessE(&got, opts...)
+
+			switch {
+			case c.WantErr != "":
+				if err == nil || !strings.Contains(err.Error(), c.WantErr) {
+					t.Fatalf("expected error containing %q, got: %v", c.WantErr, err)
+				}
+			case err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			case c.Check != nil:
+				c.Check(t, &got)
+			case !reflect.DeepEqual(got, c.Want):
+				t.Errorf("expected %+v, got: %+v", c.Want, got)
+			}
+		})
+	}
+}
+
+// Setenv sets the variables in `env` for the duration of the test `t`, and
+// unsets every other variable read by the struct (or pointer to struct) `v`
+// (see envconf.Fields). Variables named by indexed fields beyond those
+// listed in `env` are left untouched. The environment is restored when the
+// test ends.
+func Setenv(t testing.TB, v any, env map[string]string) {
+	t.Helper()
+
+	for fi := range envconf.Fields(v) {
+		if _, ok := env[fi.Key]; !ok {
+			unsetenv(t, fi.Key)
+		}
+	}
+	if _, ok := env[envconf.SchemaVersionKey]; !ok {
+		unsetenv(t, envconf.SchemaVersionKey)
+	}
+	for k, val := range env {
+		t.Setenv(k, val)
+	}
+}
+
+// unsetenv unsets `key` until the test `t` ends. testing.T.Setenv records the
+// original value, so it is restored (or removed) afterwards.
+func unsetenv(t testing.TB, key string) {
+	t.Helper()
+
+	t.Setenv(key, "")
+	if err := os.Unsetenv(key); err != nil {
+		t.Fatalf("failed to unset %s: %v", key, err)
+	}
+}
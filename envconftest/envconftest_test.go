@@ -0,0 +1,67 @@
+package envconftest
+
+import (
+	"os"
+	"testing"
+
+	"github.com/rmerry/envconf"
+)
+
+type config struct {
+	Host string   `env:"ENVCONFTEST_HOST,required"`
+	Port int      `env:"ENVCONFTEST_PORT,default=8080"`
+	Tags []string `env:"ENVCONFTEST_TAGS"`
+}
+
+func TestRun(t *testing.T) {
+	// Arrange
+	t.Setenv("ENVCONFTEST_TAGS", "leak") // Must not leak into the cases.
+
+	// Act
+	Run(t, []Case[config]{{
+		Name: "values are read from the environment",
+		Env:  map[string]string{"ENVCONFTEST_HOST": "db", "ENVCONFTEST_PORT": "5432"},
+		Want: config{Host: "db", Port: 5432},
+	}, {
+		Name:    "errors are matched",
+		Env:     map[string]string{"ENVCONFTEST_PORT": "http"},
+		WantErr: `env var "ENVCONFTEST_HOST" not set`,
+	}, {
+		Name: "results can be checked by hand",
+		Env:  map[string]string{"ENVCONFTEST_HOST": "db", "ENVCONFTEST_TAGS": "a,b"},
+		Check: func(t *testing.T, got *config) {
+			if len(got.Tags) != 2 {
+				t.Errorf("expected 2 tags, got: %v", got.Tags)
+			}
+		},
+	}})
+
+	// Assert
+	if got := os.Getenv("ENVCONFTEST_TAGS"); got != "leak" {
+		t.Errorf("expected the environment to be restored, got: %q", got)
+	}
+}
+
+func TestSetenv(t *testing.T) {
+	// Arrange
+	t.Setenv("ENVCONFTEST_PORT", "1")
+
+	t.Run("unlisted variables are unset", func(t *testing.T) {
+		// Act
+		Setenv(t, config{}, map[string]string{"ENVCONFTEST_HOST": "db"})
+
+		// Assert
+		if _, ok := os.LookupEnv("ENVCONFTEST_PORT"); ok {
+			t.Error("expected ENVCONFTEST_PORT to be unset")
+		}
+		cfg, err := envconf.Load[config]()
+		if err != nil || cfg.Host != "db" || cfg.Port != 8080 {
+			t.Errorf("unexpected result: %+v, %v", cfg, err)
+		}
+	})
+
+	// Assert
+	if got := os.Getenv("ENVCONFTEST_PORT"); got != "1" {
+		t.Errorf("expected the environment to be restored, got: %q", got)
+	}
+}
@@ -0,0 +1,289 @@
+package envconf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrorCode is a stable, machine-readable classification of a failure,
+// allowing orchestration systems to branch on the kind of failure rather than
+// on error messages (see ErrorCodes).
+type ErrorCode string
+
+// Error codes reported by ErrorCodes and the Code methods of the error types
+// in this package.
+const (
+	CodeMissingRequired       ErrorCode = "MISSING_REQUIRED"
+	CodeParseError            ErrorCode = "PARSE_ERROR"
+	CodeConstraintViolation   ErrorCode = "CONSTRAINT_VIOLATION"
+	CodeSourceUnavailable     ErrorCode = "SOURCE_UNAVAILABLE"
+	CodeValueTooLarge         ErrorCode = "VALUE_TOO_LARGE"
+	CodeSchemaVersionMismatch ErrorCode = "SCHEMA_VERSION_MISMATCH"
+	CodePanic                 ErrorCode = "PANIC"
+	CodeUnknown               ErrorCode = "UNKNOWN" // E.g. a malformed struct tag.
+)
+
+// ErrorCodes returns the code of every failure reported by `err`, which may
+// be a joined error such as those returned by ProcessE, in order and without
+// duplicates. It returns nil if `err` is nil.
+func ErrorCodes(err error) []ErrorCode {
+	var (
+		codes []ErrorCode
+		seen  = make(map[ErrorCode]bool)
+		walk  func(error)
+	)
+	walk = func(err error) {
+		var code ErrorCode
+		switch e := err.(type) {
+		case interface{ Code() ErrorCode }:
+			code = e.Code()
+		case interface{ Unwrap() []error }:
+			for _, err := range e.Unwrap() {
+				walk(err)
+			}
+			return
+		case interface{ Unwrap() error }:
+			walk(e.Unwrap())
+			return
+		default:
+			code = leafCode(err)
+		}
+		if !seen[code] {
+			seen[code] = true
+			codes = append(codes, code)
+		}
+	}
+	if err != nil {
+		walk(err)
+	}
+
+	return codes
+}
+
+// leafCode returns the code of the sentinel or foreign error `err`, which
+// wraps no other errors.
+func leafCode(err error) ErrorCode {
+	switch err {
+	case ErrValueTooLarge:
+		return CodeValueTooLarge
+	case context.Canceled, context.DeadlineExceeded:
+		return CodeSourceUnavailable
+	}
+
+	return CodeUnknown
+}
+
+var (
+	// ErrMissing is matched (see errors.Is) by every MissingError and
+	// MissingGroupError.
+	ErrMissing = errors.New("env var not set")
+
+	// ErrParse is matched (see errors.Is) by every ParseError.
+	ErrParse = errors.New("invalid env var value")
+
+	// ErrValueTooLarge is matched (see errors.Is) by errors reporting a value
+	// that exceeds a configured size limit (see WithMaxValueLength and
+	// WithMaxDecodedSize).
+	ErrValueTooLarge = errors.New("value too large")
+
+	// ErrConstraint is matched (see errors.Is) by every ConstraintError.
+	ErrConstraint = errors.New("constraint violated")
+
+	// ErrSource is matched (see errors.Is) by every SourceError.
+	ErrSource = errors.New("env var source unavailable")
+
+	// ErrSchemaVersion is matched (see errors.Is) by every
+	// SchemaVersionError.
+	ErrSchemaVersion = errors.New("config schema version mismatch")
+)
+
+// MissingError reports that a required variable was not set and no default
+// value was provided.
+type MissingError struct {
+	Var   string // Name of the variable, e.g. "PORT".
+	Field string // Path of the struct field, e.g. "Server.Port".
+}
+
+func (e *MissingError) Error() string {
+	return fmt.Sprintf("env var %q not set", e.Var)
+}
+
+// Is reports whether `target` is ErrMissing.
+func (e *MissingError) Is(target error) bool {
+	return target == ErrMissing
+}
+
+// Code returns CodeMissingRequired.
+func (e *MissingError) Code() ErrorCode {
+	return CodeMissingRequired
+}
+
+// MissingGroupError reports that none of the variables of a required group
+// (see the `group` and `required_group` attributes) was set.
+type MissingGroupError struct {
+	Group string   // Name of the group, e.g. "auth".
+	Vars  []string // Names of the group's variables, e.g. ["API_KEY", "OAUTH_TOKEN"].
+}
+
+func (e *MissingGroupError) Error() string {
+	vars := make([]string, len(e.Vars))
+	for i, v := range e.Vars {
+		vars[i] = strconv.Quote(v)
+	}
+	return fmt.Sprintf("none of env vars %s set (group %q requires at least one)",
+		strings.Join(vars, ", "), e.Group)
+}
+
+// Is reports whether `target` is ErrMissing.
+func (e *MissingGroupError) Is(target error) bool {
+	return target == ErrMissing
+}
+
+// Code returns CodeMissingRequired.
+func (e *MissingGroupError) Code() ErrorCode {
+	return CodeMissingRequired
+}
+
+// ParseError reports that a variable's value could not be converted to the
+// type of its struct field.
+type ParseError struct {
+	Var   string // Name of the variable, e.g. "PORT".
+	Field string // Path of the struct field, e.g. "Server.Port".
+	Value string // The offending value, masked if the field is secret.
+	Kind  string // The type being parsed, e.g. "int".
+	Err   error  // The underlying conversion error, which may echo the value unless the field is secret.
+}
+
+func (e *ParseError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("env var %q: invalid %s value supplied: %q", e.Var, e.Kind, e.Value)
+	}
+	return fmt.Sprintf("env var %q: invalid %s value supplied: %q: %v", e.Var, e.Kind, e.Value, e.Err)
+}
+
+// Unwrap returns the underlying conversion error.
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether `target` is ErrParse.
+func (e *ParseError) Is(target error) bool {
+	return target == ErrParse
+}
+
+// Code returns CodeParseError.
+func (e *ParseError) Code() ErrorCode {
+	return CodeParseError
+}
+
+// Operations reported by SourceError.
+const (
+	opLookup   = "look up"
+	opResolve  = "resolve"
+	opReadFile = "read the file named by"
+	opUnset    = "unset"
+)
+
+// SourceError reports that a variable could not be retrieved from its source,
+// e.g. because a secret store was unreachable or the lookup was cancelled.
+type SourceError struct {
+	Var string // Name of the variable, e.g. "DB_PASSWORD".
+	Op  string // The failed operation, e.g. "look up" or "resolve".
+	Err error  // The underlying error.
+}
+
+func (e *SourceError) Error() string {
+	return fmt.Sprintf("failed to %s env var %q: %v", e.Op, e.Var, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *SourceError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether `target` is ErrSource.
+func (e *SourceError) Is(target error) bool {
+	return target == ErrSource
+}
+
+// Code returns CodeSourceUnavailable.
+func (e *SourceError) Code() ErrorCode {
+	return CodeSourceUnavailable
+}
+
+// ConstraintError reports that populated fields violate a cross-field
+// constraint (see WithConstraints).
+type ConstraintError struct {
+	Constraint string // The constraint expression, e.g. "MaxConns >= MinConns".
+	Left       string // Path of the left-hand field, e.g. "MaxConns".
+	Right      string // Path of the right-hand field, e.g. "MinConns".
+
+	// LeftValue and RightValue hold the formatted values of the fields. They
+	// are empty if either field is marked `secret`.
+	LeftValue, RightValue string
+}
+
+func (e *ConstraintError) Error() string {
+	if e.LeftValue == "" && e.RightValue == "" {
+		return fmt.Sprintf("constraint %q violated", e.Constraint)
+	}
+	return fmt.Sprintf("constraint %q violated: %s is %s, %s is %s",
+		e.Constraint, e.Left, e.LeftValue, e.Right, e.RightValue)
+}
+
+// Is reports whether `target` is ErrConstraint.
+func (e *ConstraintError) Is(target error) bool {
+	return target == ErrConstraint
+}
+
+// Code returns CodeConstraintViolation.
+func (e *ConstraintError) Code() ErrorCode {
+	return CodeConstraintViolation
+}
+
+// SchemaVersionError reports that the environment was written for a different
+// version of the configuration struct (see WithSchemaVersionCheck).
+type SchemaVersionError struct {
+	Declared string // The version declared by the struct.
+	Supplied string // The version held by SchemaVersionKey.
+}
+
+func (e *SchemaVersionError) Error() string {
+	return fmt.Sprintf(
+		"config schema version mismatch: environment provides version %q but %q is required",
+		e.Supplied, e.Declared)
+}
+
+// Is reports whether `target` is ErrSchemaVersion.
+func (e *SchemaVersionError) Is(target error) bool {
+	return target == ErrSchemaVersion
+}
+
+// Code returns CodeSchemaVersionMismatch.
+func (e *SchemaVersionError) Code() ErrorCode {
+	return CodeSchemaVersionMismatch
+}
+
+// PanicError reports a panic recovered during processing (see Safe).
+type PanicError struct {
+	Value any    // The value passed to panic.
+	Stack []byte // The stack trace of the panicking goroutine.
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("envconf: recovered from panic: %v", e.Value)
+}
+
+// Unwrap returns the panic value if it is an error.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// Code returns CodePanic.
+func (e *PanicError) Code() ErrorCode {
+	return CodePanic
+}
@@ -0,0 +1,205 @@
+package envconf
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+func TestProcessE_TypedErrors(t *testing.T) {
+	// Pre Arrange
+	type testObj struct {
+		Server struct {
+			Host string `env:"HOST,required"`
+			Port int    `env:"PORT"`
+		}
+	}
+
+	tRun(t, "missing variables are MissingErrors", func(t *testing.T) {
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv())
+
+		// Assert
+		assertEqual(t, errors.Is(err, ErrMissing), true)
+		assertEqual(t, errors.Is(err, ErrParse), false)
+
+		var missing *MissingError
+		if !errors.As(err, &missing) {
+			t.Fatalf("expected a *MissingError, got: %v", err)
+		}
+		assertEqual(t, missing.Var, "HOST")
+		assertEqual(t, missing.Field, "Server.Host")
+	})
+
+	tRun(t, "unparseable variables are ParseErrors", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["HOST"] = "localhost"
+		mockEnvVarMap["PORT"] = "eighty"
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv())
+
+		// Assert
+		assertEqual(t, errors.Is(err, ErrParse), true)
+		assertEqual(t, errors.Is(err, ErrMissing), false)
+		assertEqual(t, errors.Is(err, strconv.ErrSyntax), true)
+
+		var parseErr *ParseError
+		if !errors.As(err, &parseErr) {
+			t.Fatalf("expected a *ParseError, got: %v", err)
+		}
+		assertEqual(t, parseErr.Var, "PORT")
+		assertEqual(t, parseErr.Field, "Server.Port")
+		assertEqual(t, parseErr.Value, "eighty")
+		assertEqual(t, parseErr.Kind, "int")
+	})
+
+	tRun(t, "joined errors name each variable and the reason it was rejected", func(t *testing.T) {
+		// Arrange
+		type testObj struct {
+			Port    int    `env:"PORT"`
+			Level   string `env:"LEVEL,oneof=debug|info"`
+			Workers int    `env:"WORKERS,min=1"`
+			PIN     int    `env:"PIN,secret"`
+		}
+		mockEnvVarMap["PORT"] = "eighty"
+		mockEnvVarMap["LEVEL"] = "verbose"
+		mockEnvVarMap["WORKERS"] = "0"
+		mockEnvVarMap["PIN"] = "12ab"
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv())
+
+		// Assert
+		assertErrorWithSubStr(t, err,
+			`env var "PORT": invalid int value supplied: "eighty": strconv.ParseInt: parsing "eighty": invalid syntax`)
+		assertErrorWithSubStr(t, err,
+			`env var "LEVEL": invalid string value supplied: "verbose": "verbose" is not one of debug, info`)
+		assertErrorWithSubStr(t, err,
+			`env var "WORKERS": invalid int value supplied: "0": "0" is less than the minimum of 1`)
+		assertErrorWithSubStr(t, err,
+			`env var "PIN": invalid int value supplied: "********": invalid syntax`)
+	})
+}
+
+func TestProcessE_Safe(t *testing.T) {
+	// Pre Arrange
+	type testObj struct {
+		Host string `env:"HOST"`
+	}
+	panicking := LookuperFunc(func(string) (string, bool) {
+		panic("lookuper exploded")
+	})
+
+	tRun(t, "panics are returned as errors", func(t *testing.T) {
+		// Act
+		var in testObj
+		err := ProcessE(&in, WithLookuper(panicking), Safe())
+
+		// Assert
+		var panicErr *PanicError
+		if !errors.As(err, &panicErr) {
+			t.Fatalf("expected a *PanicError, got: %v", err)
+		}
+		assertEqual(t, panicErr.Value, "lookuper exploded")
+		assertEqual(t, len(panicErr.Stack) > 0, true)
+	})
+
+	tRun(t, "panics propagate without Safe", func(t *testing.T) {
+		// Assert
+		defer assertPanicWithSubStr(t, "lookuper exploded")
+
+		// Act
+		var in testObj
+		ProcessE(&in, WithLookuper(panicking))
+	})
+}
+
+// failingLookuper is a ContextLookuper whose every lookup fails.
+type failingLookuper struct{ err error }
+
+func (f failingLookuper) Lookup(string) (string, bool) { return "", false }
+
+func (f failingLookuper) LookupContext(context.Context, string) (string, bool, error) {
+	return "", false, f.err
+}
+
+func TestErrorCodes(t *testing.T) {
+	type testObj struct {
+		Host string `env:"HOST,required"`
+		Port int    `env:"PORT"`
+		Min  int    `env:"MIN"`
+		Max  int    `env:"MAX"`
+	}
+
+	tests := map[string]struct {
+		env  map[string]string
+		opts []Option
+		want []ErrorCode
+	}{
+		"missing": {
+			want: []ErrorCode{CodeMissingRequired},
+		},
+		"parse": {
+			env:  map[string]string{"HOST": "h", "PORT": "eighty"},
+			want: []ErrorCode{CodeParseError},
+		},
+		"constraint": {
+			env:  map[string]string{"HOST": "h", "MIN": "2", "MAX": "1"},
+			opts: []Option{WithConstraints("Max >= Min")},
+			want: []ErrorCode{CodeConstraintViolation},
+		},
+		"too large": {
+			env:  map[string]string{"HOST": "localhost"},
+			opts: []Option{WithMaxValueLength(4)},
+			want: []ErrorCode{CodeValueTooLarge},
+		},
+		"several": {
+			env:  map[string]string{"PORT": "eighty", "MIN": "x"},
+			want: []ErrorCode{CodeMissingRequired, CodeParseError},
+		},
+	}
+	for name, tc := range tests {
+		tRun(t, name, func(t *testing.T) {
+			// Arrange
+			for k, v := range tc.env {
+				mockEnvVarMap[k] = v
+			}
+
+			// Act
+			var in testObj
+			err := ProcessE(&in, append([]Option{mockEnv()}, tc.opts...)...)
+
+			// Assert
+			assertEqual(t, slices.Equal(ErrorCodes(err), tc.want), true)
+		})
+	}
+
+	tRun(t, "unavailable sources", func(t *testing.T) {
+		// Arrange
+		type testObj struct {
+			Password string `env:"DB_PASSWORD,source=vault"`
+		}
+		vault := failingLookuper{errors.New("connection refused")}
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, WithSource("vault", vault))
+
+		// Assert
+		var se *SourceError
+		assertEqual(t, errors.As(err, &se), true)
+		assertEqual(t, se.Var, "DB_PASSWORD")
+		assertEqual(t, errors.Is(err, ErrSource), true)
+		assertEqual(t, slices.Equal(ErrorCodes(err), []ErrorCode{CodeSourceUnavailable}), true)
+	})
+
+	tRun(t, "no error has no codes", func(t *testing.T) {
+		assertEqual(t, ErrorCodes(nil) == nil, true)
+	})
+}
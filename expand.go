@@ -0,0 +1,53 @@
+package envconf
+
+import (
+	"os"
+	"regexp"
+)
+
+// windowsVarRe matches the variable references recognised when Windows
+// expansion is enabled: ${VAR}, $VAR, %VAR% and the escaped percent sign %%.
+var windowsVarRe = regexp.MustCompile(
+	`\$\{[^}]*\}|\$[A-Za-z0-9_]+|%[A-Za-z_][A-Za-z0-9_()]*%|%%`)
+
+// expand substitutes references to other variables in `val` (see the `expand`
+// attribute) with their values, read from the configured Lookuper.
+// References use the syntax of os.Expand and, if enabled with
+// WithWindowsExpansion, %VAR%. Substituted values are not themselves
+// expanded.
+func (o *options) expand(val string) (string, error) {
+	var firstErr error
+	mapping := func(name string) string {
+		v, _, err := o.lookup(name)
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+		return v
+	}
+
+	if !o.windowsExpansion {
+		return os.Expand(val, mapping), firstErr
+	}
+
+	val = windowsVarRe.ReplaceAllStringFunc(val, func(ref string) string {
+		switch {
+		case ref == "%%":
+			return "%"
+		case ref[0] == '%':
+			// As in cmd.exe, references to unset variables are left as is.
+			v, ok, err := o.lookup(ref[1 : len(ref)-1])
+			if err != nil && firstErr == nil {
+				firstErr = err
+			}
+			if !ok {
+				return ref
+			}
+			return v
+		case ref[1] == '{':
+			return mapping(ref[2 : len(ref)-1])
+		}
+		return mapping(ref[1:])
+	})
+
+	return val, firstErr
+}
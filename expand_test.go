@@ -0,0 +1,70 @@
+package envconf
+
+import "testing"
+
+func TestProcess_Expand(t *testing.T) {
+	type testObj struct {
+		Addr    string `env:"ADDR,expand"`
+		Literal string `env:"LITERAL"`
+		Path    string `env:"DATA_PATH,expand"`
+	}
+
+	tRun(t, "references are substituted", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["HOST"] = "db.example.com"
+		mockEnvVarMap["PORT"] = "5432"
+		mockEnvVarMap["ADDR"] = "${HOST}:$PORT"
+		mockEnvVarMap["LITERAL"] = "${HOST}"
+
+		// Act
+		var in testObj
+		Process(&in, mockEnv())
+
+		// Assert
+		assertEqual(t, in.Addr, "db.example.com:5432")
+		assertEqual(t, in.Literal, "${HOST}")
+	})
+
+	tRun(t, "defaults are expanded", func(t *testing.T) {
+		// Arrange
+		type testObj struct {
+			Addr string `env:"ADDR,expand,default=${HOST}:${PORT}"`
+		}
+		mockEnvVarMap["HOST"] = "localhost"
+		mockEnvVarMap["PORT"] = "8080"
+
+		// Act
+		var in testObj
+		Process(&in, mockEnv())
+
+		// Assert
+		assertEqual(t, in.Addr, "localhost:8080")
+	})
+
+	tRun(t, "windows syntax is ignored unless enabled", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["APPDATA"] = `C:\Users\app\AppData`
+		mockEnvVarMap["DATA_PATH"] = `%APPDATA%\svc`
+
+		// Act
+		var in testObj
+		Process(&in, mockEnv())
+
+		// Assert
+		assertEqual(t, in.Path, `%APPDATA%\svc`)
+	})
+
+	tRun(t, "windows syntax is recognised when enabled", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["APPDATA"] = `C:\Users\app\AppData`
+		mockEnvVarMap["HOST"] = "db"
+		mockEnvVarMap["DATA_PATH"] = `%APPDATA%\svc\%UNSET%\100%%\${HOST}`
+
+		// Act
+		var in testObj
+		Process(&in, mockEnv(), WithWindowsExpansion())
+
+		// Assert
+		assertEqual(t, in.Path, `C:\Users\app\AppData\svc\%UNSET%\100%\db`)
+	})
+}
@@ -0,0 +1,192 @@
+package envconf
+
+import (
+	"errors"
+	"iter"
+	"reflect"
+)
+
+// FieldInfo describes a single tagged field of a struct, as consumed by
+// Process.
+type FieldInfo struct {
+	Key      string // Name of the variable, e.g. "PORT".
+	Field    string // Path of the struct field, e.g. "Server.Port".
+	Group    string // Path of the enclosing struct, empty at the top level.
+	Type     reflect.Type
+	Required bool   // Set by the `required` attribute.
+	Default  string // Set by the `default` attribute.
+	Secret   bool   // Set by the `secret` attribute.
+
+	// Description is a human description of the variable, set by the `desc`
+	// attribute.
+	Description string
+
+	// Value is the field's current value. It is invalid when the field lives
+	// under a nil struct pointer.
+	Value reflect.Value
+
+	tag  fieldTag
+	elem bool // An element of an indexed slice or prefix map, written even if zero.
+}
+
+// Fields returns an iterator over every tagged field of the struct (or pointer
+// to struct) `v`, allowing external tools to inspect the environment contract
+// of a struct. Fields are yielded in the canonical order used by the
+// generators (see WriteDotEnv): the top-level struct's fields first, then
+// those of each nested struct as a group, each in declaration order:
+//
+//	for fi := range envconf.Fields(Config{}) {
+//		fmt.Println(fi.Key, fi.Type, fi.Required)
+//	}
+//
+// Tags are read as configured by `opts` (see WithTagName). Fields panics if
+// `v` is not a struct or pointer to struct, or if a struct tag is malformed.
+func Fields(v any, opts ...Option) iter.Seq[FieldInfo] {
+	fields, err := newOptions(opts).collectFields(v)
+	if err != nil {
+		panic(err)
+	}
+
+	return func(yield func(FieldInfo) bool) {
+		for _, fi := range fields {
+			if !yield(fi) {
+				return
+			}
+		}
+	}
+}
+
+// collectFields returns every tagged field of the struct (or pointer to
+// struct) `v` in canonical order: fields of the top-level struct first, then
+// the fields of each nested struct grouped together, with groups ordered by
+// first appearance and each preceding the groups of the structs nested within
+// it. Within a group fields retain their declaration order. Nested structs
+// are thus hoisted after the fields that follow them, so that every group
+// appears once, under a single header. The same order is used by every
+// generated output so artifacts diff cleanly.
+func collectFields(v any) ([]FieldInfo, error) {
+	var o options
+	return o.collectFields(v)
+}
+
+// collectFields is like the function of the same name, but parses tags as
+// configured (see WithTagName).
+func (o *options) collectFields(v any) ([]FieldInfo, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, errors.New("expected struct or pointer to struct")
+	}
+	if !rv.CanAddr() {
+		// Copy struct values so that methods with pointer receivers (e.g.
+		// MarshalText) are available to their fields.
+		p := reflect.New(rv.Type()).Elem()
+		p.Set(rv)
+		rv = p
+	}
+
+	var (
+		groups []string
+		byName = make(map[string][]FieldInfo)
+	)
+	add := func(fi FieldInfo) {
+		// Register enclosing groups first, so that a struct's own fields
+		// precede those of the structs nested within it.
+		for i, c := range fi.Group {
+			if c == '.' {
+				if _, ok := byName[fi.Group[:i]]; !ok {
+					groups = append(groups, fi.Group[:i])
+					byName[fi.Group[:i]] = nil
+				}
+			}
+		}
+		if _, ok := byName[fi.Group]; !ok {
+			groups = append(groups, fi.Group)
+		}
+		byName[fi.Group] = append(byName[fi.Group], fi)
+	}
+	if err := o.walkFields(rv.Type(), rv, "", "", add); err != nil {
+		return nil, err
+	}
+
+	fields := byName[""]
+	for _, g := range groups {
+		if g != "" {
+			fields = append(fields, byName[g]...)
+		}
+	}
+
+	return fields, nil
+}
+
+// walkFields calls `fn` for every tagged field of the struct type `t`, in
+// declaration order, recursing into nested structs. `v` holds the value of
+// the struct and may be invalid. `prefix` is prepended to every key (see the
+// `prefix` and `noprefix` attributes).
+func (o *options) walkFields(t reflect.Type, v reflect.Value, group, prefix string, fn func(FieldInfo)) error {
+	for _, field := range reflect.VisibleFields(t) {
+		if !field.IsExported() || len(field.Index) > 1 {
+			continue
+		}
+
+		tag, err := o.parseTag(field.Tag)
+		if err != nil {
+			return err
+		}
+
+		var fV reflect.Value
+		if v.IsValid() {
+			fV = v.FieldByIndex(field.Index)
+		}
+
+		ft := field.Type
+		if ft.Kind() == reflect.Pointer && ft.Elem().Kind() == reflect.Struct &&
+			!o.isLeaf(ft.Elem()) && tag.format == "" {
+			ft = ft.Elem()
+			if fV.IsValid() {
+				if fV.IsNil() {
+					fV = reflect.Value{}
+				} else {
+					fV = fV.Elem()
+				}
+			}
+		}
+		if ft.Kind() == reflect.Struct && !o.isLeaf(ft) && tag.format == "" {
+			err := o.walkFields(ft, fV, joinPath(group, field.Name), tag.childPrefix(prefix), fn)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		if tag.key == "" {
+			continue
+		}
+
+		fn(FieldInfo{
+			Key:      prefix + tag.key,
+			Field:    joinPath(group, field.Name),
+			Group:    group,
+			Type:     field.Type,
+			Required: tag.required,
+			Default:  tag.defaultVal,
+			Secret:   tag.secret,
+			Value:    fV,
+			tag:      tag,
+
+			Description: tag.desc,
+		})
+	}
+
+	return nil
+}
+
+// joinPath joins the dotted field path `parent` and `name`.
+func joinPath(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "." + name
+}
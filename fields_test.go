@@ -0,0 +1,85 @@
+package envconf
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFields(t *testing.T) {
+	type testObj struct {
+		DB struct {
+			Password string `env:"DB_PASSWORD,secret"`
+		}
+		Port  int    `env:"PORT,default=8080,desc=Listen port"`
+		Name  string `env:"NAME,required"`
+		Other string
+	}
+
+	tRun(t, "yields tagged fields in canonical order", func(t *testing.T) {
+		// Arrange
+		in := testObj{Port: 9090}
+
+		// Act
+		var got []FieldInfo
+		for fi := range Fields(&in) {
+			got = append(got, fi)
+		}
+
+		// Assert
+		assertEqual(t, len(got), 3)
+		assertEqual(t, got[0].Key, "PORT")
+		assertEqual(t, got[0].Type, reflect.TypeOf(0))
+		assertEqual(t, got[0].Default, "8080")
+		assertEqual(t, got[0].Description, "Listen port")
+		assertEqual(t, got[0].Value.Int(), int64(9090))
+		assertEqual(t, got[1].Key, "NAME")
+		assertEqual(t, got[1].Required, true)
+		assertEqual(t, got[2].Field, "DB.Password")
+		assertEqual(t, got[2].Group, "DB")
+		assertEqual(t, got[2].Secret, true)
+	})
+
+	tRun(t, "hoists nested structs after the fields of their parents", func(t *testing.T) {
+		// Arrange
+		type testObj struct {
+			DB struct {
+				TLS struct {
+					Cert string `env:"DB_TLS_CERT"`
+				}
+				Host string `env:"DB_HOST"`
+			}
+			Port int `env:"PORT"`
+		}
+
+		// Act
+		var keys []string
+		for fi := range Fields(testObj{}) {
+			keys = append(keys, fi.Key)
+		}
+
+		// Assert
+		assertEqual(t, strings.Join(keys, ","), "PORT,DB_HOST,DB_TLS_CERT")
+	})
+
+	tRun(t, "stops when the loop breaks", func(t *testing.T) {
+		// Act
+		n := 0
+		for range Fields(testObj{}) {
+			n++
+			break
+		}
+
+		// Assert
+		assertEqual(t, n, 1)
+	})
+
+	tRun(t, "panics for non-structs", func(t *testing.T) {
+		// Assert
+		defer assertPanicWithSubStr(t, "expected struct or pointer to struct")
+
+		// Act
+		for range Fields(42) {
+		}
+	})
+}
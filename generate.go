@@ -0,0 +1,489 @@
+package envconf
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// The generators in this file describe the environment contract of a struct.
+// Every generator emits fields in the canonical order produced by
+// collectFields, preceded by a header for each nested struct group, and uses
+// deterministic formatting so that generated artifacts diff cleanly under
+// version control. Indexed slices are expanded into the variables of their
+// elements, KEY_0, KEY_1 and so on, preceded by their count variable if they
+// have one (see the `count` attribute); a slice without elements is
+// represented by the placeholder KEY_0, and the elements of slices of structs
+// by their fields, KEY_0_FIELD and so on. Prefix maps are likewise expanded
+// into a variable for each entry, ordered by name, or the placeholder KEYNAME
+// if they have none (e.g. FEATURE_NAME for the prefix FEATURE_). Options
+// passed to a generator control how struct tags are read and which structs
+// are recursed into (see WithTagName and WithStopTypes); others have no
+// effect.
+
+// WriteDotEnv writes a .env file for the struct (or pointer to struct) `v` to
+// `w`. Each variable is assigned the field's current value or, where the field
+// holds its zero value or reads its value from a file (see the `file`
+// attribute), its default. The values of secret fields are redacted (see
+// Redacted). Values are double-quoted when they contain characters that are
+// not safe unquoted, and variables with a description (see the `desc`
+// attribute) are preceded by it as a comment.
+func WriteDotEnv(w io.Writer, v any, opts ...Option) error {
+	fields, err := newOptions(opts).generatedFields(v)
+	if err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+	if version, ok := schemaVersionOf(v); ok {
+		fmt.Fprintf(bw, "%s=%s\n\n", SchemaVersionKey, quoteDotEnv(version))
+	}
+	forEachGroup(fields, func(group string, first bool) {
+		if !first {
+			bw.WriteString("\n")
+		}
+		if group != "" {
+			fmt.Fprintf(bw, "# %s\n", group)
+		}
+	}, func(fi FieldInfo) {
+		if fi.Description != "" {
+			fmt.Fprintf(bw, "# %s\n", fi.Description)
+		}
+		fmt.Fprintf(bw, "%s=%s\n", fi.Key, quoteDotEnv(templateValue(fi)))
+	})
+
+	return bw.Flush()
+}
+
+// WriteManifest writes a Kubernetes container `env` manifest block for the
+// struct (or pointer to struct) `v` to `w`. Values are chosen as for
+// WriteDotEnv.
+func WriteManifest(w io.Writer, v any, opts ...Option) error {
+	fields, err := newOptions(opts).generatedFields(v)
+	if err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+	bw.WriteString("env:\n")
+	if version, ok := schemaVersionOf(v); ok {
+		fmt.Fprintf(bw, "  - name: %s\n    value: %s\n",
+			SchemaVersionKey, strconv.Quote(version))
+	}
+	forEachGroup(fields, func(group string, _ bool) {
+		if group != "" {
+			fmt.Fprintf(bw, "  # %s\n", group)
+		}
+	}, func(fi FieldInfo) {
+		fmt.Fprintf(bw, "  - name: %s\n    value: %s\n",
+			fi.Key, strconv.Quote(templateValue(fi)))
+	})
+
+	return bw.Flush()
+}
+
+// Usage writes a table describing every variable of the struct (or pointer
+// to struct) `v` to `w`, suitable for a command's help output. The table has
+// a DESCRIPTION column if any variable has a description.
+func Usage(w io.Writer, v any, opts ...Option) error {
+	fields, err := newOptions(opts).generatedFields(v)
+	if err != nil {
+		return err
+	}
+
+	desc := hasDescriptions(fields)
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	forEachGroup(fields, func(group string, first bool) {
+		if !first {
+			tw.Write([]byte("\n"))
+		}
+		if group != "" {
+			fmt.Fprintf(tw, "%s:\n", group)
+		}
+		if desc {
+			fmt.Fprintf(tw, "KEY\tTYPE\tDEFAULT\tREQUIRED\tDESCRIPTION\n")
+		} else {
+			fmt.Fprintf(tw, "KEY\tTYPE\tDEFAULT\tREQUIRED\n")
+		}
+	}, func(fi FieldInfo) {
+		if desc {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%t\t%s\n",
+				fi.Key, fi.Type, fi.Default, fi.Required, fi.Description)
+		} else {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%t\n",
+				fi.Key, fi.Type, fi.Default, fi.Required)
+		}
+	})
+
+	return tw.Flush()
+}
+
+// WriteMarkdown writes Markdown documentation of every variable of the struct
+// (or pointer to struct) `v` to `w`, one table per group. The tables have a
+// Description column if any variable has a description.
+func WriteMarkdown(w io.Writer, v any, opts ...Option) error {
+	fields, err := newOptions(opts).generatedFields(v)
+	if err != nil {
+		return err
+	}
+
+	desc := hasDescriptions(fields)
+	bw := bufio.NewWriter(w)
+	forEachGroup(fields, func(group string, first bool) {
+		if !first {
+			bw.WriteString("\n")
+		}
+		if group != "" {
+			fmt.Fprintf(bw, "### %s\n\n", group)
+		}
+		if desc {
+			bw.WriteString("| Variable | Type | Default | Required | Description |\n")
+			bw.WriteString("|----------|------|---------|----------|-------------|\n")
+		} else {
+			bw.WriteString("| Variable | Type | Default | Required |\n")
+			bw.WriteString("|----------|------|---------|----------|\n")
+		}
+	}, func(fi FieldInfo) {
+		def := ""
+		if fi.Default != "" {
+			def = "`" + fi.Default + "`"
+		}
+		req := "no"
+		if fi.Required {
+			req = "yes"
+		}
+		if desc {
+			fmt.Fprintf(bw, "| `%s` | `%s` | %s | %s | %s |\n", fi.Key, fi.Type, def, req,
+				strings.ReplaceAll(fi.Description, "|", `\|`))
+		} else {
+			fmt.Fprintf(bw, "| `%s` | `%s` | %s | %s |\n", fi.Key, fi.Type, def, req)
+		}
+	})
+
+	return bw.Flush()
+}
+
+// WriteTerraformVariables writes a Terraform `variable` block for every
+// variable of the struct (or pointer to struct) `v` to `w`, so infrastructure
+// modules that inject the environment stay aligned with the application.
+// Variables are named after the lower-cased key (e.g. DB_DSN becomes db_dsn).
+// Plain bool and numeric fields are typed accordingly and every other field
+// is a string. Required fields have no default; other fields default to their
+// declared default or, lacking one, to null. Secret fields are marked
+// sensitive. Variables are described by their description (see the `desc`
+// attribute) or, lacking one, by their key and field.
+func WriteTerraformVariables(w io.Writer, v any, opts ...Option) error {
+	fields, err := newOptions(opts).generatedFields(v)
+	if err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+	if version, ok := schemaVersionOf(v); ok {
+		fmt.Fprintf(bw, "variable %q {\n  type    = string\n  default = %s\n}\n\n",
+			tfName(SchemaVersionKey), hclString(version))
+	}
+	forEachGroup(fields, func(group string, first bool) {
+		if !first {
+			bw.WriteString("\n")
+		}
+		if group != "" {
+			fmt.Fprintf(bw, "# %s\n\n", group)
+		}
+	}, func(fi FieldInfo) {
+		typ := tfType(fi)
+		fmt.Fprintf(bw, "variable %q {\n", tfName(fi.Key))
+		desc := fi.Description
+		if desc == "" {
+			desc = fi.Key + " (" + fi.Field + ")"
+		}
+		fmt.Fprintf(bw, "  description = %s\n", hclString(desc))
+		fmt.Fprintf(bw, "  type        = %s\n", typ)
+		switch {
+		case fi.Default != "":
+			fmt.Fprintf(bw, "  default     = %s\n", hclValue(fi.Default, typ))
+		case !fi.Required:
+			bw.WriteString("  default     = null\n")
+		}
+		if fi.Secret {
+			bw.WriteString("  sensitive   = true\n")
+		}
+		bw.WriteString("}\n")
+	})
+
+	return bw.Flush()
+}
+
+// WriteTFVars writes a Terraform .tfvars template for the struct (or pointer
+// to struct) `v` to `w`, assigning each variable declared by
+// WriteTerraformVariables a value chosen as for WriteDotEnv. Variables without
+// a value are assigned null.
+func WriteTFVars(w io.Writer, v any, opts ...Option) error {
+	fields, err := newOptions(opts).generatedFields(v)
+	if err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+	if version, ok := schemaVersionOf(v); ok {
+		fmt.Fprintf(bw, "%s = %s\n\n", tfName(SchemaVersionKey), hclString(version))
+	}
+	forEachGroup(fields, func(group string, first bool) {
+		if !first {
+			bw.WriteString("\n")
+		}
+		if group != "" {
+			fmt.Fprintf(bw, "# %s\n", group)
+		}
+	}, func(fi FieldInfo) {
+		val := "null"
+		if tv := templateValue(fi); tv != "" {
+			val = hclValue(tv, tfType(fi))
+		}
+		fmt.Fprintf(bw, "%s = %s\n", tfName(fi.Key), val)
+	})
+
+	return bw.Flush()
+}
+
+// tfName returns the Terraform variable name for the variable `key`.
+func tfName(key string) string {
+	return strings.ToLower(key)
+}
+
+// tfType returns the Terraform type of the variable described by `fi`: bool
+// or number for fields of the predeclared boolean and numeric types, and
+// string otherwise, including for values in notations Terraform does not
+// share (e.g. hex integers or percentages).
+func tfType(fi FieldInfo) string {
+	if fi.Type.PkgPath() != "" || fi.tag.byteSize || fi.tag.intBase() != 10 ||
+		fi.tag.percent || fi.tag.lenientBool {
+		return "string"
+	}
+	switch fi.Type.Kind() {
+	case reflect.Bool:
+		return "bool"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	}
+
+	return "string"
+}
+
+// hclValue formats `val` as an HCL literal of the Terraform type `typ`,
+// falling back to a string if it is not a valid literal of that type.
+func hclValue(val, typ string) string {
+	switch typ {
+	case "bool":
+		if b, err := strconv.ParseBool(val); err == nil {
+			return strconv.FormatBool(b)
+		}
+	case "number":
+		if _, err := strconv.ParseFloat(val, 64); err == nil {
+			return val
+		}
+	}
+
+	return hclString(val)
+}
+
+// hclString formats `val` as a quoted HCL string, escaping template
+// sequences.
+func hclString(val string) string {
+	q := strconv.Quote(val)
+	q = strings.ReplaceAll(q, "${", "$${")
+	return strings.ReplaceAll(q, "%{", "%%{")
+}
+
+// hasDescriptions reports whether any of `fields` has a description.
+func hasDescriptions(fields []FieldInfo) bool {
+	for _, fi := range fields {
+		if fi.Description != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// forEachGroup iterates `fields` (which must be in canonical order), calling
+// `header` at the start of every group and `field` for every field. `first`
+// reports whether the group is the first to be emitted.
+func forEachGroup(fields []FieldInfo, header func(group string, first bool), field func(FieldInfo)) {
+	for i, fi := range fields {
+		if i == 0 || fi.Group != fields[i-1].Group {
+			header(fi.Group, i == 0)
+		}
+		field(fi)
+	}
+}
+
+// generatedFields returns the variables of the struct (or pointer to struct)
+// `v` described by the generators: its fields in canonical order (see
+// collectFields), with indexed slices and prefix maps expanded into the
+// variables of their elements.
+func (o *options) generatedFields(v any) ([]FieldInfo, error) {
+	fields, err := o.collectFields(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var vars []FieldInfo
+	for _, fi := range fields {
+		if fi.tag.indexed && fi.Type.Kind() == reflect.Slice {
+			if vars, err = o.expandIndexed(vars, fi); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if fi.tag.prefixMap && fi.Type.Kind() == reflect.Map {
+			if vars, err = expandPrefixMap(vars, fi); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		vars = append(vars, fi)
+	}
+
+	return vars, nil
+}
+
+// expandIndexed appends to `vars` the count variable of the indexed slice
+// `fi`, if it has one, followed by the variables of each of its elements, or
+// those of the placeholder element 0 if it has none. The variables of an
+// element of a slice of structs are those of the struct, prefixed by KEY_i_.
+// Only the first variable carries the field's description, and the elements
+// of other slices have no default, since that of the slice is a delimited
+// list.
+func (o *options) expandIndexed(vars []FieldInfo, fi FieldInfo) ([]FieldInfo, error) {
+	n := 0
+	if fi.Value.IsValid() {
+		n = fi.Value.Len()
+	}
+	desc := fi.Description
+	if fi.tag.count != "" {
+		vars = append(vars, FieldInfo{
+			Key:      countKey(fi),
+			Field:    fi.Field,
+			Group:    fi.Group,
+			Type:     reflect.TypeOf(0),
+			Required: fi.Required,
+			Value:    reflect.ValueOf(n),
+
+			Description: desc,
+		})
+		desc = ""
+	}
+
+	et := fi.Type.Elem()
+	if et.Kind() == reflect.Struct && !o.isLeaf(et) && fi.tag.format == "" {
+		for i := range max(n, 1) {
+			ev := reflect.New(et)
+			if i < n {
+				ev = fi.Value.Index(i).Addr()
+			}
+			sub, err := o.generatedFields(ev.Interface())
+			if err != nil {
+				return nil, err
+			}
+			for _, e := range sub {
+				e.Key = indexedKey(fi.Key, i) + "_" + e.Key
+				e.Field = fmt.Sprintf("%s[%d].%s", fi.Field, i, e.Field)
+				e.Group = fi.Group
+				if desc != "" && e.Description == "" {
+					e.Description = desc
+				}
+				vars = append(vars, e)
+				desc = ""
+			}
+		}
+		return vars, nil
+	}
+
+	for i := range max(n, 1) {
+		e := fi
+		e.Key = indexedKey(fi.Key, i)
+		e.Field = fmt.Sprintf("%s[%d]", fi.Field, i)
+		e.Type = et
+		e.Required = fi.Required && (i == 0 || fi.tag.count != "")
+		e.Default = ""
+		e.Description = desc
+		e.Value = reflect.Value{}
+		if i < n {
+			e.Value, e.elem = fi.Value.Index(i), true
+		}
+		vars = append(vars, e)
+		desc = ""
+	}
+
+	return vars, nil
+}
+
+// expandPrefixMap appends to `vars` a variable for each entry of the prefix
+// map `fi`, ordered by name, or the placeholder KEYNAME if it has none. Only
+// the first variable carries the field's description, and the entries have no
+// default, since that of the map is a delimited list.
+func expandPrefixMap(vars []FieldInfo, fi FieldInfo) ([]FieldInfo, error) {
+	e := fi
+	e.Type = fi.Type.Elem()
+	e.Default = ""
+	if !fi.Value.IsValid() || fi.Value.Len() == 0 {
+		e.Key = fi.Key + "NAME"
+		e.Field = fi.Field + "[NAME]"
+		e.Value = reflect.Value{}
+		return append(vars, e), nil
+	}
+
+	var entries []FieldInfo
+	iter := fi.Value.MapRange()
+	for iter.Next() {
+		suffix, ok := formatValue(iter.Key(), fi.tag)
+		if !ok {
+			return nil, fmt.Errorf("cannot format key of field %q of type %s",
+				fi.Field, fi.Type)
+		}
+		e.Key = fi.Key + suffix
+		e.Field = fmt.Sprintf("%s[%s]", fi.Field, suffix)
+		e.Value, e.elem = iter.Value(), true
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Key < entries[j].Key })
+	for i := range entries[1:] {
+		entries[i+1].Description = ""
+	}
+
+	return append(vars, entries...), nil
+}
+
+// templateValue returns the value to emit for `fi` in generated templates:
+// its current value (redacted if secret), or its default if the value is
+// zero, unavailable or read from a file.
+func templateValue(fi FieldInfo) string {
+	if fi.Value.IsValid() && (fi.elem || !fi.Value.IsZero()) && !fi.tag.file {
+		if val, ok := formatValue(fi.Value, fi.tag); ok {
+			return fi.tag.redact(val)
+		}
+	}
+
+	return fi.Default
+}
+
+// quoteDotEnv double-quotes `val` if it contains characters that are not safe
+// to leave unquoted in a .env file.
+func quoteDotEnv(val string) string {
+	safe := strings.IndexFunc(val, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' ||
+			r >= '0' && r <= '9' || strings.ContainsRune("_-.,:/@+=", r))
+	}) == -1
+	if safe {
+		return val
+	}
+
+	return strconv.Quote(val)
+}
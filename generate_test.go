@@ -0,0 +1,400 @@
+package envconf
+
+import (
+	"strings"
+	"testing"
+)
+
+type generateTestObj struct {
+	Name   string `env:"NAME,required"`
+	Server struct {
+		Host string `env:"HOST,default=localhost"`
+		Port int    `env:"PORT,default=8080"`
+	}
+	Debug bool `env:"DEBUG"`
+	DB    *struct {
+		DSN string `env:"DB_DSN"`
+	}
+	Untagged string
+}
+
+type describedTestObj struct {
+	Port int    `env:"PORT,default=8080,desc=Listen port for the HTTP server"`
+	Host string `env:"HOST"`
+}
+
+func newGenerateTestObj() generateTestObj {
+	var in generateTestObj
+	in.Name = "my app"
+	in.Server.Port = 9090
+	return in
+}
+
+func TestWriteDotEnv(t *testing.T) {
+	tRun(t, "canonical order with group headers", func(t *testing.T) {
+		// Arrange
+		var sb strings.Builder
+
+		// Act
+		err := WriteDotEnv(&sb, newGenerateTestObj())
+
+		// Assert
+		assertEqual(t, err, nil)
+		assertEqual(t, sb.String(), `NAME="my app"
+DEBUG=
+
+# Server
+HOST=localhost
+PORT=9090
+
+# DB
+DB_DSN=
+`)
+	})
+
+	tRun(t, "descriptions are comments", func(t *testing.T) {
+		// Arrange
+		var sb strings.Builder
+
+		// Act
+		err := WriteDotEnv(&sb, describedTestObj{})
+
+		// Assert
+		assertEqual(t, err, nil)
+		assertEqual(t, sb.String(), `# Listen port for the HTTP server
+PORT=8080
+HOST=
+`)
+	})
+
+	tRun(t, "indexed slices are expanded", func(t *testing.T) {
+		// Arrange
+		var sb strings.Builder
+		type testObj struct {
+			Hosts []string `env:"HOST,indexed,desc=Backend hosts"`
+			Ports []int    `env:"PORT,indexed,count=PORTS"`
+		}
+		in := testObj{Hosts: []string{"a", "b"}, Ports: []int{0}}
+
+		// Act
+		err := WriteDotEnv(&sb, in)
+
+		// Assert
+		assertEqual(t, err, nil)
+		assertEqual(t, sb.String(), `# Backend hosts
+HOST_0=a
+HOST_1=b
+PORTS=1
+PORT_0=0
+`)
+	})
+
+	tRun(t, "indexed slices of structs are expanded", func(t *testing.T) {
+		// Arrange
+		var sb strings.Builder
+		type upstream struct {
+			Host string `env:"HOST"`
+			Port int    `env:"PORT,default=80"`
+		}
+		type testObj struct {
+			Upstreams []upstream `env:"UPSTREAM,indexed,count=UPSTREAMS"`
+			Backups   []upstream `env:"BACKUP,indexed"`
+		}
+		in := testObj{Upstreams: []upstream{{Host: "a", Port: 8080}, {Host: "b"}}}
+
+		// Act
+		err := WriteDotEnv(&sb, in)
+
+		// Assert
+		assertEqual(t, err, nil)
+		assertEqual(t, sb.String(), `UPSTREAMS=2
+UPSTREAM_0_HOST=a
+UPSTREAM_0_PORT=8080
+UPSTREAM_1_HOST=b
+UPSTREAM_1_PORT=80
+BACKUP_0_HOST=
+BACKUP_0_PORT=80
+`)
+	})
+
+	tRun(t, "prefix maps are expanded", func(t *testing.T) {
+		// Arrange
+		var sb strings.Builder
+		type testObj struct {
+			Features map[string]string `env:"FEATURE_,prefixmap,desc=Feature flags"`
+			Limits   map[string]int    `env:"LIMIT_,prefixmap"`
+		}
+		in := testObj{Features: map[string]string{"SEARCH": "on", "BETA_UI": "off"}}
+
+		// Act
+		err := WriteDotEnv(&sb, in)
+
+		// Assert
+		assertEqual(t, err, nil)
+		assertEqual(t, sb.String(), `# Feature flags
+FEATURE_BETA_UI=off
+FEATURE_SEARCH=on
+LIMIT_NAME=
+`)
+	})
+}
+
+func TestWriteManifest(t *testing.T) {
+	tRun(t, "canonical order with group headers", func(t *testing.T) {
+		// Arrange
+		var sb strings.Builder
+
+		// Act
+		err := WriteManifest(&sb, newGenerateTestObj())
+
+		// Assert
+		assertEqual(t, err, nil)
+		assertEqual(t, sb.String(), `env:
+  - name: NAME
+    value: "my app"
+  - name: DEBUG
+    value: ""
+  # Server
+  - name: HOST
+    value: "localhost"
+  - name: PORT
+    value: "9090"
+  # DB
+  - name: DB_DSN
+    value: ""
+`)
+	})
+}
+
+func TestUsage(t *testing.T) {
+	tRun(t, "canonical order with group headers", func(t *testing.T) {
+		// Arrange
+		var sb strings.Builder
+
+		// Act
+		err := Usage(&sb, &generateTestObj{})
+
+		// Assert
+		assertEqual(t, err, nil)
+		assertEqual(t, sb.String(), `KEY    TYPE    DEFAULT  REQUIRED
+NAME   string           true
+DEBUG  bool             false
+
+Server:
+KEY   TYPE    DEFAULT    REQUIRED
+HOST  string  localhost  false
+PORT  int     8080       false
+
+DB:
+KEY     TYPE    DEFAULT  REQUIRED
+DB_DSN  string           false
+`)
+	})
+
+	tRun(t, "descriptions add a column", func(t *testing.T) {
+		// Arrange
+		var sb strings.Builder
+
+		// Act
+		err := Usage(&sb, describedTestObj{})
+
+		// Assert
+		assertEqual(t, err, nil)
+		assertEqual(t, sb.String(), `KEY   TYPE    DEFAULT  REQUIRED  DESCRIPTION
+PORT  int     8080     false     Listen port for the HTTP server
+HOST  string           false     
+`)
+	})
+
+	tRun(t, "indexed slices without elements are placeholders", func(t *testing.T) {
+		// Arrange
+		var sb strings.Builder
+		type testObj struct {
+			Hosts []string `env:"HOST,indexed,required,default=a;b"`
+			Ports []int    `env:"PORT,indexed,count=PORTS"`
+		}
+
+		// Act
+		err := Usage(&sb, testObj{})
+
+		// Assert
+		assertEqual(t, err, nil)
+		assertEqual(t, sb.String(), `KEY     TYPE    DEFAULT  REQUIRED
+HOST_0  string           true
+PORTS   int              false
+PORT_0  int              false
+`)
+	})
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	tRun(t, "canonical order with group headers", func(t *testing.T) {
+		// Arrange
+		var sb strings.Builder
+
+		// Act
+		err := WriteMarkdown(&sb, generateTestObj{})
+
+		// Assert
+		assertEqual(t, err, nil)
+		assertEqual(t, sb.String(), "| Variable | Type | Default | Required |\n"+
+			"|----------|------|---------|----------|\n"+
+			"| `NAME` | `string` |  | yes |\n"+
+			"| `DEBUG` | `bool` |  | no |\n"+
+			"\n"+
+			"### Server\n"+
+			"\n"+
+			"| Variable | Type | Default | Required |\n"+
+			"|----------|------|---------|----------|\n"+
+			"| `HOST` | `string` | `localhost` | no |\n"+
+			"| `PORT` | `int` | `8080` | no |\n"+
+			"\n"+
+			"### DB\n"+
+			"\n"+
+			"| Variable | Type | Default | Required |\n"+
+			"|----------|------|---------|----------|\n"+
+			"| `DB_DSN` | `string` |  | no |\n")
+	})
+
+	tRun(t, "descriptions add a column", func(t *testing.T) {
+		// Arrange
+		var sb strings.Builder
+
+		// Act
+		err := WriteMarkdown(&sb, describedTestObj{})
+
+		// Assert
+		assertEqual(t, err, nil)
+		assertEqual(t, sb.String(), "| Variable | Type | Default | Required | Description |\n"+
+			"|----------|------|---------|----------|-------------|\n"+
+			"| `PORT` | `int` | `8080` | no | Listen port for the HTTP server |\n"+
+			"| `HOST` | `string` |  | no |  |\n")
+	})
+}
+
+func TestWriteTerraformVariables(t *testing.T) {
+	tRun(t, "canonical order with group headers", func(t *testing.T) {
+		// Arrange
+		var sb strings.Builder
+
+		// Act
+		err := WriteTerraformVariables(&sb, generateTestObj{})
+
+		// Assert
+		assertEqual(t, err, nil)
+		assertEqual(t, sb.String(), `variable "name" {
+  description = "NAME (Name)"
+  type        = string
+}
+variable "debug" {
+  description = "DEBUG (Debug)"
+  type        = bool
+  default     = null
+}
+
+# Server
+
+variable "host" {
+  description = "HOST (Server.Host)"
+  type        = string
+  default     = "localhost"
+}
+variable "port" {
+  description = "PORT (Server.Port)"
+  type        = number
+  default     = 8080
+}
+
+# DB
+
+variable "db_dsn" {
+  description = "DB_DSN (DB.DSN)"
+  type        = string
+  default     = null
+}
+`)
+	})
+
+	tRun(t, "descriptions replace the default description", func(t *testing.T) {
+		// Arrange
+		var sb strings.Builder
+
+		// Act
+		err := WriteTerraformVariables(&sb, describedTestObj{})
+
+		// Assert
+		assertEqual(t, err, nil)
+		assertEqual(t, strings.Contains(sb.String(),
+			`  description = "Listen port for the HTTP server"`), true)
+		assertEqual(t, strings.Contains(sb.String(), `  description = "HOST (Host)"`), true)
+	})
+
+	tRun(t, "secrets are sensitive", func(t *testing.T) {
+		// Arrange
+		var sb strings.Builder
+		in := struct {
+			Token string `env:"TOKEN,secret"`
+		}{}
+
+		// Act
+		err := WriteTerraformVariables(&sb, in)
+
+		// Assert
+		assertEqual(t, err, nil)
+		assertEqual(t, strings.Contains(sb.String(), "  sensitive   = true\n"), true)
+	})
+
+	tRun(t, "numbers not written in decimal are strings", func(t *testing.T) {
+		// Arrange
+		var sb strings.Builder
+		in := struct {
+			Mask uint32 `env:"MASK,base=16,default=ff00"`
+		}{}
+
+		// Act
+		err := WriteTerraformVariables(&sb, in)
+
+		// Assert
+		assertEqual(t, err, nil)
+		assertEqual(t, strings.Contains(sb.String(), "  type        = string\n"), true)
+		assertEqual(t, strings.Contains(sb.String(), `  default     = "ff00"`), true)
+	})
+}
+
+func TestWriteTFVars(t *testing.T) {
+	tRun(t, "canonical order with group headers", func(t *testing.T) {
+		// Arrange
+		var sb strings.Builder
+
+		// Act
+		err := WriteTFVars(&sb, newGenerateTestObj())
+
+		// Assert
+		assertEqual(t, err, nil)
+		assertEqual(t, sb.String(), `name = "my app"
+debug = null
+
+# Server
+host = "localhost"
+port = 9090
+
+# DB
+db_dsn = null
+`)
+	})
+
+	tRun(t, "template sequences are escaped", func(t *testing.T) {
+		// Arrange
+		var sb strings.Builder
+		in := struct {
+			Greeting string `env:"GREETING"`
+		}{Greeting: "hi ${name}"}
+
+		// Act
+		err := WriteTFVars(&sb, in)
+
+		// Assert
+		assertEqual(t, err, nil)
+		assertEqual(t, sb.String(), "greeting = \"hi $${name}\"\n")
+	})
+}
@@ -1,3 +1,3 @@
 module github.com/rmerry/envconf
 
-go 1.22.7
+go 1.23
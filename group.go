@@ -0,0 +1,60 @@
+package envconf
+
+// fieldGroups tracks the groups of fields (see the `group` attribute) seen
+// while processing a struct.
+type fieldGroups struct {
+	byName map[string]*fieldGroup
+	order  []string // Group names in order of first appearance.
+}
+
+// fieldGroup describes a group of fields.
+type fieldGroup struct {
+	vars      []string // The variables of the group's fields.
+	required  bool     // Whether at least one must be set.
+	satisfied bool     // Whether at least one is set.
+}
+
+// join records that the field whose variable is `key` belongs to the group
+// named by its tag, if any.
+func (g *fieldGroups) join(key string, tag fieldTag) {
+	if g == nil || tag.group == "" {
+		return
+	}
+	fg, ok := g.byName[tag.group]
+	if !ok {
+		if g.byName == nil {
+			g.byName = make(map[string]*fieldGroup)
+		}
+		fg = &fieldGroup{}
+		g.byName[tag.group] = fg
+		g.order = append(g.order, tag.group)
+	}
+	fg.vars = append(fg.vars, key)
+	fg.required = fg.required || tag.requiredGroup
+}
+
+// satisfy records that a field of the group named by `tag`, if any, has a
+// value.
+func (g *fieldGroups) satisfy(tag fieldTag) {
+	if g == nil || tag.group == "" {
+		return
+	}
+	g.byName[tag.group].satisfied = true
+}
+
+// check returns a MissingGroupError for every required group none of whose
+// fields has a value.
+func (g *fieldGroups) check() []error {
+	if g == nil {
+		return nil
+	}
+
+	var errs []error
+	for _, name := range g.order {
+		fg := g.byName[name]
+		if fg.required && !fg.satisfied {
+			errs = append(errs, &MissingGroupError{Group: name, Vars: fg.vars})
+		}
+	}
+	return errs
+}
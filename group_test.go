@@ -0,0 +1,120 @@
+package envconf
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestProcess_RequiredGroup(t *testing.T) {
+	type testObj struct {
+		APIKey     string `env:"API_KEY,group=auth,required_group"`
+		OAuthToken string `env:"OAUTH_TOKEN,group=auth"`
+		TLS        struct {
+			Cert string `env:"CERT,group=auth"`
+		} `env:",prefix=MTLS_"`
+		Region string `env:"REGION,group=location"`
+		Zone   string `env:"ZONE,group=location,default=a"`
+	}
+
+	tRun(t, "any one variable satisfies the group", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["MTLS_CERT"] = "cert.pem"
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv())
+
+		// Assert
+		assertEqual(t, err, nil)
+		assertEqual(t, in.TLS.Cert, "cert.pem")
+	})
+
+	tRun(t, "unsatisfied groups name every variable", func(t *testing.T) {
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv())
+
+		// Assert
+		var ge *MissingGroupError
+		assertEqual(t, errors.As(err, &ge), true)
+		assertEqual(t, ge.Group, "auth")
+		assertEqual(t, slices.Equal(ge.Vars, []string{"API_KEY", "OAUTH_TOKEN", "MTLS_CERT"}), true)
+		assertEqual(t, errors.Is(err, ErrMissing), true)
+		assertErrorWithSubStr(t, err,
+			`none of env vars "API_KEY", "OAUTH_TOKEN", "MTLS_CERT" set (group "auth" requires at least one)`)
+	})
+
+	tRun(t, "groups are only enforced if required", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["API_KEY"] = "key"
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv())
+
+		// Assert
+		assertEqual(t, err, nil)
+		assertEqual(t, in.Zone, "a")
+	})
+
+	tRun(t, "defaults do not satisfy the group", func(t *testing.T) {
+		// Arrange
+		type testObj struct {
+			Primary   string `env:"PRIMARY,group=db,required_group"`
+			Secondary string `env:"SECONDARY,group=db,default=localhost"`
+		}
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv())
+
+		// Assert
+		var ge *MissingGroupError
+		assertEqual(t, errors.As(err, &ge), true)
+		assertEqual(t, ErrorCodes(err)[0], CodeMissingRequired)
+	})
+	tRun(t, "values already held satisfy the group", func(t *testing.T) {
+		for _, policy := range []OverwritePolicy{OverwriteNever, OverwriteIfSet} {
+			// Act
+			in := testObj{OAuthToken: "token"}
+			err := ProcessE(&in, mockEnv(), WithOverwrite(policy))
+
+			// Assert
+			assertEqual(t, err, nil)
+			assertEqual(t, in.OAuthToken, "token")
+		}
+	})
+
+	tRun(t, "cancelled processing reports no groups", func(t *testing.T) {
+		// Arrange
+		ctx, cancel := context.WithCancel(context.Background())
+		l := ctxLookuperFunc(func(ctx context.Context, key string) (string, bool, error) {
+			cancel()
+			return "", false, ctx.Err()
+		})
+
+		// Act
+		var in testObj
+		err := ProcessContext(ctx, &in, WithLookuper(l))
+
+		// Assert
+		assertEqual(t, errors.Is(err, context.Canceled), true)
+		assertEqual(t, errors.Is(err, ErrMissing), false)
+	})
+
+	tRun(t, "required_group requires a group", func(t *testing.T) {
+		// Arrange
+		type testObj struct {
+			APIKey string `env:"API_KEY,required_group"`
+		}
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv())
+
+		// Assert
+		assertErrorWithSubStr(t, err, "required_group struct tag attribute requires a group attribute")
+	})
+}
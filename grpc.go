@@ -0,0 +1,187 @@
+package envconf
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Invoker performs a unary RPC, unmarshalling the response into `resp`. It is
+// the subset of a gRPC client connection used by GRPCLookuper; a
+// *grpc.ClientConn is adapted with InvokerFunc:
+//
+//	envconf.InvokerFunc(func(ctx context.Context, method string, req, resp any) error {
+//		return conn.Invoke(ctx, method, req, resp)
+//	})
+type Invoker interface {
+	Invoke(ctx context.Context, method string, req, resp any) error
+}
+
+// InvokerFunc is an adapter allowing an ordinary function to be used as an
+// Invoker.
+type InvokerFunc func(ctx context.Context, method string, req, resp any) error
+
+// Invoke calls f(ctx, method, req, resp).
+func (f InvokerFunc) Invoke(ctx context.Context, method string, req, resp any) error {
+	return f(ctx, method, req, resp)
+}
+
+// GRPCLookuper is a Lookuper backed by a centralised configuration service
+// reached over gRPC (or any other RPC system with an Invoker). The service is
+// called once, on the first lookup, and its response is mapped to variables:
+//
+//   - Each scalar field of the response message becomes a variable named by
+//     Fields or, if it is not listed there, by the field's protobuf name in
+//     upper case (e.g. db_host becomes DB_HOST).
+//   - Each entry of a map field (e.g. map<string, string> values) becomes a
+//     variable named by the entry's key.
+//
+// Unset optional fields and nested messages are ignored. For example:
+//
+//	l := &envconf.GRPCLookuper{
+//		Conn: envconf.InvokerFunc(func(ctx context.Context, method string, req, resp any) error {
+//			return conn.Invoke(ctx, method, req, resp)
+//		}),
+//		Method:      "/config.v1.ConfigService/GetConfig",
+//		Request:     &configpb.GetConfigRequest{Service: "billing"},
+//		NewResponse: func() any { return new(configpb.GetConfigResponse) },
+//	}
+//
+// A failed call is reported by Process, and retried by the next lookup. The
+// response is cached for the lifetime of the GRPCLookuper, so one should be
+// created per Process call (or per reload) to observe changes.
+type GRPCLookuper struct {
+	Conn   Invoker
+	Method string // Full method name, e.g. "/pkg.Service/Method".
+
+	// Request is the request message sent to the service.
+	Request any
+
+	// NewResponse returns an empty response message, a pointer to a struct.
+	NewResponse func() any
+
+	// Fields maps the protobuf names of response fields to variable names,
+	// overriding the upper-cased default.
+	Fields map[string]string
+
+	mu   sync.Mutex
+	vars map[string]string
+}
+
+// Lookup retrieves the value of `key` from the service's response.
+func (g *GRPCLookuper) Lookup(key string) (string, bool) {
+	val, ok, _ := g.LookupContext(context.Background(), key)
+	return val, ok
+}
+
+// LookupContext is like Lookup but abandons the call, returning ctx.Err(), if
+// `ctx` is done first.
+func (g *GRPCLookuper) LookupContext(ctx context.Context, key string) (string, bool, error) {
+	vars, err := g.fetch(ctx)
+	if err != nil {
+		return "", false, err
+	}
+
+	val, ok := vars[key]
+	return val, ok, nil
+}
+
+// Keys returns the names of every variable in the service's response, or nil
+// if the service cannot be reached.
+func (g *GRPCLookuper) Keys() []string {
+	vars, err := g.fetch(context.Background())
+	if err != nil {
+		return nil
+	}
+
+	keys := make([]string, 0, len(vars))
+	for k := range vars {
+		keys = append(keys, k)
+	}
+
+	return keys
+}
+
+// fetch calls the service, if it has not already been called successfully,
+// and returns the variables mapped from its response.
+func (g *GRPCLookuper) fetch(ctx context.Context) (map[string]string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.vars != nil {
+		return g.vars, nil
+	}
+	if g.Conn == nil || g.NewResponse == nil {
+		return nil, errors.New("GRPCLookuper requires Conn and NewResponse")
+	}
+
+	resp := g.NewResponse()
+	if err := g.Conn.Invoke(ctx, g.Method, g.Request, resp); err != nil {
+		return nil, err
+	}
+
+	rv := reflect.ValueOf(resp)
+	if rv.Kind() != reflect.Pointer || rv.Elem().Kind() != reflect.Struct {
+		return nil, errors.New("GRPCLookuper response must be a pointer to struct")
+	}
+	g.vars = g.mapResponse(rv.Elem())
+
+	return g.vars, nil
+}
+
+// mapResponse returns the variables held by the response message `v`.
+func (g *GRPCLookuper) mapResponse(v reflect.Value) map[string]string {
+	vars := make(map[string]string)
+	for _, field := range reflect.VisibleFields(v.Type()) {
+		if !field.IsExported() || len(field.Index) > 1 {
+			continue
+		}
+		fv := v.FieldByIndex(field.Index)
+
+		if fv.Kind() == reflect.Map {
+			iter := fv.MapRange()
+			for iter.Next() {
+				key, keyOK := formatValue(iter.Key(), fieldTag{})
+				val, valOK := formatValue(iter.Value(), fieldTag{})
+				if keyOK && valOK {
+					vars[key] = val
+				}
+			}
+			continue
+		}
+		if fv.Kind() == reflect.Pointer {
+			if fv.IsNil() || fv.Elem().Kind() == reflect.Struct {
+				continue // Unset optional field or nested message.
+			}
+			fv = fv.Elem()
+		}
+		if fv.Kind() == reflect.Struct || fv.Kind() == reflect.Interface {
+			continue // Nested message or oneof.
+		}
+
+		name := protoName(field)
+		key, ok := g.Fields[name]
+		if !ok {
+			key = strings.ToUpper(name)
+		}
+		if val, ok := formatValue(fv, fieldTag{}); ok {
+			vars[key] = val
+		}
+	}
+
+	return vars
+}
+
+// protoName returns the protobuf name of the generated message field
+// `field`, falling back to its Go name.
+func protoName(field reflect.StructField) string {
+	for _, attr := range strings.Split(field.Tag.Get("protobuf"), ",") {
+		if name, ok := strings.CutPrefix(attr, "name="); ok {
+			return name
+		}
+	}
+
+	return field.Name
+}
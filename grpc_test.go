@@ -0,0 +1,95 @@
+package envconf
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+)
+
+// testConfigResponse resembles a message generated by protoc-gen-go.
+type testConfigResponse struct {
+	unknownFields []byte
+
+	DbHost  string            `protobuf:"bytes,1,opt,name=db_host,json=dbHost,proto3"`
+	Port    int32             `protobuf:"varint,2,opt,name=port,proto3"`
+	Timeout *string           `protobuf:"bytes,3,opt,name=timeout,proto3,oneof"`
+	Values  map[string]string `protobuf:"bytes,4,rep,name=values,proto3"`
+}
+
+func TestGRPCLookuper(t *testing.T) {
+	newLookuper := func(calls *int, err error) *GRPCLookuper {
+		return &GRPCLookuper{
+			Conn: InvokerFunc(func(_ context.Context, method string, req, resp any) error {
+				*calls++
+				if err != nil {
+					return err
+				}
+				assertEqual(t, method, "/config.v1.ConfigService/GetConfig")
+				assertEqual(t, req, "billing")
+				r := resp.(*testConfigResponse)
+				r.DbHost = "db.internal"
+				r.Port = 5432
+				r.Values = map[string]string{"FEATURE_X": "on"}
+				return nil
+			}),
+			Method:      "/config.v1.ConfigService/GetConfig",
+			Request:     "billing",
+			NewResponse: func() any { return new(testConfigResponse) },
+			Fields:      map[string]string{"port": "DB_PORT"},
+		}
+	}
+
+	tRun(t, "response fields are mapped to variables", func(t *testing.T) {
+		// Arrange
+		var calls int
+		l := newLookuper(&calls, nil)
+		type testObj struct {
+			Host    string `env:"DB_HOST"`
+			Port    int    `env:"DB_PORT"`
+			Feature string `env:"FEATURE_X"`
+			Timeout string `env:"TIMEOUT,default=5s"`
+		}
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, WithLookuper(l))
+
+		// Assert
+		assertEqual(t, err, nil)
+		assertEqual(t, in, testObj{Host: "db.internal", Port: 5432, Feature: "on", Timeout: "5s"})
+		assertEqual(t, calls, 1)
+	})
+
+	tRun(t, "keys are enumerated", func(t *testing.T) {
+		// Arrange
+		var calls int
+		l := newLookuper(&calls, nil)
+
+		// Act
+		keys := l.Keys()
+		slices.Sort(keys)
+
+		// Assert
+		assertEqual(t, slices.Equal(keys, []string{"DB_HOST", "DB_PORT", "FEATURE_X"}), true)
+	})
+
+	tRun(t, "failed calls are reported and retried", func(t *testing.T) {
+		// Arrange
+		var calls int
+		l := newLookuper(&calls, errors.New("unavailable"))
+		type testObj struct {
+			Host string `env:"DB_HOST"`
+		}
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, WithLookuper(l))
+		_, _, err2 := l.LookupContext(context.Background(), "DB_HOST")
+
+		// Assert
+		assertErrorWithSubStr(t, err, `failed to look up env var "DB_HOST": unavailable`)
+		assertEqual(t, err2 != nil, true)
+		assertEqual(t, calls, 2)
+	})
+}
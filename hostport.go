@@ -0,0 +1,72 @@
+package envconf
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+	"strconv"
+)
+
+// HostPort is a network address made up of a host name or IP address and a
+// port, parsed from the "host:port" form accepted by net.SplitHostPort
+// (IPv6 addresses must be bracketed, e.g. "[::1]:8080"). The host may be
+// empty, as in ":8080".
+type HostPort struct {
+	Host string
+	Port uint16
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (hp *HostPort) UnmarshalText(b []byte) error {
+	host, port, err := splitHostPort(string(b))
+	if err != nil {
+		return err
+	}
+	*hp = HostPort{Host: host, Port: port}
+	return nil
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (hp HostPort) MarshalText() ([]byte, error) {
+	return []byte(hp.String()), nil
+}
+
+// String returns the address in "host:port" form, bracketing IPv6 hosts.
+func (hp HostPort) String() string {
+	return net.JoinHostPort(hp.Host, strconv.Itoa(int(hp.Port)))
+}
+
+// splitHostPort splits `val` into a host and a numeric port.
+func splitHostPort(val string) (string, uint16, error) {
+	host, port, err := net.SplitHostPort(val)
+	if err != nil {
+		return "", 0, err
+	}
+	p, err := strconv.ParseUint(port, 10, 16)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid port %q", port)
+	}
+	return host, uint16(p), nil
+}
+
+// parseTCPAddr parses `val`, in "host:port" form, into a net.TCPAddr. The host
+// must be empty or an IP address (optionally with an IPv6 zone); host names
+// are rejected rather than resolved.
+func parseTCPAddr(val string) (net.TCPAddr, error) {
+	host, port, err := splitHostPort(val)
+	if err != nil {
+		return net.TCPAddr{}, err
+	}
+
+	addr := net.TCPAddr{Port: int(port)}
+	if host != "" {
+		ip, err := netip.ParseAddr(host)
+		if err != nil {
+			return net.TCPAddr{}, fmt.Errorf(
+				"host %q is not an IP address (use envconf.HostPort for host names)", host)
+		}
+		addr.IP, addr.Zone = ip.AsSlice(), ip.Zone()
+	}
+
+	return addr, nil
+}
@@ -0,0 +1,95 @@
+package envconf
+
+import (
+	"errors"
+	"net"
+	"testing"
+)
+
+func TestProcess_HostPort(t *testing.T) {
+	type testObj struct {
+		Upstream HostPort     `env:"UPSTREAM"`
+		Listen   net.TCPAddr  `env:"LISTEN"`
+		Peers    []HostPort   `env:"PEERS"`
+		Admin    *net.TCPAddr `env:"ADMIN"`
+	}
+
+	tRun(t, "addresses are split into host and port", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["UPSTREAM"] = "db.example.com:5432"
+		mockEnvVarMap["LISTEN"] = "[fe80::1%eth0]:8080"
+		mockEnvVarMap["PEERS"] = "[2001:db8::1]:443,:80"
+		mockEnvVarMap["ADMIN"] = "127.0.0.1:9000"
+
+		// Act
+		var in testObj
+		Process(&in, mockEnv())
+
+		// Assert
+		assertEqual(t, in.Upstream, HostPort{Host: "db.example.com", Port: 5432})
+		assertEqual(t, in.Listen.IP.Equal(net.ParseIP("fe80::1")), true)
+		assertEqual(t, in.Listen.Zone, "eth0")
+		assertEqual(t, in.Listen.Port, 8080)
+		assertEqual(t, in.Peers[0], HostPort{Host: "2001:db8::1", Port: 443})
+		assertEqual(t, in.Peers[1], HostPort{Port: 80})
+		assertEqual(t, in.Admin.String(), "127.0.0.1:9000")
+	})
+
+	tRun(t, "malformed addresses are reported", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["UPSTREAM"] = "db.example.com"
+		mockEnvVarMap["LISTEN"] = "localhost:8080"
+		mockEnvVarMap["PEERS"] = "a:70000"
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv())
+
+		// Assert
+		assertErrorWithSubStr(t, err, `invalid envconf.HostPort value supplied: "db.example.com"`)
+		assertErrorWithSubStr(t, err, `invalid []envconf.HostPort value supplied: "a:70000"`)
+		assertErrorWithSubStr(t, err, `invalid net.TCPAddr value supplied: "localhost:8080"`)
+	})
+
+	tRun(t, "host names are not resolved for TCPAddr", func(t *testing.T) {
+		// Arrange
+		type testObj struct {
+			Listen net.TCPAddr `env:"LISTEN"`
+		}
+		mockEnvVarMap["LISTEN"] = "localhost:8080"
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv())
+
+		// Assert
+		var pe *ParseError
+		assertEqual(t, errors.As(err, &pe), true)
+		assertErrorWithSubStr(t, pe.Err, `host "localhost" is not an IP address`)
+	})
+
+	tRun(t, "values round trip", func(t *testing.T) {
+		// Arrange
+		in := testObj{
+			Upstream: HostPort{Host: "::1", Port: 1},
+			Listen:   net.TCPAddr{IP: net.IPv4(10, 0, 0, 1).To4(), Port: 80},
+			Peers:    []HostPort{{Host: "a", Port: 2}},
+		}
+
+		// Act
+		err := RoundTripCheck(in)
+
+		// Assert
+		assertEqual(t, err, nil)
+	})
+}
+
+func TestHostPort_String(t *testing.T) {
+	tRun(t, "brackets IPv6 hosts", func(t *testing.T) {
+		// Act
+		s := HostPort{Host: "::1", Port: 8080}.String()
+
+		// Assert
+		assertEqual(t, s, "[::1]:8080")
+	})
+}
@@ -0,0 +1,279 @@
+package envconf
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Discovery of indexed slices (see the `indexed` attribute) proceeds in one of
+// two ways:
+//
+//   - Probing (the default): indexes 0, 1, 2, ... are looked up until `gap`
+//     consecutive indexes are missing (1 unless set with the `gap`
+//     attribute), so lists may be sparse. Missing indexes are skipped.
+//   - Counting: if the `count` attribute names a variable, it holds the
+//     number of elements and exactly that many indexes are read; a missing
+//     index is reported as an error.
+//
+// Both bound the number of lookups made, so large and sparse lists resolve
+// predictably. The elements of slices of structs are discovered in the same
+// way, an index being present if any of the element's variables is set.
+
+// indexedKey returns the name of the variable holding element `i` of the
+// indexed slice `key`.
+func indexedKey(key string, i int) string {
+	return key + "_" + strconv.Itoa(i)
+}
+
+// countKey returns the name of the variable holding the number of elements
+// of the indexed slice `fi` (see the `count` attribute), which shares the
+// prefix of the slice's own variable.
+func countKey(fi FieldInfo) string {
+	return strings.TrimSuffix(fi.Key, fi.tag.key) + fi.tag.count
+}
+
+// processIndexed populates the indexed slice `fv` (see the `indexed`
+// attribute) at path `field` from the variables `key`_0, `key`_1, and so on.
+// `isZero` reports whether `fv` held its zero value beforehand.
+func (o *options) processIndexed(fv reflect.Value, key, field string, tag fieldTag, isZero bool) error {
+	if fv.Kind() != reflect.Slice || fv.Type() == bytesType || isLeafType(fv.Type()) {
+		return fmt.Errorf("field %q: %s attribute requires a slice", field, tagAttrIndexed)
+	}
+	if et := fv.Type().Elem(); et.Kind() == reflect.Struct && !o.isLeaf(et) && tag.format == "" {
+		return o.processIndexedStructs(fv, key, field, tag, isZero)
+	}
+
+	keys, vals, err := o.lookupIndexed(key, field, tag)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case len(vals) > 0:
+	case o.overwrite == OverwriteIfSet && !isZero:
+		return nil // Only a value from the source may replace this one.
+	case tag.defaultVal != "":
+		// Defaults are delimited lists, as for non-indexed slices.
+		keys, vals = []string{key}, []string{tag.defaultVal}
+	case tag.required:
+		return &MissingError{Var: indexedKey(key, 0), Field: field}
+	default:
+		return nil
+	}
+
+	var errs []error
+	for i := range vals {
+		val, ok, err := o.prepareValue(keys[i], field, vals[i], tag, fv.Type().Elem())
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if !ok {
+			return errors.Join(errs...) // Leave the field untouched.
+		}
+		vals[i] = val
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	if len(keys) == 1 && keys[0] == key {
+		if err := setField(fv, vals[0], tag); err != nil {
+			return &ParseError{Var: key, Field: field, Value: tag.redact(vals[0]),
+				Kind: typeName(fv.Type()), Err: tag.redactErr(err)}
+		}
+		return nil
+	}
+
+	s := reflect.MakeSlice(fv.Type(), len(vals), len(vals))
+	for i, val := range vals {
+		if err := setField(s.Index(i), val, tag); err != nil {
+			errs = append(errs, &ParseError{Var: keys[i], Field: field, Value: tag.redact(val),
+				Kind: typeName(fv.Type().Elem()), Err: tag.redactErr(err)})
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...) // Leave the field untouched.
+	}
+	fv.Set(s)
+
+	return nil
+}
+
+// lookupIndexed returns the names and values of the elements of the indexed
+// slice `key` at path `field`, discovered as configured by `tag`.
+func (o *options) lookupIndexed(key, field string, tag fieldTag) ([]string, []string, error) {
+	if tag.count != "" {
+		return o.lookupCounted(key, field, tag)
+	}
+
+	gap := max(tag.gap, 1)
+	var keys, vals []string
+	for i, missing := 0, 0; missing < gap; i++ {
+		k := indexedKey(key, i)
+		val, _, err := o.lookupField(k, tag)
+		if err != nil {
+			return nil, nil, &SourceError{Var: k, Op: opLookup, Err: err}
+		}
+		if val == "" {
+			missing++
+			continue
+		}
+		missing = 0
+		keys, vals = append(keys, k), append(vals, val)
+	}
+
+	return keys, vals, nil
+}
+
+// lookupCounted is like lookupIndexed for indexed slices whose length is held
+// by the variable named by the `count` attribute.
+func (o *options) lookupCounted(key, field string, tag fieldTag) ([]string, []string, error) {
+	n, err := o.lookupCount(field, tag)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		keys = make([]string, n)
+		vals = make([]string, n)
+		errs []error
+	)
+	for i := range vals {
+		keys[i] = indexedKey(key, i)
+		if vals[i], _, err = o.lookupField(keys[i], tag); err != nil {
+			return nil, nil, &SourceError{Var: keys[i], Op: opLookup, Err: err}
+		}
+		if vals[i] == "" {
+			errs = append(errs, &MissingError{Var: keys[i], Field: field})
+		}
+	}
+
+	return keys, vals, errors.Join(errs...)
+}
+
+// processIndexedStructs is like processIndexed for slices of structs, whose
+// element `i` is populated from the variables `key`_`i`_FIELD.
+func (o *options) processIndexedStructs(fv reflect.Value, key, field string, tag fieldTag, isZero bool) error {
+	et := fv.Type().Elem()
+	fields, err := o.collectFields(reflect.New(et).Interface())
+	if err != nil {
+		return err
+	}
+
+	var indexes []int
+	if tag.count != "" {
+		n, err := o.lookupCount(field, tag)
+		if err != nil {
+			return err
+		}
+		var errs []error
+		for i := range n {
+			ok, err := o.elementPresent(indexedKey(key, i)+"_", fields)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				errs = append(errs, &MissingError{Var: indexedKey(key, i) + "_*",
+					Field: fmt.Sprintf("%s[%d]", field, i)})
+				continue
+			}
+			indexes = append(indexes, i)
+		}
+		if len(errs) > 0 {
+			return errors.Join(errs...) // Leave the field untouched.
+		}
+	} else {
+		gap := max(tag.gap, 1)
+		for i, missing := 0, 0; missing < gap; i++ {
+			ok, err := o.elementPresent(indexedKey(key, i)+"_", fields)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				missing++
+				continue
+			}
+			missing = 0
+			indexes = append(indexes, i)
+		}
+	}
+
+	switch {
+	case len(indexes) > 0:
+	case o.overwrite == OverwriteIfSet && !isZero:
+		return nil // Only a value from the source may replace this one.
+	case tag.required:
+		return &MissingError{Var: indexedKey(key, 0), Field: field}
+	default:
+		return nil
+	}
+
+	var (
+		errs []error
+		s    = reflect.MakeSlice(fv.Type(), len(indexes), len(indexes))
+	)
+	for j, i := range indexes {
+		eo := *o
+		eo.keyPrefix = indexedKey(key, i) + "_"
+		path := fmt.Sprintf("%s[%d].", field, i)
+		if err := processFields(s.Index(j).Addr(), &eo, path); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...) // Leave the field untouched.
+	}
+	fv.Set(s)
+
+	return nil
+}
+
+// elementPresent reports whether any of `fields` is set under `prefix`.
+func (o *options) elementPresent(prefix string, fields []FieldInfo) (bool, error) {
+	for _, fi := range fields {
+		k := prefix + fi.Key
+		val, _, err := o.lookupField(k, fi.tag)
+		if err != nil {
+			return false, &SourceError{Var: k, Op: opLookup, Err: err}
+		}
+		if val != "" {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// maxIndexedCount bounds the number of elements an indexed slice with the
+// `count` attribute may declare, so that a mistaken count cannot exhaust
+// memory.
+const maxIndexedCount = 1 << 16
+
+// lookupCount returns the number of elements held by the variable named by
+// the `count` attribute of the indexed slice at path `field`, or 0 if it is
+// unset.
+func (o *options) lookupCount(field string, tag fieldTag) (int, error) {
+	key := o.keyPrefix + tag.count
+	raw, _, err := o.lookupField(key, tag)
+	if err != nil {
+		return 0, &SourceError{Var: key, Op: opLookup, Err: err}
+	}
+	if raw == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 0 {
+		return 0, &ParseError{Var: key, Field: field, Value: raw,
+			Kind: "count", Err: errors.New("expected a non-negative integer")}
+	}
+	if n > maxIndexedCount {
+		return 0, &ParseError{Var: key, Field: field, Value: raw,
+			Kind: "count", Err: fmt.Errorf("exceeds the maximum of %d", maxIndexedCount)}
+	}
+
+	return n, nil
+}
@@ -0,0 +1,324 @@
+package envconf
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestProcess_Indexed(t *testing.T) {
+	tRun(t, "stops at the first missing index by default", func(t *testing.T) {
+		// Arrange
+		type testObj struct {
+			Hosts []string `env:"HOST,indexed"`
+		}
+		mockEnvVarMap["HOST_0"] = "a"
+		mockEnvVarMap["HOST_1"] = "b,c"
+		mockEnvVarMap["HOST_3"] = "d"
+
+		// Act
+		var in testObj
+		Process(&in, mockEnv())
+
+		// Assert
+		assertEqual(t, len(in.Hosts), 2)
+		assertEqual(t, in.Hosts[1], "b,c")
+	})
+
+	tRun(t, "gap tolerates sparse indexes", func(t *testing.T) {
+		// Arrange
+		type testObj struct {
+			Ports []int `env:"PORT,indexed,gap=3"`
+		}
+		mockEnvVarMap["PORT_0"] = "80"
+		mockEnvVarMap["PORT_3"] = "443"
+		mockEnvVarMap["PORT_7"] = "8443"
+
+		// Act
+		var in testObj
+		Process(&in, mockEnv())
+
+		// Assert
+		assertEqual(t, len(in.Ports), 2)
+		assertEqual(t, in.Ports[1], 443)
+	})
+
+	tRun(t, "count reads exactly that many elements", func(t *testing.T) {
+		// Arrange
+		type testObj struct {
+			Items []string `env:"ITEM,indexed,count=ITEMS_COUNT"`
+		}
+		mockEnvVarMap["ITEMS_COUNT"] = "3"
+		mockEnvVarMap["ITEM_0"] = "a"
+		mockEnvVarMap["ITEM_2"] = "c"
+		mockEnvVarMap["ITEM_3"] = "d"
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv())
+
+		// Assert
+		var me *MissingError
+		assertEqual(t, errors.As(err, &me), true)
+		assertEqual(t, me.Var, "ITEM_1")
+		assertEqual(t, me.Field, "Items")
+		assertEqual(t, in.Items == nil, true)
+	})
+
+	tRun(t, "invalid counts are reported", func(t *testing.T) {
+		// Arrange
+		type testObj struct {
+			Items []string `env:"ITEM,indexed,count=ITEMS_COUNT"`
+		}
+		mockEnvVarMap["ITEMS_COUNT"] = "-1"
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv())
+
+		// Assert
+		assertErrorWithSubStr(t, err, `invalid count value supplied: "-1"`)
+	})
+
+	tRun(t, "excessive counts are reported", func(t *testing.T) {
+		// Arrange
+		type testObj struct {
+			Items []string `env:"ITEM,indexed,count=ITEMS_COUNT"`
+		}
+		mockEnvVarMap["ITEMS_COUNT"] = "99999999999999"
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv())
+
+		// Assert
+		var pe *ParseError
+		assertEqual(t, errors.As(err, &pe), true)
+		assertEqual(t, pe.Var, "ITEMS_COUNT")
+		assertErrorWithSubStr(t, err, "exceeds the maximum of 65536")
+	})
+
+	tRun(t, "counts are prefixed like the elements", func(t *testing.T) {
+		// Arrange
+		type testObj struct {
+			DB struct {
+				Hosts []string `env:"HOST,indexed,count=HOST_COUNT"`
+			} `env:",prefix=DB_"`
+		}
+		mockEnvVarMap["DB_HOST_COUNT"] = "2"
+		mockEnvVarMap["DB_HOST_0"] = "a"
+		mockEnvVarMap["DB_HOST_1"] = "b"
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv())
+
+		// Assert
+		assertEqual(t, err, nil)
+		assertEqual(t, len(in.DB.Hosts), 2)
+	})
+
+	tRun(t, "defaults and required apply when no element is set", func(t *testing.T) {
+		// Arrange
+		type testObj struct {
+			Hosts []string `env:"HOST,indexed,default=a;b,separator=;"`
+			Peers []string `env:"PEER,indexed,required"`
+		}
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv())
+
+		// Assert
+		assertErrorWithSubStr(t, err, `env var "PEER_0" not set`)
+		assertEqual(t, len(in.Hosts), 2)
+		assertEqual(t, in.Hosts[1], "b")
+	})
+
+	tRun(t, "element errors name the element variable", func(t *testing.T) {
+		// Arrange
+		type testObj struct {
+			Ports []int `env:"PORT,indexed"`
+		}
+		mockEnvVarMap["PORT_0"] = "80"
+		mockEnvVarMap["PORT_1"] = "http"
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv())
+
+		// Assert
+		var pe *ParseError
+		assertEqual(t, errors.As(err, &pe), true)
+		assertEqual(t, pe.Var, "PORT_1")
+		assertEqual(t, pe.Kind, "int")
+		assertEqual(t, in.Ports == nil, true)
+	})
+
+	tRun(t, "non-slice fields are rejected", func(t *testing.T) {
+		// Arrange
+		type testObj struct {
+			Port int `env:"PORT,indexed"`
+		}
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv())
+
+		// Assert
+		assertErrorWithSubStr(t, err, `field "Port": indexed attribute requires a slice`)
+	})
+
+	tRun(t, "values round trip", func(t *testing.T) {
+		// Arrange
+		type testObj struct {
+			Hosts []string `env:"HOST,indexed"`
+		}
+		in := testObj{Hosts: []string{"a,b", "c"}}
+
+		// Act
+		env, err := Marshal(in)
+		rtErr := RoundTripCheck(in)
+
+		// Assert
+		assertEqual(t, err, nil)
+		assertEqual(t, env["HOST_0"], "a,b")
+		assertEqual(t, rtErr, nil)
+	})
+
+	tRun(t, "counted values round trip", func(t *testing.T) {
+		// Arrange
+		type testObj struct {
+			Ports []int `env:"PORT,indexed,count=PORTS"`
+		}
+		in := testObj{Ports: []int{80, 0, 443}}
+
+		// Act
+		env, err := Marshal(in)
+		rtErr := RoundTripCheck(in)
+
+		// Assert
+		assertEqual(t, err, nil)
+		assertEqual(t, env["PORTS"], "3")
+		assertEqual(t, env["PORT_1"], "0")
+		assertEqual(t, rtErr, nil)
+	})
+}
+
+func TestProcess_IndexedStructs(t *testing.T) {
+	type upstream struct {
+		Host string `env:"HOST,required"`
+		Port int    `env:"PORT,default=80"`
+	}
+	type testObj struct {
+		Upstreams []upstream `env:"UPSTREAM,indexed"`
+	}
+
+	tRun(t, "elements are read until the first missing index", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["UPSTREAM_0_HOST"] = "a.example.com"
+		mockEnvVarMap["UPSTREAM_0_PORT"] = "8080"
+		mockEnvVarMap["UPSTREAM_1_HOST"] = "b.example.com"
+		mockEnvVarMap["UPSTREAM_3_HOST"] = "d.example.com"
+
+		// Act
+		var in testObj
+		Process(&in, mockEnv())
+
+		// Assert
+		assertEqual(t, len(in.Upstreams), 2)
+		assertEqual(t, in.Upstreams[0], upstream{Host: "a.example.com", Port: 8080})
+		assertEqual(t, in.Upstreams[1], upstream{Host: "b.example.com", Port: 80})
+	})
+
+	tRun(t, "element errors name the element variable", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["UPSTREAM_0_HOST"] = "a.example.com"
+		mockEnvVarMap["UPSTREAM_1_PORT"] = "8080"
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv())
+
+		// Assert
+		var me *MissingError
+		assertEqual(t, errors.As(err, &me), true)
+		assertEqual(t, me.Var, "UPSTREAM_1_HOST")
+		assertEqual(t, me.Field, "Upstreams[1].Host")
+		assertEqual(t, in.Upstreams == nil, true)
+	})
+
+	tRun(t, "element errors name the variable index", func(t *testing.T) {
+		// Arrange
+		type testObj struct {
+			Upstreams []upstream `env:"UPSTREAM,indexed,gap=2"`
+		}
+		mockEnvVarMap["UPSTREAM_0_HOST"] = "a.example.com"
+		mockEnvVarMap["UPSTREAM_2_PORT"] = "8080"
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv())
+
+		// Assert
+		var me *MissingError
+		assertEqual(t, errors.As(err, &me), true)
+		assertEqual(t, me.Var, "UPSTREAM_2_HOST")
+		assertEqual(t, me.Field, "Upstreams[2].Host")
+	})
+
+	tRun(t, "count reads exactly that many elements", func(t *testing.T) {
+		// Arrange
+		type testObj struct {
+			Upstreams []upstream `env:"UPSTREAM,indexed,count=UPSTREAMS"`
+		}
+		mockEnvVarMap["UPSTREAMS"] = "3"
+		mockEnvVarMap["UPSTREAM_0_HOST"] = "a.example.com"
+		mockEnvVarMap["UPSTREAM_2_PORT"] = "8080"
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv())
+
+		// Assert
+		var me *MissingError
+		assertEqual(t, errors.As(err, &me), true)
+		assertEqual(t, me.Var, "UPSTREAM_1_*")
+		assertEqual(t, me.Field, "Upstreams[1]")
+		assertEqual(t, in.Upstreams == nil, true)
+	})
+
+	tRun(t, "count reports every missing element", func(t *testing.T) {
+		// Arrange
+		type testObj struct {
+			Upstreams []upstream `env:"UPSTREAM,indexed,count=UPSTREAMS"`
+		}
+		mockEnvVarMap["UPSTREAMS"] = "3"
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv())
+
+		// Assert
+		assertErrorWithSubStr(t, err, `env var "UPSTREAM_0_*" not set`)
+		assertErrorWithSubStr(t, err, `env var "UPSTREAM_1_*" not set`)
+		assertErrorWithSubStr(t, err, `env var "UPSTREAM_2_*" not set`)
+		assertEqual(t, in.Upstreams == nil, true)
+	})
+
+	tRun(t, "values round trip", func(t *testing.T) {
+		// Arrange
+		in := testObj{Upstreams: []upstream{
+			{Host: "a.example.com", Port: 8080},
+			{Host: "b.example.com", Port: 80},
+		}}
+
+		// Act
+		env, err := Marshal(in)
+		rtErr := RoundTripCheck(in)
+
+		// Assert
+		assertEqual(t, err, nil)
+		assertEqual(t, env["UPSTREAM_1_HOST"], "b.example.com")
+		assertEqual(t, rtErr, nil)
+	})
+}
@@ -0,0 +1,54 @@
+package envconf
+
+import (
+	"bytes"
+	"context"
+)
+
+// KeychainLookuper is a Lookuper backed by the operating system's credential
+// store, allowing developers to keep secrets out of shell profiles during local
+// runs:
+//
+//   - macOS - the login Keychain, via the security(1) tool, where each variable
+//     is a generic password with service Service and account KEY.
+//   - Linux - the Secret Service (GNOME Keyring, KWallet, etc.), via
+//     secret-tool(1), where each variable is stored with the attributes
+//     "service" Service and "account" KEY.
+//   - Windows - the Credential Manager, where each variable is a generic
+//     credential with target name "Service/KEY" and a UTF-8 encoded secret.
+//
+// On other platforms, or when the credential store is unavailable, every
+// variable is reported as not present. It is typically placed after an
+// OSLookuper in a MultiLookuper so that real environment variables take
+// precedence.
+type KeychainLookuper struct {
+	// Service namespaces the stored secrets, usually the application name.
+	Service string
+}
+
+// Lookup retrieves the secret stored for `key` in the credential store.
+func (k KeychainLookuper) Lookup(key string) (string, bool) {
+	val, ok, _ := k.LookupContext(context.Background(), key)
+	return val, ok
+}
+
+// LookupContext is like Lookup but abandons the lookup, returning ctx.Err(),
+// if `ctx` is done first.
+func (k KeychainLookuper) LookupContext(ctx context.Context, key string) (string, bool, error) {
+	return keychainLookup(ctx, k.Service, key)
+}
+
+// commandLookup runs a credential store command returning its output, minus
+// the trailing newline, as the value. A failing command is reported as the
+// value not being present unless `ctx` is done.
+func commandLookup(ctx context.Context, name string, args ...string) (string, bool, error) {
+	out, err := runCommand(ctx, name, args...)
+	if ctx.Err() != nil {
+		return "", false, ctx.Err()
+	}
+	if err != nil {
+		return "", false, nil
+	}
+
+	return string(bytes.TrimSuffix(out, []byte("\n"))), true, nil
+}
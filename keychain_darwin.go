@@ -0,0 +1,9 @@
+package envconf
+
+import "context"
+
+// keychainLookup reads a generic password from the macOS Keychain.
+func keychainLookup(ctx context.Context, service, key string) (string, bool, error) {
+	return commandLookup(ctx, "security",
+		"find-generic-password", "-s", service, "-a", key, "-w")
+}
@@ -0,0 +1,11 @@
+//go:build !unix && !windows
+
+package envconf
+
+import "context"
+
+// keychainLookup always reports the secret as not present since no supported
+// credential store exists on this platform.
+func keychainLookup(ctx context.Context, service, key string) (string, bool, error) {
+	return "", false, nil
+}
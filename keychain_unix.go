@@ -0,0 +1,11 @@
+//go:build unix && !darwin
+
+package envconf
+
+import "context"
+
+// keychainLookup reads a secret from the Secret Service via secret-tool.
+func keychainLookup(ctx context.Context, service, key string) (string, bool, error) {
+	return commandLookup(ctx, "secret-tool",
+		"lookup", "service", service, "account", key)
+}
@@ -0,0 +1,49 @@
+//go:build unix && !darwin
+
+package envconf
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestKeychainLookuper(t *testing.T) {
+	// Pre Arrange
+	defer func(f func(context.Context, string, ...string) ([]byte, error)) {
+		runCommand = f
+	}(runCommand)
+
+	tRun(t, "secret is read via secret-tool", func(t *testing.T) {
+		// Arrange
+		var gotArgs []string
+		runCommand = func(_ context.Context, name string, args ...string) ([]byte, error) {
+			gotArgs = append([]string{name}, args...)
+			return []byte("s3cret\n"), nil
+		}
+
+		// Act
+		val, ok := KeychainLookuper{Service: "myapp"}.Lookup("DB_PASSWORD")
+
+		// Assert
+		assertEqual(t, ok, true)
+		assertEqual(t, val, "s3cret")
+		assertEqual(t, slices.Equal(gotArgs, []string{
+			"secret-tool", "lookup", "service", "myapp", "account", "DB_PASSWORD",
+		}), true)
+	})
+
+	tRun(t, "missing secret is reported as not present", func(t *testing.T) {
+		// Arrange
+		runCommand = func(_ context.Context, name string, args ...string) ([]byte, error) {
+			return nil, errors.New("exit status 1")
+		}
+
+		// Act
+		_, ok := KeychainLookuper{Service: "myapp"}.Lookup("DB_PASSWORD")
+
+		// Assert
+		assertEqual(t, ok, false)
+	})
+}
@@ -0,0 +1,62 @@
+package envconf
+
+import (
+	"context"
+	"syscall"
+	"unsafe"
+)
+
+const credTypeGeneric = 1
+
+var (
+	modAdvapi32   = syscall.NewLazyDLL("advapi32.dll")
+	procCredReadW = modAdvapi32.NewProc("CredReadW")
+	procCredFree  = modAdvapi32.NewProc("CredFree")
+)
+
+// credential mirrors the Win32 CREDENTIALW structure.
+type credential struct {
+	Flags              uint32
+	Type               uint32
+	TargetName         *uint16
+	Comment            *uint16
+	LastWritten        syscall.Filetime
+	CredentialBlobSize uint32
+	CredentialBlob     *byte
+	Persist            uint32
+	AttributeCount     uint32
+	Attributes         uintptr
+	TargetAlias        *uint16
+	UserName           *uint16
+}
+
+// keychainLookup reads a generic credential from the Windows Credential
+// Manager.
+func keychainLookup(ctx context.Context, service, key string) (string, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return "", false, err
+	}
+	if procCredReadW.Find() != nil {
+		return "", false, nil
+	}
+
+	target, err := syscall.UTF16PtrFromString(service + "/" + key)
+	if err != nil {
+		return "", false, nil
+	}
+
+	var cred *credential
+	ret, _, _ := procCredReadW.Call(
+		uintptr(unsafe.Pointer(target)),
+		credTypeGeneric,
+		0,
+		uintptr(unsafe.Pointer(&cred)),
+	)
+	if ret == 0 {
+		return "", false, nil
+	}
+	defer procCredFree.Call(uintptr(unsafe.Pointer(cred)))
+
+	blob := unsafe.Slice(cred.CredentialBlob, cred.CredentialBlobSize)
+	return string(blob), true, nil
+}
@@ -0,0 +1,48 @@
+package envconf
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// checkLength reports an error if `val` exceeds the maximum value length. The
+// value itself is deliberately not included in the error.
+func (o *options) checkLength(key, val string) error {
+	if o.maxLength > 0 && len(val) > o.maxLength {
+		return fmt.Errorf("env var %q: value of %d bytes exceeds limit of %d: %w",
+			key, len(val), o.maxLength, ErrValueTooLarge)
+	}
+	return nil
+}
+
+// checkDecodedSize reports an error if decoding `val` into a field of type `t`
+// would produce more than the maximum decoded size.
+func (o *options) checkDecodedSize(key, val string, tag fieldTag, t reflect.Type) error {
+	isDecoded := t == bytesType || isBinaryField(t, tag) ||
+		t.Kind() == reflect.String && tag.encoding != ""
+	if o.maxDecoded <= 0 || !isDecoded {
+		return nil
+	}
+
+	if n := decodedLen(val, tag.encoding); n > o.maxDecoded {
+		return fmt.Errorf("env var %q: decoded value of %d bytes exceeds limit of %d: %w",
+			key, n, o.maxDecoded, ErrValueTooLarge)
+	}
+	return nil
+}
+
+// decodedLen returns the maximum number of bytes decoding `val` with the
+// named `encoding` may produce.
+func decodedLen(val, encoding string) int {
+	switch encoding {
+	case "hex":
+		return hex.DecodedLen(len(val))
+	case "base64", "base64url":
+		return base64.RawStdEncoding.DecodedLen(len(strings.TrimRight(val, "=")))
+	}
+
+	return len(val)
+}
@@ -0,0 +1,78 @@
+package envconf
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestProcess_SizeLimits(t *testing.T) {
+	tRun(t, "values over the maximum length are rejected", func(t *testing.T) {
+		// Arrange
+		type testObj struct {
+			Small string `env:"SMALL"`
+			Large string `env:"LARGE"`
+		}
+		mockEnvVarMap["SMALL"] = "ok"
+		mockEnvVarMap["LARGE"] = strings.Repeat("x", 1025)
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv(), WithMaxValueLength(1024))
+
+		// Assert
+		assertEqual(t, errors.Is(err, ErrValueTooLarge), true)
+		assertErrorWithSubStr(t, err, `env var "LARGE": value of 1025 bytes exceeds limit of 1024`)
+		assertEqual(t, in.Small, "ok")
+		assertEqual(t, in.Large, "")
+	})
+
+	tRun(t, "resolved values are checked", func(t *testing.T) {
+		// Arrange
+		type testObj struct {
+			Secret string `env:"SECRET"`
+		}
+		mockEnvVarMap["SECRET"] = "ref://big"
+		r := ResolverFunc(func(string) (string, error) {
+			return strings.Repeat("x", 100), nil
+		})
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv(), WithResolver("ref", r), WithMaxValueLength(10))
+
+		// Assert
+		assertEqual(t, errors.Is(err, ErrValueTooLarge), true)
+	})
+
+	tRun(t, "decoded sizes over the maximum are rejected", func(t *testing.T) {
+		// Arrange
+		type testObj struct {
+			Key []byte `env:"KEY,encoding=hex"`
+		}
+		mockEnvVarMap["KEY"] = strings.Repeat("ab", 33)
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv(), WithMaxDecodedSize(32))
+
+		// Assert
+		assertErrorWithSubStr(t, err, `env var "KEY": decoded value of 33 bytes exceeds limit of 32`)
+	})
+
+	tRun(t, "values within limits are accepted", func(t *testing.T) {
+		// Arrange
+		type testObj struct {
+			Key []byte `env:"KEY,encoding=base64"`
+		}
+		mockEnvVarMap["KEY"] = "AAECAwQFBgcICQoLDA0ODxAREhMUFRYXGBkaGxwdHh8="
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv(), WithMaxDecodedSize(32), WithMaxValueLength(64))
+
+		// Assert
+		assertEqual(t, err, nil)
+		assertEqual(t, len(in.Key), 32)
+	})
+}
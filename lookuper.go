@@ -0,0 +1,208 @@
+package envconf
+
+import (
+	"context"
+	"os"
+	"strings"
+)
+
+// Lookuper retrieves the value of a configuration variable. The boolean result
+// reports whether the variable was present.
+//
+// A Lookuper shared between concurrent calls to Process must be safe for
+// concurrent use.
+type Lookuper interface {
+	Lookup(key string) (string, bool)
+}
+
+// ContextLookuper is implemented by Lookupers whose lookups may block (e.g.
+// network or subprocess backed). ProcessContext prefers LookupContext over
+// Lookup when it is available; a non-nil error aborts the lookup of the
+// variable (e.g. because `ctx` was cancelled) and is reported by Process.
+type ContextLookuper interface {
+	Lookuper
+	LookupContext(ctx context.Context, key string) (string, bool, error)
+}
+
+// Enumerator is implemented by Lookupers that are able to list the keys of
+// every variable they hold. It is required by features that discover
+// variables rather than look them up by name (see ProcessTenants).
+type Enumerator interface {
+	Keys() []string
+}
+
+// LookuperFunc is an adapter allowing an ordinary function to be used as a
+// Lookuper.
+type LookuperFunc func(key string) (string, bool)
+
+// Lookup calls f(key).
+func (f LookuperFunc) Lookup(key string) (string, bool) {
+	return f(key)
+}
+
+// OSLookuper is a Lookuper backed by the process environment.
+type OSLookuper struct{}
+
+// Lookup retrieves the value of the environment variable named by `key` (see
+// os.LookupEnv).
+func (OSLookuper) Lookup(key string) (string, bool) {
+	return os.LookupEnv(key)
+}
+
+// Keys returns the names of every variable in the process environment.
+func (OSLookuper) Keys() []string {
+	environ := os.Environ()
+	keys := make([]string, 0, len(environ))
+	for _, kv := range environ {
+		k, _, _ := strings.Cut(kv, "=")
+		keys = append(keys, k)
+	}
+
+	return keys
+}
+
+// MapLookuper is a Lookuper backed by a map of variable names to values.
+type MapLookuper map[string]string
+
+// Lookup returns the value stored in `m` under `key`.
+func (m MapLookuper) Lookup(key string) (string, bool) {
+	val, ok := m[key]
+	return val, ok
+}
+
+// Keys returns the keys of `m`.
+func (m MapLookuper) Keys() []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	return keys
+}
+
+// MultiLookuper returns a Lookuper that consults each of `ls` in order,
+// returning the first value found. The returned Lookuper implements Enumerator,
+// listing the keys of every member that itself implements Enumerator.
+func MultiLookuper(ls ...Lookuper) Lookuper {
+	return multiLookuper(ls)
+}
+
+type multiLookuper []Lookuper
+
+func (m multiLookuper) Lookup(key string) (string, bool) {
+	for _, l := range m {
+		if val, ok := l.Lookup(key); ok {
+			return val, true
+		}
+	}
+	return "", false
+}
+
+func (m multiLookuper) LookupContext(ctx context.Context, key string) (string, bool, error) {
+	for _, l := range m {
+		val, ok, err := lookupContext(ctx, l, key)
+		if err != nil || ok {
+			return val, ok, err
+		}
+	}
+	return "", false, nil
+}
+
+func (m multiLookuper) Keys() []string {
+	var (
+		keys []string
+		seen = make(map[string]bool)
+	)
+	for _, l := range m {
+		enum, ok := l.(Enumerator)
+		if !ok {
+			continue
+		}
+		for _, k := range enum.Keys() {
+			if !seen[k] {
+				seen[k] = true
+				keys = append(keys, k)
+			}
+		}
+	}
+
+	return keys
+}
+
+// lookupContext retrieves `key` from `l`, via LookupContext if `l` is a
+// ContextLookuper.
+func lookupContext(ctx context.Context, l Lookuper, key string) (string, bool, error) {
+	if cl, ok := l.(ContextLookuper); ok {
+		return cl.LookupContext(ctx, key)
+	}
+
+	val, ok := l.Lookup(key)
+	return val, ok, nil
+}
+
+// processEnvKey returns the name of the process environment variable from
+// which `l` answers lookups of `key`, seeing through the Lookupers provided by
+// this package. The boolean result is false if the value of `key` is supplied
+// by any other source, or by none.
+func processEnvKey(ctx context.Context, l Lookuper, key string) (string, bool) {
+	switch l := l.(type) {
+	case OSLookuper, *OSLookuper, envSnapshot:
+		return key, true
+	case multiLookuper:
+		for _, sub := range l {
+			if _, ok, err := lookupContext(ctx, sub, key); err != nil || ok {
+				return processEnvKey(ctx, sub, key)
+			}
+		}
+	case mappedLookuper:
+		return processEnvKey(ctx, l.l, l.m(key))
+	case prefixLookuper:
+		return processEnvKey(ctx, l.l, l.prefix+key)
+	}
+
+	return "", false
+}
+
+// KeyMapper translates a variable name into the key used by a particular
+// source, e.g. "DB_PASSWORD" into "database/creds#password" for a secret
+// store.
+type KeyMapper func(envKey string) string
+
+// KeyMap returns a KeyMapper translating keys according to `m`. Keys absent
+// from `m` are passed through unchanged.
+func KeyMap(m map[string]string) KeyMapper {
+	return func(envKey string) string {
+		if k, ok := m[envKey]; ok {
+			return k
+		}
+		return envKey
+	}
+}
+
+// MapKeys returns a Lookuper that translates every key with `m` before
+// consulting `l`, allowing source specific naming (such as secret store
+// paths) without changing struct tags. It is typically applied to a single
+// member of a MultiLookuper:
+//
+//	l := envconf.MultiLookuper(
+//		envconf.OSLookuper{},
+//		envconf.MapKeys(vault, func(k string) string {
+//			return "app/" + strings.ToLower(k)
+//		}),
+//	)
+func MapKeys(l Lookuper, m KeyMapper) Lookuper {
+	return mappedLookuper{l: l, m: m}
+}
+
+type mappedLookuper struct {
+	l Lookuper
+	m KeyMapper
+}
+
+func (ml mappedLookuper) Lookup(key string) (string, bool) {
+	return ml.l.Lookup(ml.m(key))
+}
+
+func (ml mappedLookuper) LookupContext(ctx context.Context, key string) (string, bool, error) {
+	return lookupContext(ctx, ml.l, ml.m(key))
+}
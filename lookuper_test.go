@@ -0,0 +1,151 @@
+package envconf
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestProcess_DefaultLookuper(t *testing.T) {
+	tRun(t, "reads from the process environment", func(t *testing.T) {
+		// Arrange
+		type testObj struct {
+			Field string `env:"ENVCONF_TEST_FIELD"`
+		}
+		t.Setenv("ENVCONF_TEST_FIELD", "from-os")
+
+		// Act
+		var in testObj
+		Process(&in)
+
+		// Assert
+		assertEqual(t, in.Field, "from-os")
+	})
+}
+
+func TestMultiLookuper(t *testing.T) {
+	tRun(t, "first lookuper holding the key wins", func(t *testing.T) {
+		// Arrange
+		l := MultiLookuper(MapLookuper{"A": "1"}, MapLookuper{"A": "2", "B": "3"})
+
+		// Act
+		a, _ := l.Lookup("A")
+		b, _ := l.Lookup("B")
+		_, ok := l.Lookup("C")
+
+		// Assert
+		assertEqual(t, a, "1")
+		assertEqual(t, b, "3")
+		assertEqual(t, ok, false)
+	})
+
+	tRun(t, "keys of enumerable members are merged", func(t *testing.T) {
+		// Arrange
+		l := MultiLookuper(
+			MapLookuper{"A": "1"},
+			LookuperFunc(func(string) (string, bool) { return "", false }),
+			MapLookuper{"A": "2", "B": "3"},
+		)
+
+		// Act
+		keys := l.(Enumerator).Keys()
+		slices.Sort(keys)
+
+		// Assert
+		assertEqual(t, slices.Equal(keys, []string{"A", "B"}), true)
+	})
+}
+
+func TestMapKeys(t *testing.T) {
+	tRun(t, "keys are translated before lookup", func(t *testing.T) {
+		// Arrange
+		type testObj struct {
+			Password string `env:"DB_PASSWORD"`
+			User     string `env:"DB_USER"`
+		}
+		store := MapLookuper{"database/creds#password": "s3cret", "DB_USER": "app"}
+		l := MapKeys(store, KeyMap(map[string]string{
+			"DB_PASSWORD": "database/creds#password",
+		}))
+
+		// Act
+		var in testObj
+		Process(&in, WithLookuper(MultiLookuper(MapLookuper{}, l)))
+
+		// Assert
+		assertEqual(t, in.Password, "s3cret")
+		assertEqual(t, in.User, "app")
+	})
+}
+
+func TestProcess_Sources(t *testing.T) {
+	type testObj struct {
+		Token string `env:"TOKEN,source=file,env"`
+		Host  string `env:"HOST,required,source=env"`
+		Port  string `env:"PORT"`
+	}
+	file := MapLookuper{"TOKEN": "from-file", "HOST": "from-file", "PORT": "from-file"}
+	env := MapLookuper{"TOKEN": "from-env", "HOST": "from-env"}
+
+	tRun(t, "sources are consulted in order", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["PORT"] = "from-default"
+
+		// Act
+		var in testObj
+		Process(&in, mockEnv(), WithSource("file", file), WithSource("env", env))
+
+		// Assert
+		assertEqual(t, in.Token, "from-file")
+		assertEqual(t, in.Host, "from-env")
+		assertEqual(t, in.Port, "from-default")
+	})
+
+	tRun(t, "later sources are fallbacks", func(t *testing.T) {
+		// Act
+		var in testObj
+		Process(&in, mockEnv(), WithSource("file", MapLookuper{}), WithSource("env", env))
+
+		// Assert
+		assertEqual(t, in.Token, "from-env")
+	})
+
+	tRun(t, "unknown sources are reported", func(t *testing.T) {
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv(), WithSource("env", env))
+
+		// Assert
+		assertErrorWithSubStr(t, err, `failed to look up env var "TOKEN": unknown source "file"`)
+	})
+}
+
+func TestParseTag_Sources(t *testing.T) {
+	tRun(t, "bare words after source continue the list", func(t *testing.T) {
+		// Act
+		tag, err := parseTag(`env:"KEY,source=a,b,required"`)
+
+		// Assert
+		assertEqual(t, err, nil)
+		assertEqual(t, slices.Equal(tag.sources, []string{"a", "b"}), true)
+		assertEqual(t, tag.required, true)
+	})
+
+	tRun(t, "flags end the list", func(t *testing.T) {
+		// Act
+		tag, err := parseTag(`env:"KEY,source=a,b,file,allowempty"`)
+
+		// Assert
+		assertEqual(t, err, nil)
+		assertEqual(t, slices.Equal(tag.sources, []string{"a", "b"}), true)
+		assertEqual(t, tag.file, true)
+		assertEqual(t, tag.allowEmpty, true)
+	})
+
+	tRun(t, "bare words after other attributes are rejected", func(t *testing.T) {
+		// Act
+		_, err := parseTag(`env:"KEY,source=a,required,b"`)
+
+		// Assert
+		assertErrorWithSubStr(t, err, `unrecognised struct tag attribute: "b"`)
+	})
+}
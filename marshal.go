@@ -0,0 +1,326 @@
+package envconf
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Marshal is the inverse of Process: it returns the environment variables
+// that, when processed, would populate a struct with the values held by `v`.
+//
+// The input `v` must be a struct or a pointer to a struct. Fields are
+// discovered exactly as Process discovers them; nil struct pointers are
+// skipped.
+//
+// Round-trip guarantee: for structs whose tagged fields are all of the basic
+// types supported by Process, processing the output of Marshal into a zero
+// value of the same type reproduces `v` (see RoundTripCheck). The guarantee
+// does not extend to:
+//
+//   - Empty strings in fields that declare a default, since an empty value is
+//     treated as unset and the default applied.
+//   - Nil struct pointers, since Process always allocates them.
+//   - Fields with the `file` attribute, whose variables name a file rather
+//     than hold its contents. Marshal omits them, so that the contents (often
+//     secrets) are never exported.
+//   - Fields with the `expand` attribute whose values contain "$", which
+//     Process would expand.
+//   - Fields with a `transform` (or `trim`, `lower` or `upper`) attribute,
+//     since Process transforms the marshalled value again.
+//   - Types implementing encoding.TextUnmarshaler or
+//     encoding.BinaryUnmarshaler, unless they also implement
+//     encoding.TextMarshaler or encoding.BinaryMarshaler as its inverse.
+//
+// Tags are read as configured by `opts` (see WithTagName), which should match
+// those later passed to Process.
+func Marshal(v any, opts ...Option) (map[string]string, error) {
+	return newOptions(opts).marshal(v, false)
+}
+
+// marshal implements Marshal and, if `redact` is set, Redacted, parsing tags
+// as configured (see WithTagName).
+func (o *options) marshal(v any, redact bool) (map[string]string, error) {
+	fields, err := o.collectFields(v)
+	if err != nil {
+		return nil, err
+	}
+
+	env := make(map[string]string, len(fields)+1)
+	if version, ok := schemaVersionOf(v); ok {
+		env[SchemaVersionKey] = version
+	}
+	for _, fi := range fields {
+		if !fi.Value.IsValid() {
+			continue // Nil struct pointer.
+		}
+		if fi.tag.file {
+			continue // The variable names a file, whose contents are not exported.
+		}
+
+		if fi.tag.indexed && fi.Value.Kind() == reflect.Slice {
+			if fi.tag.count != "" {
+				env[countKey(fi)] = strconv.Itoa(fi.Value.Len())
+			}
+			for i := 0; i < fi.Value.Len(); i++ {
+				if elem := fi.Value.Index(i); elem.Kind() == reflect.Struct &&
+					!o.isLeaf(elem.Type()) && fi.tag.format == "" {
+					sub, err := o.marshal(elem.Addr().Interface(), redact)
+					if err != nil {
+						return nil, fmt.Errorf("field %q: %w", fi.Field, err)
+					}
+					for k, val := range sub {
+						env[indexedKey(fi.Key, i)+"_"+k] = val
+					}
+					continue
+				}
+				val, ok := formatValue(fi.Value.Index(i), fi.tag)
+				if !ok {
+					return nil, fmt.Errorf("cannot marshal field %q of type %s",
+						fi.Field, fi.Type)
+				}
+				if redact {
+					val = fi.tag.redact(val)
+				}
+				env[indexedKey(fi.Key, i)] = val
+			}
+			continue
+		}
+
+		if fi.tag.prefixMap && fi.Value.Kind() == reflect.Map {
+			iter := fi.Value.MapRange()
+			for iter.Next() {
+				suffix, keyOK := formatValue(iter.Key(), fi.tag)
+				val, valOK := formatValue(iter.Value(), fi.tag)
+				if !keyOK || !valOK {
+					return nil, fmt.Errorf("cannot marshal field %q of type %s",
+						fi.Field, fi.Type)
+				}
+				if redact {
+					val = fi.tag.redact(val)
+				}
+				env[fi.Key+suffix] = val
+			}
+			continue
+		}
+
+		val, ok := formatValue(fi.Value, fi.tag)
+		if !ok {
+			return nil, fmt.Errorf("cannot marshal field %q of type %s",
+				fi.Field, fi.Type)
+		}
+		if redact {
+			val = fi.tag.redact(val)
+		}
+		env[fi.Key] = val
+	}
+
+	return env, nil
+}
+
+// formatBase returns the base in which integers are formatted for `tag`:
+// that of the `base` attribute, or 10 when it infers the base from a prefix.
+func formatBase(tag fieldTag) int {
+	if base := tag.intBase(); base != 0 {
+		return base
+	}
+	return 10
+}
+
+// formatValue formats `v` such that parsing the result according to v's type
+// and the field's `tag` yields `v` again. The boolean result reports whether
+// the type is supported.
+func formatValue(v reflect.Value, tag fieldTag) (string, bool) {
+	if format := documentFormat(v.Type(), tag); format != "" {
+		return encodeDocument(v, format)
+	}
+	if tag.uuid && v.Kind() == reflect.Array && isUUIDType(v.Type()) {
+		var u [16]byte
+		reflect.Copy(reflect.ValueOf(u[:]), v)
+		return formatUUID(u), true
+	}
+	if hasImplementations(v.Type()) {
+		return implementationName(v)
+	}
+	if isAtomicType(v.Type()) {
+		loaded, ok := loadAtomic(v)
+		if !ok {
+			return "", true
+		}
+		return formatValue(loaded, tag)
+	}
+	if v.Type() == timeType {
+		return formatTime(v.Interface().(time.Time), tag.layout), true
+	}
+	if v.Type() == reflect.PointerTo(locationType) {
+		if v.IsNil() {
+			return "", true
+		}
+		return v.Interface().(*time.Location).String(), true
+	}
+	if v.Type() == bytesType {
+		return encodeBytes(v.Bytes(), tag.encoding), true
+	}
+	if v.Type() == urlType {
+		u := v.Interface().(url.URL)
+		return u.String(), true
+	}
+	if v.Type() == ipNetType {
+		n := v.Interface().(net.IPNet)
+		return n.String(), true
+	}
+	if v.Type() == tcpAddrType {
+		a := v.Interface().(net.TCPAddr)
+		return a.String(), true
+	}
+	if v.Type() == fileModeType {
+		return formatFileMode(v.Interface().(fs.FileMode)), true
+	}
+	if v.Type() == mailAddressType {
+		a := v.Interface().(mail.Address)
+		return a.String(), true
+	}
+	if isBinaryField(v.Type(), tag) {
+		m, ok := marshaler[encoding.BinaryMarshaler](v)
+		if !ok {
+			return "", false
+		}
+		b, err := m.MarshalBinary()
+		if err != nil {
+			return "", false
+		}
+		return encodeBytes(b, tag.encoding), true
+	}
+	if m, ok := marshaler[encoding.TextMarshaler](v); ok {
+		b, err := m.MarshalText()
+		if err != nil {
+			return "", false
+		}
+		return string(b), true
+	}
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return "", true // Processed as unset, leaving the pointer nil.
+		}
+		return formatValue(v.Elem(), tag)
+	}
+	if v.Kind() == reflect.Slice || v.Kind() == reflect.Array {
+		parts := make([]string, v.Len())
+		for i := range parts {
+			part, ok := formatValue(v.Index(i), tag)
+			if !ok {
+				return "", false
+			}
+			parts[i] = part
+		}
+		return strings.Join(parts, tag.sep()), true
+	}
+	if v.Kind() == reflect.Map {
+		pairs := make([]string, 0, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			k, ok := formatValue(iter.Key(), tag)
+			if !ok {
+				return "", false
+			}
+			e, ok := formatValue(iter.Value(), tag)
+			if !ok {
+				return "", false
+			}
+			pairs = append(pairs, k+tag.kvSep()+e)
+		}
+		sort.Strings(pairs) // Deterministic output.
+		return strings.Join(pairs, tag.sep()), true
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return encodeBytes([]byte(v.String()), tag.encoding), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if tag.duration {
+			return time.Duration(v.Int()).String(), true
+		}
+		return strconv.FormatInt(v.Int(), formatBase(tag)), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), formatBase(tag)), true
+	case reflect.Float32:
+		return strconv.FormatFloat(v.Float(), 'g', -1, 32), true
+	case reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, 64), true
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), true
+	case reflect.Complex64:
+		return strconv.FormatComplex(v.Complex(), 'g', -1, 64), true
+	case reflect.Complex128:
+		return strconv.FormatComplex(v.Complex(), 'g', -1, 128), true
+	}
+
+	return "", false
+}
+
+// marshaler returns `v` (or, if addressable, a pointer to it) as an M (e.g.
+// encoding.TextMarshaler) if it implements the interface.
+func marshaler[M any](v reflect.Value) (M, bool) {
+	var zero M
+	if v.Kind() == reflect.Pointer && v.IsNil() {
+		return zero, false
+	}
+	if m, ok := v.Interface().(M); ok {
+		return m, true
+	}
+	if v.CanAddr() {
+		m, ok := v.Addr().Interface().(M)
+		return m, ok
+	}
+	return zero, false
+}
+
+// RoundTripCheck verifies the Marshal round-trip guarantee for `cfg`: it
+// marshals `cfg`, processes the result into a zero T and reports an error
+// naming the offending variables if the two differ. Any `opts` are passed to
+// Process (the lookuper is always replaced by the marshalled variables).
+func RoundTripCheck[T any](cfg T, opts ...Option) error {
+	o := newOptions(opts)
+	env, err := o.marshal(&cfg, false)
+	if err != nil {
+		return err
+	}
+
+	var got T
+	opts = append(opts[:len(opts):len(opts)], WithLookuper(MapLookuper(env)))
+	if err := ProcessE(&got, opts...); err != nil {
+		return fmt.Errorf("round trip failed: %w", err)
+	}
+
+	if reflect.DeepEqual(cfg, got) {
+		return nil
+	}
+
+	gotEnv, err := o.marshal(&got, false)
+	if err != nil {
+		return err
+	}
+
+	var diff []string
+	for k, v := range env {
+		if gotEnv[k] != v {
+			diff = append(diff, fmt.Sprintf("%s: %q != %q", k, v, gotEnv[k]))
+		}
+	}
+	sort.Strings(diff)
+	if len(diff) == 0 {
+		return errors.New("round trip mismatch")
+	}
+
+	return fmt.Errorf("round trip mismatch: %s", strings.Join(diff, ", "))
+}
@@ -0,0 +1,147 @@
+package envconf
+
+import (
+	"math"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestMarshal(t *testing.T) {
+	tRun(t, "formats tagged fields", func(t *testing.T) {
+		// Arrange
+		type testObj struct {
+			Host   string `env:"HOST"`
+			Port   int    `env:"PORT"`
+			Nested struct {
+				Debug bool `env:"DEBUG"`
+			}
+			Untagged string
+		}
+		in := testObj{Host: "localhost", Port: 8080}
+		in.Nested.Debug = true
+
+		// Act
+		env, err := Marshal(in)
+
+		// Assert
+		assertEqual(t, err, nil)
+		assertEqual(t, len(env), 3)
+		assertEqual(t, env["HOST"], "localhost")
+		assertEqual(t, env["PORT"], "8080")
+		assertEqual(t, env["DEBUG"], "true")
+	})
+
+	tRun(t, "file fields are not exported", func(t *testing.T) {
+		// Arrange
+		type testObj struct {
+			User     string `env:"DB_USER"`
+			Password string `env:"DB_PASSWORD_FILE,file,default=/run/secrets/db"`
+		}
+		in := testObj{User: "app", Password: "s3cret"}
+
+		// Act
+		env, err := Marshal(in)
+		var dotEnv strings.Builder
+		dotEnvErr := WriteDotEnv(&dotEnv, in)
+
+		// Assert
+		assertEqual(t, err, nil)
+		assertEqual(t, len(env), 1)
+		assertEqual(t, env["DB_USER"], "app")
+		assertEqual(t, dotEnvErr, nil)
+		assertEqual(t, dotEnv.String(), "DB_USER=app\nDB_PASSWORD_FILE=/run/secrets/db\n")
+	})
+
+	tRun(t, "struct values use pointer receiver marshalers", func(t *testing.T) {
+		// Arrange
+		type limit struct {
+			Max big.Int `env:"MAX"`
+		}
+		type testObj struct {
+			N      big.Int `env:"N"`
+			Limits []limit `env:"LIMIT,indexed"`
+		}
+		var in testObj
+		in.N.SetInt64(42)
+		in.Limits = make([]limit, 1)
+		in.Limits[0].Max.SetInt64(7)
+
+		// Act
+		env, err := Marshal(in)
+		redacted, redactErr := Redacted(in)
+
+		// Assert
+		assertEqual(t, err, nil)
+		assertEqual(t, env["N"], "42")
+		assertEqual(t, env["LIMIT_0_MAX"], "7")
+		assertEqual(t, redactErr, nil)
+		assertEqual(t, redacted["N"], "42")
+	})
+
+	tRun(t, "non struct errors", func(t *testing.T) {
+		// Act
+		_, err := Marshal(42)
+
+		// Assert
+		assertErrorWithSubStr(t, err, "expected struct or pointer to struct")
+	})
+}
+
+func TestRoundTripCheck(t *testing.T) {
+	tRun(t, "every basic type round trips", func(t *testing.T) {
+		// Arrange
+		type testObj struct {
+			String     string     `env:"STRING"`
+			Bool       bool       `env:"BOOL"`
+			Int        int        `env:"INT"`
+			Int8       int8       `env:"INT8"`
+			Int16      int16      `env:"INT16"`
+			Int32      int32      `env:"INT32"`
+			Int64      int64      `env:"INT64"`
+			Uint       uint       `env:"UINT"`
+			Uint8      uint8      `env:"UINT8"`
+			Uint16     uint16     `env:"UINT16"`
+			Uint32     uint32     `env:"UINT32"`
+			Uint64     uint64     `env:"UINT64"`
+			Float32    float32    `env:"FLOAT32"`
+			Float64    float64    `env:"FLOAT64"`
+			Complex64  complex64  `env:"COMPLEX64"`
+			Complex128 complex128 `env:"COMPLEX128"`
+			Nested     *struct {
+				String string `env:"NESTED_STRING,default=x"`
+			}
+		}
+		in := testObj{
+			String: "a,b=c", Bool: true,
+			Int: math.MinInt, Int8: math.MinInt8, Int16: math.MaxInt16,
+			Int32: math.MinInt32, Int64: math.MaxInt64,
+			Uint: math.MaxUint, Uint8: math.MaxUint8, Uint16: math.MaxUint16,
+			Uint32: math.MaxUint32, Uint64: math.MaxUint64,
+			Float32: math.SmallestNonzeroFloat32, Float64: math.Pi,
+			Complex64: complex(1.5, -2), Complex128: complex(math.E, math.Pi),
+		}
+		in.Nested = &struct {
+			String string `env:"NESTED_STRING,default=x"`
+		}{String: "y"}
+
+		// Act
+		err := RoundTripCheck(in)
+
+		// Assert
+		assertEqual(t, err, nil)
+	})
+
+	tRun(t, "empty strings with defaults are reported", func(t *testing.T) {
+		// Arrange
+		type testObj struct {
+			Host string `env:"HOST,default=localhost"`
+		}
+
+		// Act
+		err := RoundTripCheck(testObj{})
+
+		// Assert
+		assertErrorWithSubStr(t, err, `HOST: "" != "localhost"`)
+	})
+}
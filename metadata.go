@@ -0,0 +1,57 @@
+package envconf
+
+import (
+	"path"
+	"reflect"
+	"runtime/debug"
+)
+
+// Makes unit testing easier.
+var readBuildInfo = debug.ReadBuildInfo
+
+var metadataType = reflect.TypeOf(Metadata{})
+
+// Metadata holds conventional service metadata useful for observability
+// (logging, tracing, metrics labels, etc.).
+//
+// Metadata may be embedded in, or be a field of, any struct passed to Process.
+// Its fields are first populated from the environment variables named in their
+// tags; any field still empty afterwards is populated from the build
+// information embedded in the running binary (see debug.ReadBuildInfo):
+//
+//   - ServiceName - the last element of the main package path.
+//   - Version - the main module version (unless it is "(devel)").
+//   - GitSHA - the "vcs.revision" build setting.
+//   - BuildDate - the "vcs.time" build setting.
+type Metadata struct {
+	ServiceName string `env:"SERVICE_NAME"`
+	Version     string `env:"SERVICE_VERSION"`
+	GitSHA      string `env:"GIT_SHA"`
+	BuildDate   string `env:"BUILD_DATE"`
+}
+
+// fillFromBuildInfo populates any empty fields of `m` from the build
+// information embedded in the running binary. Fields that already hold a value
+// are left untouched.
+func (m *Metadata) fillFromBuildInfo() {
+	bi, ok := readBuildInfo()
+	if !ok {
+		return
+	}
+
+	if m.ServiceName == "" && bi.Path != "" {
+		m.ServiceName = path.Base(bi.Path)
+	}
+	if m.Version == "" && bi.Main.Version != "(devel)" {
+		m.Version = bi.Main.Version
+	}
+
+	for _, s := range bi.Settings {
+		switch {
+		case s.Key == "vcs.revision" && m.GitSHA == "":
+			m.GitSHA = s.Value
+		case s.Key == "vcs.time" && m.BuildDate == "":
+			m.BuildDate = s.Value
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package envconf
+
+import (
+	"runtime/debug"
+	"testing"
+)
+
+func TestProcess_Metadata(t *testing.T) {
+	// Pre Arrange
+	type testObj struct {
+		Metadata
+		Port string `env:"PORT"`
+	}
+
+	defer func(f func() (*debug.BuildInfo, bool)) { readBuildInfo = f }(readBuildInfo)
+	readBuildInfo = func() (*debug.BuildInfo, bool) {
+		return &debug.BuildInfo{
+			Path: "github.com/example/svc/cmd/api",
+			Main: debug.Module{Version: "v1.2.3"},
+			Settings: []debug.BuildSetting{
+				{Key: "vcs.revision", Value: "abc123"},
+				{Key: "vcs.time", Value: "2024-01-02T03:04:05Z"},
+			},
+		}, true
+	}
+
+	tRun(t, "unset fields are populated from build info", func(t *testing.T) {
+		// Act
+		var in testObj
+		Process(&in, mockEnv())
+
+		// Assert
+		assertEqual(t, in.ServiceName, "api")
+		assertEqual(t, in.Version, "v1.2.3")
+		assertEqual(t, in.GitSHA, "abc123")
+		assertEqual(t, in.BuildDate, "2024-01-02T03:04:05Z")
+	})
+
+	tRun(t, "environment values take precedence over build info", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["SERVICE_NAME"] = "billing"
+		mockEnvVarMap["GIT_SHA"] = "def456"
+
+		// Act
+		var in testObj
+		Process(&in, mockEnv())
+
+		// Assert
+		assertEqual(t, in.ServiceName, "billing")
+		assertEqual(t, in.Version, "v1.2.3")
+		assertEqual(t, in.GitSHA, "def456")
+	})
+
+	tRun(t, "devel version is ignored", func(t *testing.T) {
+		// Arrange
+		readBuildInfo = func() (*debug.BuildInfo, bool) {
+			return &debug.BuildInfo{Main: debug.Module{Version: "(devel)"}}, true
+		}
+
+		// Act
+		var in testObj
+		Process(&in, mockEnv())
+
+		// Assert
+		assertEqual(t, in.Version, "")
+	})
+}
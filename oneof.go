@@ -0,0 +1,92 @@
+package envconf
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// isOneOfKind reports whether the `oneof` attribute applies to values of
+// type `t`: those of the predeclared string, boolean and numeric kinds,
+// including named types such as time.Duration.
+func isOneOfKind(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// setOneOf parses `val` into `fv` as setField does, provided the result
+// equals one of the values permitted by the `oneof` attribute, each parsed
+// the same way (so "1.0" matches "1" for a float field). `fv` is left
+// untouched otherwise.
+func setOneOf(fv reflect.Value, val string, tag fieldTag) error {
+	allowed := tag.oneOf
+	tag.oneOf = nil
+
+	v := reflect.New(fv.Type()).Elem()
+	if err := setField(v, val, tag); err != nil {
+		return err
+	}
+	for _, a := range allowed {
+		av := reflect.New(fv.Type()).Elem()
+		if err := setField(av, a, tag); err != nil {
+			return fmt.Errorf("invalid %s struct tag attribute: %q: %w", tagAttrOneOf, a, err)
+		}
+		if av.Interface() == v.Interface() {
+			fv.Set(v)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%q is not one of %s", val, strings.Join(allowed, ", "))
+}
+
+// checkConstraintArgs reports an error if any argument of the `oneof`, `min`
+// or `max` attributes of a field of type `t` cannot be parsed as a value of
+// the type it restricts: `t` itself or, for pointers, slices, arrays and
+// maps, that of their elements. This surfaces malformed tags even when the
+// variable is unset.
+func checkConstraintArgs(t reflect.Type, tag fieldTag) error {
+	if tag.oneOf == nil && tag.min == "" && tag.max == "" {
+		return nil
+	}
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice ||
+		t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+		t = t.Elem()
+	}
+
+	check := func(attr, arg string) error {
+		plain := tag
+		plain.oneOf, plain.min, plain.max = nil, "", ""
+		if err := setField(reflect.New(t).Elem(), arg, plain); err != nil {
+			return fmt.Errorf("invalid %s struct tag attribute: %q: %w", attr, arg, err)
+		}
+		return nil
+	}
+	if isOneOfKind(t) {
+		for _, a := range tag.oneOf {
+			if err := check(tagAttrOneOf, a); err != nil {
+				return err
+			}
+		}
+	}
+	if isNumericKind(t) {
+		if tag.min != "" {
+			if err := check(tagAttrMin, tag.min); err != nil {
+				return err
+			}
+		}
+		if tag.max != "" {
+			if err := check(tagAttrMax, tag.max); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,77 @@
+package envconf
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestProcess_OneOf(t *testing.T) {
+	type testObj struct {
+		Level    string            `env:"LOG_LEVEL,default=info,oneof=debug|info|warn|error"`
+		Replicas int               `env:"REPLICAS,oneof=1|3|5"`
+		Ratio    float64           `env:"RATIO,oneof=0.5|1"`
+		Timeout  time.Duration     `env:"TIMEOUT,duration,oneof=30s|1m"`
+		Regions  []string          `env:"REGIONS,oneof=eu|us|ap"`
+		Weights  map[string]uint16 `env:"WEIGHTS,oneof=0|50|100"`
+	}
+
+	tRun(t, "permitted values are accepted", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["REPLICAS"] = "3"
+		mockEnvVarMap["RATIO"] = "1.0"
+		mockEnvVarMap["TIMEOUT"] = "60s"
+		mockEnvVarMap["REGIONS"] = "eu,ap"
+		mockEnvVarMap["WEIGHTS"] = "canary:50,stable:100"
+
+		// Act
+		var in testObj
+		Process(&in, mockEnv())
+
+		// Assert
+		assertEqual(t, in.Level, "info")
+		assertEqual(t, in.Replicas, 3)
+		assertEqual(t, in.Ratio, 1.0)
+		assertEqual(t, in.Timeout, time.Minute)
+		assertEqual(t, in.Regions[1], "ap")
+		assertEqual(t, in.Weights["canary"], uint16(50))
+	})
+
+	tRun(t, "other values are reported with the permitted set", func(t *testing.T) {
+		tests := map[string][2]string{
+			"LOG_LEVEL": {"verbose", `"verbose" is not one of debug, info, warn, error`},
+			"REPLICAS":  {"2", `"2" is not one of 1, 3, 5`},
+			"REGIONS":   {"eu,sa", `element 1: "sa" is not one of eu, us, ap`},
+			"WEIGHTS":   {"a:10", `invalid uint16 value: "10" is not one of 0, 50, 100`},
+		}
+		for key, tt := range tests {
+			// Arrange
+			mockEnvVarMap[key] = tt[0]
+
+			// Act
+			var in testObj
+			err := ProcessE(&in, mockEnv())
+			delete(mockEnvVarMap, key)
+
+			// Assert
+			var pe *ParseError
+			assertEqual(t, errors.As(err, &pe), true)
+			assertEqual(t, pe.Var, key)
+			assertErrorWithSubStr(t, pe.Err, tt[1])
+		}
+	})
+
+	tRun(t, "malformed permitted values are reported even when unset", func(t *testing.T) {
+		// Arrange
+		var in struct {
+			N int `env:"N,oneof=1|two"`
+		}
+
+		// Act
+		err := ProcessE(&in, mockEnv())
+
+		// Assert
+		assertEqual(t, errors.Is(err, ErrParse), false)
+		assertErrorWithSubStr(t, err, `field "N": invalid oneof struct tag attribute: "two"`)
+	})
+}
@@ -0,0 +1,377 @@
+package envconf
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"reflect"
+)
+
+// Option configures the behaviour of Process.
+type Option func(*options)
+
+// options holds the configuration assembled from the Option values supplied
+// to Process.
+type options struct {
+	ctx                context.Context
+	lookuper           Lookuper
+	resolvers          map[string]Resolver
+	profileLabels      bool
+	safe               bool
+	overwrite          OverwritePolicy
+	maxLength          int
+	maxDecoded         int
+	fileSystem         fs.FS
+	snapshot           bool
+	scanners           []SecretScanner
+	warnSecret         func(SecretWarning)
+	warnDeprecated     func(DeprecationWarning)
+	keyRecorder        func(field, key string)
+	ignoreUnknownAttrs bool
+	tagName            string
+	groups             *fieldGroups // Set while processing.
+	stopTypes          map[reflect.Type]bool
+	diagnostics        bool
+	validating         bool // Set by Validate, which leaves the environment untouched.
+	windowsExpansion   bool
+	constraints        []string
+	sources            map[string]Lookuper
+	schemaCheck        bool
+	keyPrefix          string // Prepended to variable names, e.g. UPSTREAM_0_.
+}
+
+// newOptions returns the default options with each of `opts` applied in
+// order.
+func newOptions(opts []Option) *options {
+	o := &options{
+		ctx:      context.Background(),
+		lookuper: OSLookuper{},
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.snapshot {
+		env := Snapshot()
+		o.lookuper = snapshotLookuper(o.lookuper, env)
+		for name, l := range o.sources {
+			o.sources[name] = snapshotLookuper(l, env)
+		}
+	}
+
+	return o
+}
+
+// WithLookuper sets the Lookuper used to resolve variable values, allowing
+// applications and tests to supply alternate value sources. By default values
+// are read from the process environment (see OSLookuper).
+func WithLookuper(l Lookuper) Option {
+	return func(o *options) {
+		o.lookuper = l
+	}
+}
+
+// WithLayers processes the struct against `layers` stacked in order, each
+// overriding the ones before it (e.g. a base file, then an environment
+// specific file, then the process environment): each variable is looked up
+// in the layers in reverse order, so later layers take precedence. It
+// replaces any Lookuper set by WithLookuper, and vice versa.
+func WithLayers(layers ...Lookuper) Option {
+	stack := make(multiLookuper, len(layers))
+	for i, l := range layers {
+		stack[len(layers)-1-i] = l
+	}
+	return WithLookuper(stack)
+}
+
+// WithSnapshot captures the process environment once, when the call starts,
+// and answers every lookup the configured Lookuper would have made of the
+// process environment (see OSLookuper) from that snapshot. Concurrent changes
+// to the environment (e.g. by tests or other libraries calling os.Setenv)
+// therefore cannot produce a configuration mixing old and new values.
+func WithSnapshot() Option {
+	return func(o *options) {
+		o.snapshot = true
+	}
+}
+
+// WithStopTypes prevents Process from recursing into struct fields of any of
+// `types` (or pointers to them), treating them as leaves instead. Use it for
+// types whose exported fields are implementation details, such as sync.Mutex
+// or protobuf-generated messages. Untagged fields of a stop type are left
+// untouched, and are likewise skipped by Marshal, Fields and the generators.
+// time.Time is always a leaf.
+func WithStopTypes(types ...reflect.Type) Option {
+	return func(o *options) {
+		if o.stopTypes == nil {
+			o.stopTypes = make(map[reflect.Type]bool, len(types))
+		}
+		for _, t := range types {
+			o.stopTypes[t] = true
+		}
+	}
+}
+
+// isLeaf reports whether the struct type `t` must not be recursed into.
+func (o *options) isLeaf(t reflect.Type) bool {
+	return isLeafType(t) || o.stopTypes[t]
+}
+
+// WithSource registers `l` as the source called `name`, for use by fields
+// with the `source` attribute. Such fields consult the listed sources in
+// order instead of the configured Lookuper, expressing an explicit fallback
+// chain for special cases such as bootstrap credentials:
+//
+//	type Config struct {
+//		VaultToken string `env:"VAULT_TOKEN,source=file,env"`
+//	}
+//
+//	envconf.Process(&cfg,
+//		envconf.WithSource("env", envconf.OSLookuper{}),
+//		envconf.WithSource("file", envconf.DirLookuper{FS: os.DirFS("/run/secrets")}),
+//	)
+func WithSource(name string, l Lookuper) Option {
+	return func(o *options) {
+		if o.sources == nil {
+			o.sources = make(map[string]Lookuper)
+		}
+		o.sources[name] = l
+	}
+}
+
+// lookupField retrieves `key` from the sources listed by the field's `source`
+// attribute or, if there are none, from the configured Lookuper.
+func (o *options) lookupField(key string, tag fieldTag) (string, bool, error) {
+	if len(tag.sources) == 0 {
+		return o.lookup(key)
+	}
+
+	for _, name := range tag.sources {
+		l, ok := o.sources[name]
+		if !ok {
+			return "", false, fmt.Errorf("unknown source %q", name)
+		}
+		if val, ok, err := o.lookupIn(l, key); err != nil || ok {
+			return val, ok, err
+		}
+	}
+
+	return "", false, nil
+}
+
+// WithResolver registers `r` to resolve values of the form "scheme://...",
+// allowing configuration to hold secret references rather than raw secrets.
+// For example:
+//
+//	envconf.Process(&cfg,
+//		envconf.WithResolver("op", envconf.OnePasswordResolver{}),
+//		envconf.WithResolver("doppler", envconf.DopplerResolver{}),
+//	)
+//
+// Values (including defaults) whose scheme has no registered resolver are
+// used as is.
+func WithResolver(scheme string, r Resolver) Option {
+	return func(o *options) {
+		if o.resolvers == nil {
+			o.resolvers = make(map[string]Resolver)
+		}
+		o.resolvers[scheme] = r
+	}
+}
+
+// WithProfilerLabels enables pprof labels (see runtime/pprof.Do) while
+// processing, so that CPU profiles of slow startups attribute time to specific
+// configuration sections and sources. The following labels are applied:
+//
+//   - envconf_section - the name of the top-level struct field being
+//     processed (only set for struct-typed fields).
+//   - envconf_source - the type of the Lookuper (each member of a
+//     MultiLookuper is labelled individually) or Resolver being consulted.
+func WithProfilerLabels() Option {
+	return func(o *options) {
+		o.profileLabels = true
+	}
+}
+
+// OverwritePolicy controls how Process treats fields that already hold a
+// non-zero value, which matters when the same struct is processed more than
+// once (e.g. first against a defaults source, then against the environment).
+type OverwritePolicy int
+
+const (
+	// OverwriteAlways assigns every field for which the source provides a
+	// value or a default is declared, regardless of its current value. Fields
+	// with neither are left untouched. This is the default policy.
+	OverwriteAlways OverwritePolicy = iota
+
+	// OverwriteIfSet replaces a non-zero field only when the source provides a
+	// value; defaults are applied to zero fields only. A non-zero field
+	// satisfies the `required` attribute.
+	OverwriteIfSet
+
+	// OverwriteNever assigns zero fields only; non-zero fields are never
+	// replaced and satisfy the `required` attribute.
+	OverwriteNever
+)
+
+// WithOverwrite selects the OverwritePolicy used for the call.
+func WithOverwrite(p OverwritePolicy) Option {
+	return func(o *options) {
+		o.overwrite = p
+	}
+}
+
+// WithFillOnly restricts Process to fields that currently hold their zero
+// value. Callers may pre-populate a struct with programmatic overrides and then
+// layer environment values (and defaults) underneath without them being
+// clobbered. It is shorthand for WithOverwrite(OverwriteNever).
+func WithFillOnly() Option {
+	return WithOverwrite(OverwriteNever)
+}
+
+// WithMaxValueLength rejects any value longer than `n` bytes, whether it comes
+// from the source, a default, a resolved secret reference, a file (see the
+// `file` attribute) or decompression (see the `transform` attribute),
+// protecting services from pathological values injected by mistake. A limit
+// of zero (the default) disables the check.
+func WithMaxValueLength(n int) Option {
+	return func(o *options) {
+		o.maxLength = n
+	}
+}
+
+// WithFileSystem opens the files named by fields with the `file` attribute
+// in `fsys` rather than the host file system, sandboxing reads to a directory
+// where paths may come from untrusted input (e.g. tenant configuration):
+//
+//	envconf.Process(&cfg, envconf.WithFileSystem(os.DirFS("/run/secrets")))
+//
+// Paths are then relative to the root of `fsys` and must be valid fs.FS paths
+// (see fs.ValidPath); where symlinks must not escape the directory either,
+// use the fs.FS of an os.Root.
+func WithFileSystem(fsys fs.FS) Option {
+	return func(o *options) {
+		o.fileSystem = fsys
+	}
+}
+
+// WithMaxDecodedSize rejects any value whose decoded form (e.g. []byte fields
+// with a base64 or hex encoding) would exceed `n` bytes. The check is made
+// before decoding. A limit of zero (the default) disables the check.
+func WithMaxDecodedSize(n int) Option {
+	return func(o *options) {
+		o.maxDecoded = n
+	}
+}
+
+// WithSecretScanners runs every resolved value assigned to a field not marked
+// `secret` through `scanners`, calling `warn` for each value that looks like
+// a secret (e.g. a PEM private key or an AWS access key ID). This catches
+// secrets that have landed in ordinary fields before they are logged or
+// exported unredacted. Warnings do not cause processing to fail. If no
+// scanners are supplied DefaultSecretScanners is used.
+func WithSecretScanners(warn func(SecretWarning), scanners ...SecretScanner) Option {
+	if len(scanners) == 0 {
+		scanners = DefaultSecretScanners()
+	}
+	return func(o *options) {
+		o.scanners = scanners
+		o.warnSecret = warn
+	}
+}
+
+// WithDeprecationHandler calls `warn`, instead of logging with slog.Warn, for
+// every value read from a variable marked with the `deprecated` attribute.
+// Warnings do not cause processing to fail.
+func WithDeprecationHandler(warn func(DeprecationWarning)) Option {
+	return func(o *options) {
+		o.warnDeprecated = warn
+	}
+}
+
+// WithKeyRecorder calls `record` with the path of every field populated from
+// a variable (rather than a default) and the name of that variable, revealing
+// which of a field's aliases (see the `alias` attribute) or deprecated names
+// supplied its value:
+//
+//	used := make(map[string]string)
+//	envconf.Process(&cfg, envconf.WithKeyRecorder(func(field, key string) {
+//		used[field] = key
+//	}))
+func WithKeyRecorder(record func(field, key string)) Option {
+	return func(o *options) {
+		o.keyRecorder = record
+	}
+}
+
+// WithDiagnostics enables a read-only diagnostics mode in which secret stores
+// are never consulted: fields marked `secret` are not looked up and are left
+// untouched (a `required` secret is not reported as missing), and values
+// referring to a registered Resolver (see WithResolver) are not resolved,
+// leaving their fields untouched. Everything else is processed as usual, so
+// diagnostic commands (e.g. `myapp config show`) can run without access to
+// the secret backends.
+func WithDiagnostics() Option {
+	return func(o *options) {
+		o.diagnostics = true
+	}
+}
+
+// WithTagName reads field configuration from struct tags with the key `name`
+// rather than "env", e.g. `config:"PORT,default=8080"` with
+// WithTagName("config"), so that envconf can coexist with other libraries
+// reading `env` tags or follow an organisation's existing conventions. It
+// applies wherever Options are accepted, such as Process, Validate,
+// RoundTripCheck, Marshal, Redacted, Fields, Usage and the Write generators
+// (e.g. WriteDotEnv).
+func WithTagName(name string) Option {
+	return func(o *options) {
+		o.tagName = name
+	}
+}
+
+// tagKey returns the key of the struct tags holding field configuration.
+func (o *options) tagKey() string {
+	if o.tagName != "" {
+		return o.tagName
+	}
+	return tagKey
+}
+
+// WithIgnoreUnknownAttributes skips unrecognised struct tag attributes
+// rather than reporting them as errors, so that structs whose `env` tags are
+// shared with other libraries can be processed. Misused attributes (e.g. an
+// unknown encoding) are still reported. Tags are parsed strictly by default.
+// The option applies equally to the generators (e.g. Usage), Marshal and
+// Fields when passed to them.
+func WithIgnoreUnknownAttributes() Option {
+	return func(o *options) {
+		o.ignoreUnknownAttrs = true
+	}
+}
+
+// WithWindowsExpansion additionally recognises the %VAR% syntax of Windows
+// batch files in fields with the `expand` attribute, so values copied from
+// batch files and Windows service definitions resolve as operators expect.
+// As in cmd.exe, references to unset variables are left as is and %% yields
+// a literal percent sign. It is typically enabled only on Windows:
+//
+//	if runtime.GOOS == "windows" {
+//		opts = append(opts, envconf.WithWindowsExpansion())
+//	}
+func WithWindowsExpansion() Option {
+	return func(o *options) {
+		o.windowsExpansion = true
+	}
+}
+
+// Safe guarantees that processing never panics: any panic raised while
+// processing, whether by envconf itself (e.g. a programmer error in the target
+// struct) or by a third-party Lookuper or Resolver, is recovered and returned
+// as a *PanicError. It is intended for hosts processing untrusted structs,
+// such as plugin systems, in combination with ProcessE, ProcessContext or
+// Load (Process still panics when an error is returned).
+func Safe() Option {
+	return func(o *options) {
+		o.safe = true
+	}
+}
@@ -0,0 +1,775 @@
+package envconf
+
+import (
+	"encoding"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"math/big"
+	"net"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Setter is implemented by types that parse their own environment variable
+// values, such as domain enums:
+//
+//	func (f *LogFormat) SetEnvValue(val string) error {
+//		switch val {
+//		case "json", "text":
+//			*f = LogFormat(val)
+//			return nil
+//		}
+//		return fmt.Errorf("unknown log format %q", val)
+//	}
+//
+// Process calls SetEnvValue in preference to any other form of parsing,
+// including encoding.TextUnmarshaler, other than a parser registered with
+// RegisterParser.
+type Setter interface {
+	SetEnvValue(val string) error
+}
+
+var (
+	setterType            = reflect.TypeOf((*Setter)(nil)).Elem()
+	timeType              = reflect.TypeOf(time.Time{})
+	locationType          = reflect.TypeOf(time.Location{})
+	bytesType             = reflect.TypeOf([]byte(nil))
+	rawMessageType        = reflect.TypeOf(json.RawMessage(nil))
+	urlType               = reflect.TypeOf(url.URL{})
+	ipNetType             = reflect.TypeOf(net.IPNet{})
+	tcpAddrType           = reflect.TypeOf(net.TCPAddr{})
+	bigFloatType          = reflect.TypeOf(big.Float{})
+	slogLevelType         = reflect.TypeOf(slog.Level(0))
+	mailAddressType       = reflect.TypeOf(mail.Address{})
+	fileModeType          = reflect.TypeOf(fs.FileMode(0))
+	textUnmarshalerType   = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	binaryUnmarshalerType = reflect.TypeOf((*encoding.BinaryUnmarshaler)(nil)).Elem()
+)
+
+// byteEncodings maps the encodings accepted by the `encoding` attribute to
+// their decoders. A nil decoder means the value is used as is.
+var byteEncodings = map[string]func(string) ([]byte, error){
+	"raw": nil,
+	"hex": hex.DecodeString,
+	"base64": func(s string) ([]byte, error) {
+		// Accept both padded and unpadded input.
+		return base64.RawStdEncoding.DecodeString(strings.TrimRight(s, "="))
+	},
+	"base64url": func(s string) ([]byte, error) {
+		return base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+	},
+}
+
+// timeLayouts are tried in order when parsing time.Time values. Layouts
+// without a zone offset are interpreted in the field's `tz` location (UTC by
+// default).
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02",
+}
+
+// isLeafType reports whether the type `t` is parsed from a single value as a
+// whole, rather than recursed into (structs) or split into elements (slices,
+// arrays and maps).
+func isLeafType(t reflect.Type) bool {
+	if _, ok := registeredParser(t); ok {
+		return true
+	}
+	return t == timeType || t == locationType || t == urlType || t == ipNetType || t == tcpAddrType ||
+		t == mailAddressType || t == fileModeType || t == rawMessageType ||
+		isAtomicType(t) || hasImplementations(t) ||
+		reflect.PointerTo(t).Implements(setterType) ||
+		reflect.PointerTo(t).Implements(textUnmarshalerType) ||
+		reflect.PointerTo(t).Implements(binaryUnmarshalerType)
+}
+
+// isBinaryField reports whether values of type `t` are decoded (see the
+// `encoding` attribute) and passed to UnmarshalBinary: always for types
+// implementing only encoding.BinaryUnmarshaler and, for those also
+// implementing encoding.TextUnmarshaler, only when an encoding is given.
+func isBinaryField(t reflect.Type, tag fieldTag) bool {
+	if _, ok := registeredParser(t); ok {
+		return false
+	}
+	pt := reflect.PointerTo(t)
+	return t != timeType && t != urlType && !pt.Implements(setterType) &&
+		pt.Implements(binaryUnmarshalerType) &&
+		(tag.encoding != "" || !pt.Implements(textUnmarshalerType))
+}
+
+// typeName returns the name used to describe values of type `t` in error
+// messages: the kind for basic types and the type itself for structs.
+func typeName(t reflect.Type) string {
+	if t == bytesType {
+		return "[]byte"
+	}
+	if t == rawMessageType {
+		return "json.RawMessage" // Possibly an alias of jsontext.Value.
+	}
+	if isLeafType(t) || t.Kind() == reflect.Struct {
+		return t.String()
+	}
+	if t.Kind() == reflect.Pointer {
+		return "*" + typeName(t.Elem())
+	}
+	if t.Kind() == reflect.Slice {
+		return "[]" + typeName(t.Elem())
+	}
+	if t.Kind() == reflect.Array {
+		return "[" + strconv.Itoa(t.Len()) + "]" + typeName(t.Elem())
+	}
+	if t.Kind() == reflect.Map {
+		return "map[" + typeName(t.Key()) + "]" + typeName(t.Elem())
+	}
+	return t.Kind().String()
+}
+
+// setField converts `val` according to the type of `fv` and assigns it. `fv`
+// is left untouched if the conversion fails.
+func setField(fv reflect.Value, val string, tag fieldTag) error {
+	if format := documentFormat(fv.Type(), tag); format != "" {
+		return decodeDocument(fv, val, format)
+	}
+	if tag.oneOf != nil && isOneOfKind(fv.Type()) {
+		return setOneOf(fv, val, tag)
+	}
+	if (tag.min != "" || tag.max != "") && isNumericKind(fv.Type()) {
+		return setBounded(fv, val, tag)
+	}
+	if tag.uuid && isUUIDType(fv.Type()) {
+		return setUUID(fv, val)
+	}
+	if tag.versions != nil && (fv.Type() == semVerType || fv.Kind() == reflect.String) {
+		return setSemVer(fv, val, tag.versions)
+	}
+	if hasImplementations(fv.Type()) {
+		return setImplementation(fv, val)
+	}
+	if isAtomicType(fv.Type()) {
+		return setAtomic(fv, val, tag)
+	}
+	if parse, ok := registeredParser(fv.Type()); ok {
+		v, err := parse(val)
+		if err != nil {
+			return err
+		}
+		fv.Set(v)
+		return nil
+	}
+	if s, ok := reflect.New(fv.Type()).Interface().(Setter); ok {
+		if err := s.SetEnvValue(val); err != nil {
+			return err
+		}
+		fv.Set(reflect.ValueOf(s).Elem())
+		return nil
+	}
+	if fv.Type() == timeType {
+		t, err := parseTime(val, tag.layout, tag.loc)
+		if err != nil {
+			return err
+		}
+		fv.Set(reflect.ValueOf(t))
+		return nil
+	}
+	if fv.Type() == reflect.PointerTo(locationType) {
+		loc, err := loadLocation(val)
+		if err != nil {
+			return err
+		}
+		fv.Set(reflect.ValueOf(loc))
+		return nil
+	}
+	if fv.Type() == bytesType {
+		b, err := decodeBytes(val, tag.encoding)
+		if err != nil {
+			return err
+		}
+		fv.SetBytes(b)
+		return nil
+	}
+	if fv.Type() == urlType {
+		u, err := parseURL(val, tag.urlKind)
+		if err != nil {
+			return err
+		}
+		fv.Set(reflect.ValueOf(*u))
+		return nil
+	}
+	if fv.Type() == ipNetType {
+		_, n, err := net.ParseCIDR(val)
+		if err != nil {
+			return err
+		}
+		fv.Set(reflect.ValueOf(*n))
+		return nil
+	}
+	if fv.Type() == tcpAddrType {
+		a, err := parseTCPAddr(val)
+		if err != nil {
+			return err
+		}
+		fv.Set(reflect.ValueOf(a))
+		return nil
+	}
+	if fv.Type() == mailAddressType {
+		a, err := mail.ParseAddress(val)
+		if err != nil {
+			return err
+		}
+		fv.Set(reflect.ValueOf(*a))
+		return nil
+	}
+	if fv.Type() == fileModeType {
+		m, err := parseFileMode(val)
+		if err != nil {
+			return err
+		}
+		fv.Set(reflect.ValueOf(m))
+		return nil
+	}
+	if fv.Type() == slogLevelType {
+		l, err := parseLevel(val)
+		if err != nil {
+			return err
+		}
+		fv.Set(reflect.ValueOf(l))
+		return nil
+	}
+	if fv.Type() == bigFloatType {
+		f, err := parseBigFloat(val)
+		if err != nil {
+			return err
+		}
+		fv.Set(reflect.ValueOf(f).Elem())
+		return nil
+	}
+	if isBinaryField(fv.Type(), tag) {
+		b, err := decodeBytes(val, tag.encoding)
+		if err != nil {
+			return err
+		}
+		u := reflect.New(fv.Type())
+		if err := u.Interface().(encoding.BinaryUnmarshaler).UnmarshalBinary(b); err != nil {
+			return err
+		}
+		fv.Set(u.Elem())
+		return nil
+	}
+	if u, ok := reflect.New(fv.Type()).Interface().(encoding.TextUnmarshaler); ok {
+		if err := u.UnmarshalText([]byte(val)); err != nil {
+			return err
+		}
+		fv.Set(reflect.ValueOf(u).Elem())
+		return nil
+	}
+
+	switch fv.Kind() {
+	case reflect.Pointer:
+		p := reflect.New(fv.Type().Elem())
+		if err := setField(p.Elem(), val, tag); err != nil {
+			return err
+		}
+		fv.Set(p)
+	case reflect.Slice:
+		if fv.Type().Elem() == mailAddressType && tag.sep() == defaultSeparator {
+			return setAddressList(fv, val)
+		}
+		parts := tag.split(val)
+		s := reflect.MakeSlice(fv.Type(), len(parts), len(parts))
+		for i, part := range parts {
+			if err := setField(s.Index(i), part, tag); err != nil {
+				return fmt.Errorf("element %d: %w", i, err)
+			}
+		}
+		fv.Set(s)
+	case reflect.Array:
+		parts := tag.split(val)
+		if len(parts) != fv.Len() {
+			return fmt.Errorf("expected %d elements, got %d", fv.Len(), len(parts))
+		}
+		a := reflect.New(fv.Type()).Elem()
+		for i, part := range parts {
+			if err := setField(a.Index(i), part, tag); err != nil {
+				return fmt.Errorf("element %d: %w", i, err)
+			}
+		}
+		fv.Set(a)
+	case reflect.Map:
+		m := reflect.MakeMap(fv.Type())
+		for i, pair := range tag.split(val) {
+			k, v, ok := strings.Cut(pair, tag.kvSep())
+			if !ok {
+				return fmt.Errorf("pair %d (%q): missing %q separator", i, pair, tag.kvSep())
+			}
+			keyTag := tag
+			keyTag.oneOf, keyTag.min, keyTag.max = nil, "", "" // Restrict values only.
+			key := reflect.New(fv.Type().Key()).Elem()
+			if err := setField(key, k, keyTag); err != nil {
+				return fmt.Errorf("pair %d (%q): invalid %s key: %w",
+					i, pair, typeName(fv.Type().Key()), err)
+			}
+			elem := reflect.New(fv.Type().Elem()).Elem()
+			if err := setField(elem, v, tag); err != nil {
+				return fmt.Errorf("pair %d (%q): invalid %s value: %w",
+					i, pair, typeName(fv.Type().Elem()), err)
+			}
+			m.SetMapIndex(key, elem)
+		}
+		fv.Set(m)
+	case reflect.String:
+		if tag.encoding != "" {
+			b, err := decodeBytes(val, tag.encoding)
+			if err != nil {
+				return err
+			}
+			val = string(b)
+		}
+		fv.SetString(val)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64:
+		if tag.byteSize {
+			n, err := parseByteSize(val, 1<<(fv.Type().Bits()-1)-1)
+			if err != nil {
+				return err
+			}
+			fv.SetInt(int64(n))
+			return nil
+		}
+		if tag.duration {
+			d, err := parseDuration(val)
+			if err != nil {
+				return err
+			}
+			if fv.OverflowInt(int64(d)) {
+				return fmt.Errorf("duration %q out of range", val)
+			}
+			fv.SetInt(int64(d))
+			return nil
+		}
+		digits, err := trimDigitSeparators(val, tag.intBase())
+		if err != nil {
+			return err
+		}
+		i, err := strconv.ParseInt(digits, tag.intBase(), fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64:
+		if tag.byteSize {
+			n, err := parseByteSize(val, 1<<fv.Type().Bits()-1)
+			if err != nil {
+				return err
+			}
+			fv.SetUint(n)
+			return nil
+		}
+		digits, err := trimDigitSeparators(val, tag.intBase())
+		if err != nil {
+			return err
+		}
+		i, err := strconv.ParseUint(digits, tag.intBase(), fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetUint(i)
+	case reflect.Float32, reflect.Float64:
+		if tag.percent {
+			f, err := parsePercent(val)
+			if err != nil {
+				return err
+			}
+			fv.SetFloat(f)
+			return nil
+		}
+		digits, err := trimDigitSeparators(val, 10)
+		if err != nil {
+			return err
+		}
+		f, err := strconv.ParseFloat(digits, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetFloat(f)
+	case reflect.Bool:
+		b, err := parseBool(val, tag.lenientBool)
+		if err != nil {
+			return err
+		}
+		fv.SetBool(b)
+	case reflect.Complex64, reflect.Complex128:
+		c, err := strconv.ParseComplex(val, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetComplex(c)
+	}
+
+	return nil
+}
+
+// namedTimeLayouts maps the names accepted by the `layout` attribute to Go
+// reference layouts.
+var namedTimeLayouts = map[string]string{
+	"ANSIC":       time.ANSIC,
+	"RFC822":      time.RFC822,
+	"RFC822Z":     time.RFC822Z,
+	"RFC850":      time.RFC850,
+	"RFC1123":     time.RFC1123,
+	"RFC1123Z":    time.RFC1123Z,
+	"RFC3339":     time.RFC3339,
+	"RFC3339Nano": time.RFC3339Nano,
+	"Kitchen":     time.Kitchen,
+	"DateTime":    time.DateTime,
+	"DateOnly":    time.DateOnly,
+	"TimeOnly":    time.TimeOnly,
+}
+
+// Layouts representing an integer count since the Unix epoch.
+const (
+	layoutUnix      = "unix"
+	layoutUnixMilli = "unixmilli"
+	layoutUnixMicro = "unixmicro"
+	layoutUnixNano  = "unixnano"
+)
+
+// parseTime parses `val` according to `layout` (see the `layout` attribute),
+// or using the first of timeLayouts that matches if `layout` is empty. Values
+// without a zone offset are interpreted in `loc`, or UTC if `loc` is nil.
+func parseTime(val, layout string, loc *time.Location) (time.Time, error) {
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	switch layout {
+	case "":
+	case layoutUnix, layoutUnixMilli, layoutUnixMicro, layoutUnixNano:
+		n, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return time.Time{}, err
+		}
+		var t time.Time
+		switch layout {
+		case layoutUnix:
+			t = time.Unix(n, 0)
+		case layoutUnixMilli:
+			t = time.UnixMilli(n)
+		case layoutUnixMicro:
+			t = time.UnixMicro(n)
+		default:
+			t = time.Unix(0, n)
+		}
+		return t.In(loc), nil
+	default:
+		if named, ok := namedTimeLayouts[layout]; ok {
+			layout = named
+		}
+		return time.ParseInLocation(layout, val, loc)
+	}
+
+	var firstErr error
+	for _, layout := range timeLayouts {
+		t, err := time.ParseInLocation(layout, val, loc)
+		if err == nil {
+			return t, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return time.Time{}, firstErr
+}
+
+// URL kinds accepted by the `url` attribute.
+const (
+	urlAbsolute = "absolute"
+	urlRelative = "relative"
+)
+
+// parseURL parses `val` with url.Parse, additionally requiring the URL to be
+// absolute or relative according to `kind` (see the `url` attribute).
+func parseURL(val, kind string) (*url.URL, error) {
+	u, err := url.Parse(val)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case kind == urlAbsolute && !u.IsAbs():
+		return nil, errors.New("expected an absolute URL")
+	case kind == urlAbsolute && u.Host == "" && u.Opaque == "":
+		return nil, errors.New("expected an absolute URL with a host")
+	case kind == urlRelative && u.IsAbs():
+		return nil, errors.New("expected a relative URL")
+	}
+
+	return u, nil
+}
+
+// minBigFloatPrec is the least precision, in bits, of parsed big.Float values:
+// that of a float64.
+const minBigFloatPrec = 64
+
+// parseBigFloat parses `val` like big.Float.UnmarshalText, except that the
+// precision is chosen to hold every digit of `val` (at least
+// minBigFloatPrec bits) rather than fixed at 64 bits, so values such as
+// amounts of money survive without rounding.
+func parseBigFloat(val string) (*big.Float, error) {
+	prec := uint(4 * len(val)) // Four bits cover a hex digit, so any digit.
+	if prec < minBigFloatPrec {
+		prec = minBigFloatPrec
+	}
+	f, _, err := big.ParseFloat(val, 0, prec, big.ToNearestEven)
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// loadLocation returns the location named by a `tz` attribute: "UTC",
+// "Local" or an IANA time zone name such as "Europe/London".
+func loadLocation(name string) (*time.Location, error) {
+	if name == "" {
+		return nil, errors.New("empty time zone")
+	}
+	return time.LoadLocation(name)
+}
+
+// formatTime formats `t` such that parseTime with the same `layout` yields
+// `t` again.
+func formatTime(t time.Time, layout string) string {
+	switch layout {
+	case "":
+		return t.Format(time.RFC3339Nano)
+	case layoutUnix:
+		return strconv.FormatInt(t.Unix(), 10)
+	case layoutUnixMilli:
+		return strconv.FormatInt(t.UnixMilli(), 10)
+	case layoutUnixMicro:
+		return strconv.FormatInt(t.UnixMicro(), 10)
+	case layoutUnixNano:
+		return strconv.FormatInt(t.UnixNano(), 10)
+	}
+
+	if named, ok := namedTimeLayouts[layout]; ok {
+		layout = named
+	}
+	return t.Format(layout)
+}
+
+// decodeBytes decodes `val` according to the named `encoding` (see
+// byteEncodings), treating an empty name as "raw".
+func decodeBytes(val, encoding string) ([]byte, error) {
+	decode := byteEncodings[encoding]
+	if decode == nil {
+		return []byte(val), nil
+	}
+
+	return decode(val)
+}
+
+// encodeBytes is the inverse of decodeBytes.
+func encodeBytes(b []byte, encoding string) string {
+	switch encoding {
+	case "hex":
+		return hex.EncodeToString(b)
+	case "base64":
+		return base64.StdEncoding.EncodeToString(b)
+	case "base64url":
+		return base64.URLEncoding.EncodeToString(b)
+	}
+
+	return string(b)
+}
+
+// sep returns the separator used to split slice values and map pairs, and to
+// join them when marshalling.
+func (t fieldTag) sep() string {
+	switch t.separator {
+	case "":
+		return defaultSeparator
+	case whitespaceSeparator:
+		return " "
+	}
+	return t.separator
+}
+
+// split splits `val` into slice elements or map pairs (see sep).
+func (t fieldTag) split(val string) []string {
+	if t.separator == whitespaceSeparator {
+		return strings.Fields(val)
+	}
+	return strings.Split(val, t.sep())
+}
+
+// intBase returns the base in which integer values are parsed and formatted,
+// as set by the `base` attribute.
+func (t fieldTag) intBase() int {
+	if t.base == "" {
+		return 10
+	}
+	base, _ := strconv.Atoi(t.base) // Validated by parseTag.
+	return base
+}
+
+// trimDigitSeparators removes underscores separating digits in `val`, a
+// number in `base`, so that values such as "1_000_000" are accepted. Each
+// underscore must fall between two digits. Values in base 0 are returned
+// unchanged, strconv permitting underscores there itself.
+func trimDigitSeparators(val string, base int) (string, error) {
+	if base == 0 || !strings.Contains(val, "_") {
+		return val, nil
+	}
+
+	isDigit := func(i int) bool {
+		if i < 0 || i >= len(val) {
+			return false
+		}
+		c := val[i]
+		return c >= '0' && c <= '9' ||
+			base == 16 && (c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F')
+	}
+	for i := range len(val) {
+		if val[i] == '_' && (!isDigit(i-1) || !isDigit(i+1)) {
+			return "", errors.New("underscores must separate digits")
+		}
+	}
+
+	return strings.ReplaceAll(val, "_", ""), nil
+}
+
+// kvSep returns the separator used to split map pairs into key and value.
+func (t fieldTag) kvSep() string {
+	if t.kvSeparator == "" {
+		return defaultKVSeparator
+	}
+	return t.kvSeparator
+}
+
+// setAddressList parses `val` as an RFC 5322 address list into `fv`, a
+// []mail.Address, so that commas within quoted display names (e.g.
+// "\"Doe, Jane\" <jane@example.com>") do not split addresses.
+func setAddressList(fv reflect.Value, val string) error {
+	list, err := mail.ParseAddressList(val)
+	if err != nil {
+		return err
+	}
+	s := reflect.MakeSlice(fv.Type(), len(list), len(list))
+	for i, a := range list {
+		s.Index(i).Set(reflect.ValueOf(*a))
+	}
+	fv.Set(s)
+	return nil
+}
+
+// fileModeBits maps the octal setuid, setgid and sticky bits to their
+// fs.FileMode equivalents.
+var fileModeBits = []struct {
+	octal uint64
+	mode  fs.FileMode
+}{
+	{0o4000, fs.ModeSetuid},
+	{0o2000, fs.ModeSetgid},
+	{0o1000, fs.ModeSticky},
+}
+
+// parseFileMode parses `val` as octal Unix permissions, such as "0640",
+// "640" or "0o2775", no greater than 07777. The setuid, setgid and sticky
+// bits are mapped to their fs.FileMode equivalents.
+func parseFileMode(val string) (fs.FileMode, error) {
+	n, err := strconv.ParseUint(strings.TrimPrefix(val, "0o"), 8, 32)
+	if err != nil {
+		return 0, err
+	}
+	if n > 0o7777 {
+		return 0, fmt.Errorf("file mode %s out of range [0, 07777]", val)
+	}
+
+	m := fs.FileMode(n) & fs.ModePerm
+	for _, b := range fileModeBits {
+		if n&b.octal != 0 {
+			m |= b.mode
+		}
+	}
+	return m, nil
+}
+
+// formatFileMode formats `m` such that parseFileMode yields it again,
+// ignoring any type bits (e.g. fs.ModeDir).
+func formatFileMode(m fs.FileMode) string {
+	n := uint64(m.Perm())
+	for _, b := range fileModeBits {
+		if m&b.mode != 0 {
+			n |= b.octal
+		}
+	}
+	return fmt.Sprintf("%04o", n)
+}
+
+// parsePercent parses `val` as a fraction in [0, 1], either a percentage
+// such as "75%" or "12.5 %" or a fraction such as "0.75" (see the `percent`
+// attribute).
+func parsePercent(val string) (float64, error) {
+	num, isPercent := strings.CutSuffix(val, "%")
+	num = strings.TrimSpace(num)
+	f, err := strconv.ParseFloat(num, 64)
+	if err != nil {
+		return 0, err
+	}
+	if isPercent {
+		f /= 100
+	}
+	if !(f >= 0 && f <= 1) {
+		return 0, fmt.Errorf("%s out of range [0, 1] (or [0%%, 100%%])", val)
+	}
+	return f, nil
+}
+
+// lenientBools are the values accepted for bool fields with the
+// `lenientbool` attribute in addition to those accepted by strconv.ParseBool,
+// keyed by their lower-case form.
+var lenientBools = map[string]bool{
+	"yes": true, "y": true, "on": true, "enabled": true,
+	"no": false, "n": false, "off": false, "disabled": false,
+}
+
+// parseBool parses `val` as strconv.ParseBool does, also accepting the values
+// in lenientBools, in any case, if `lenient` is set.
+func parseBool(val string, lenient bool) (bool, error) {
+	b, err := strconv.ParseBool(val)
+	if err == nil || !lenient {
+		return b, err
+	}
+	if b, ok := lenientBools[strings.ToLower(val)]; ok {
+		return b, nil
+	}
+	return false, err
+}
+
+// parseLevel parses `val` as a slog.Level: either a level name, optionally
+// with an offset, as accepted by Level.UnmarshalText (e.g. "DEBUG", "info" or
+// "WARN+2"), or a plain integer (e.g. "-4").
+func parseLevel(val string) (slog.Level, error) {
+	if n, err := strconv.Atoi(val); err == nil {
+		return slog.Level(n), nil
+	}
+
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(val)); err != nil {
+		return 0, err
+	}
+	return l, nil
+}
@@ -0,0 +1,1662 @@
+package envconf
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"math/big"
+	"net"
+	"net/mail"
+	"net/netip"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestProcess_Time(t *testing.T) {
+	tRun(t, "rfc3339 values keep their offset", func(t *testing.T) {
+		// Arrange
+		type testObj struct {
+			At time.Time `env:"AT,tz=America/New_York"`
+		}
+		mockEnvVarMap["AT"] = "2024-03-01T12:00:00+01:00"
+
+		// Act
+		var in testObj
+		Process(&in, mockEnv())
+
+		// Assert
+		assertEqual(t, in.At.Equal(time.Date(2024, 3, 1, 11, 0, 0, 0, time.UTC)), true)
+	})
+
+	tRun(t, "naive values default to utc", func(t *testing.T) {
+		// Arrange
+		type testObj struct {
+			At time.Time `env:"AT"`
+		}
+		mockEnvVarMap["AT"] = "2024-03-01 12:00:00"
+
+		// Act
+		var in testObj
+		Process(&in, mockEnv())
+
+		// Assert
+		assertEqual(t, in.At, time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC))
+	})
+
+	tRun(t, "naive values are interpreted in tz", func(t *testing.T) {
+		// Arrange
+		type testObj struct {
+			At  time.Time `env:"AT,tz=Europe/London"`
+			Day time.Time `env:"DAY,tz=Asia/Tokyo"`
+		}
+		mockEnvVarMap["AT"] = "2024-07-01T12:00:00"
+		mockEnvVarMap["DAY"] = "2024-07-01"
+
+		// Act
+		var in testObj
+		Process(&in, mockEnv())
+
+		// Assert
+		assertEqual(t, in.At.Equal(time.Date(2024, 7, 1, 11, 0, 0, 0, time.UTC)), true)
+		assertEqual(t, in.At.Location().String(), "Europe/London")
+		assertEqual(t, in.Day.Equal(time.Date(2024, 6, 30, 15, 0, 0, 0, time.UTC)), true)
+	})
+
+	tRun(t, "invalid time panics", func(t *testing.T) {
+		// Arrange
+		type testObj struct {
+			At time.Time `env:"AT"`
+		}
+		mockEnvVarMap["AT"] = "yesterday"
+
+		// Assert
+		defer assertPanicWithSubStr(t, `invalid time.Time value supplied: "yesterday"`)
+
+		// Act
+		var in testObj
+		Process(&in, mockEnv())
+	})
+
+	tRun(t, "unknown tz is a tag error", func(t *testing.T) {
+		// Arrange
+		type testObj struct {
+			At time.Time `env:"AT,tz=Mars/Olympus_Mons"`
+		}
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv())
+
+		// Assert
+		assertErrorWithSubStr(t, err, "invalid tz struct tag attribute")
+	})
+}
+
+func TestProcess_TimeLayout(t *testing.T) {
+	tRun(t, "reference layout", func(t *testing.T) {
+		// Arrange
+		type testObj struct {
+			At time.Time `env:"AT,layout=02/01/2006 15:04"`
+		}
+		mockEnvVarMap["AT"] = "25/12/2024 08:30"
+
+		// Act
+		var in testObj
+		Process(&in, mockEnv())
+
+		// Assert
+		assertEqual(t, in.At, time.Date(2024, 12, 25, 8, 30, 0, 0, time.UTC))
+	})
+
+	tRun(t, "named layout", func(t *testing.T) {
+		// Arrange
+		type testObj struct {
+			At time.Time `env:"AT,layout=RFC1123Z"`
+		}
+		mockEnvVarMap["AT"] = "Mon, 02 Jan 2006 15:04:05 -0700"
+
+		// Act
+		var in testObj
+		Process(&in, mockEnv())
+
+		// Assert
+		assertEqual(t, in.At.Equal(time.Date(2006, 1, 2, 22, 4, 5, 0, time.UTC)), true)
+	})
+
+	tRun(t, "unix layouts", func(t *testing.T) {
+		// Arrange
+		type testObj struct {
+			Sec   time.Time `env:"SEC,layout=unix"`
+			Milli time.Time `env:"MILLI,layout=unixmilli"`
+			Micro time.Time `env:"MICRO,layout=unixmicro"`
+			Nano  time.Time `env:"NANO,layout=unixnano"`
+		}
+		mockEnvVarMap["SEC"] = "1700000000"
+		mockEnvVarMap["MILLI"] = "1700000000123"
+		mockEnvVarMap["MICRO"] = "1700000000123456"
+		mockEnvVarMap["NANO"] = "1700000000123456789"
+
+		// Act
+		var in testObj
+		Process(&in, mockEnv())
+
+		// Assert
+		assertEqual(t, in.Sec, time.Unix(1700000000, 0).UTC())
+		assertEqual(t, in.Milli, time.UnixMilli(1700000000123).UTC())
+		assertEqual(t, in.Micro, time.UnixMicro(1700000000123456).UTC())
+		assertEqual(t, in.Nano, time.Unix(0, 1700000000123456789).UTC())
+	})
+
+	tRun(t, "value not matching layout panics", func(t *testing.T) {
+		// Arrange
+		type testObj struct {
+			At time.Time `env:"AT,layout=DateOnly"`
+		}
+		mockEnvVarMap["AT"] = "2024-12-25T08:30:00Z"
+
+		// Assert
+		defer assertPanicWithSubStr(t, "invalid time.Time value supplied")
+
+		// Act
+		var in testObj
+		Process(&in, mockEnv())
+	})
+
+	tRun(t, "layouts round trip", func(t *testing.T) {
+		// Arrange
+		type testObj struct {
+			Date time.Time `env:"DATE,layout=DateOnly"`
+			Unix time.Time `env:"UNIX,layout=unixmilli"`
+		}
+		in := testObj{
+			Date: time.Date(2024, 12, 25, 0, 0, 0, 0, time.UTC),
+			Unix: time.UnixMilli(1700000000123).UTC(),
+		}
+
+		// Act
+		err := RoundTripCheck(in)
+
+		// Assert
+		assertEqual(t, err, nil)
+	})
+}
+
+func TestProcess_Location(t *testing.T) {
+	type testObj struct {
+		TZ       *time.Location   `env:"TZ_OVERRIDE"`
+		Fallback *time.Location   `env:"FALLBACK_TZ,default=UTC"`
+		Regions  []*time.Location `env:"REGION_TZS"`
+	}
+
+	tRun(t, "zones are loaded by name", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["TZ_OVERRIDE"] = "Europe/London"
+		mockEnvVarMap["REGION_TZS"] = "America/New_York,Asia/Tokyo"
+
+		// Act
+		var in testObj
+		Process(&in, mockEnv())
+
+		// Assert
+		assertEqual(t, in.TZ.String(), "Europe/London")
+		assertEqual(t, in.Fallback, time.UTC)
+		assertEqual(t, in.Regions[1].String(), "Asia/Tokyo")
+	})
+
+	tRun(t, "unknown zones are reported", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["TZ_OVERRIDE"] = "Europe/Atlantis"
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv())
+
+		// Assert
+		var pe *ParseError
+		assertEqual(t, errors.As(err, &pe), true)
+		assertEqual(t, pe.Kind, "*time.Location")
+		assertErrorWithSubStr(t, pe.Err, "unknown time zone Europe/Atlantis")
+	})
+
+	tRun(t, "values are marshalled by name", func(t *testing.T) {
+		// Arrange
+		loc, _ := time.LoadLocation("Europe/Paris")
+		in := testObj{TZ: loc}
+
+		// Act
+		env, err := Marshal(in)
+
+		// Assert
+		assertEqual(t, err, nil)
+		assertEqual(t, env["TZ_OVERRIDE"], "Europe/Paris")
+		assertEqual(t, env["FALLBACK_TZ"], "")
+	})
+}
+
+func TestProcess_Bytes(t *testing.T) {
+	// Pre Arrange
+	type testObj struct {
+		Raw       []byte `env:"RAW"`
+		Hex       []byte `env:"HEX,encoding=hex"`
+		Base64    []byte `env:"BASE64,encoding=base64"`
+		Base64URL []byte `env:"BASE64URL,encoding=base64url"`
+	}
+
+	tRun(t, "values are decoded per encoding", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["RAW"] = "raw bytes"
+		mockEnvVarMap["HEX"] = "deadbeef"
+		mockEnvVarMap["BASE64"] = "+/8="
+		mockEnvVarMap["BASE64URL"] = "-_8"
+
+		// Act
+		var in testObj
+		Process(&in, mockEnv())
+
+		// Assert
+		assertEqual(t, string(in.Raw), "raw bytes")
+		assertEqual(t, string(in.Hex), "\xde\xad\xbe\xef")
+		assertEqual(t, string(in.Base64), "\xfb\xff")
+		assertEqual(t, string(in.Base64URL), "\xfb\xff")
+	})
+
+	tRun(t, "invalid encoding panics", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["HEX"] = "xyz"
+
+		// Assert
+		defer assertPanicWithSubStr(t, `invalid []byte value supplied: "xyz"`)
+
+		// Act
+		var in testObj
+		Process(&in, mockEnv())
+	})
+
+	tRun(t, "unknown encoding is a tag error", func(t *testing.T) {
+		// Arrange
+		type testObj struct {
+			Key []byte `env:"KEY,encoding=rot13"`
+		}
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv())
+
+		// Assert
+		assertErrorWithSubStr(t, err, `unknown encoding "rot13"`)
+	})
+
+	tRun(t, "encodings round trip", func(t *testing.T) {
+		// Arrange
+		key := []byte{0, 1, 2, 0xfb, 0xff}
+		in := testObj{Raw: []byte("raw"), Hex: key, Base64: key, Base64URL: key}
+
+		// Act
+		err := RoundTripCheck(in)
+
+		// Assert
+		assertEqual(t, err, nil)
+	})
+}
+
+func TestProcess_Base64String(t *testing.T) {
+	// Pre Arrange
+	type testObj struct {
+		Cert  string   `env:"CERT,base64"`
+		Token string   `env:"TOKEN,encoding=hex"`
+		Keys  []string `env:"KEYS,base64"`
+	}
+
+	pem := "-----BEGIN CERTIFICATE-----\nMIIB\n-----END CERTIFICATE-----\n"
+
+	tRun(t, "values are decoded before assignment", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["CERT"] = base64.StdEncoding.EncodeToString([]byte(pem))
+		mockEnvVarMap["TOKEN"] = "736563726574"
+		mockEnvVarMap["KEYS"] = "YQ==,Yg"
+
+		// Act
+		var in testObj
+		Process(&in, mockEnv())
+
+		// Assert
+		assertEqual(t, in.Cert, pem)
+		assertEqual(t, in.Token, "secret")
+		assertEqual(t, len(in.Keys), 2)
+		assertEqual(t, in.Keys[0], "a")
+		assertEqual(t, in.Keys[1], "b")
+	})
+
+	tRun(t, "line breaks in the encoded value are ignored", func(t *testing.T) {
+		// Arrange
+		enc := base64.StdEncoding.EncodeToString([]byte(pem))
+		mockEnvVarMap["CERT"] = enc[:20] + "\n" + enc[20:40] + "\r\n" + enc[40:]
+
+		// Act
+		var in testObj
+		Process(&in, mockEnv())
+
+		// Assert
+		assertEqual(t, in.Cert, pem)
+	})
+
+	tRun(t, "invalid base64 is a parse error", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["CERT"] = "not base64!"
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv())
+
+		// Assert
+		assertErrorWithSubStr(t, err, `invalid string value supplied`)
+	})
+
+	tRun(t, "conflicting encodings are a tag error", func(t *testing.T) {
+		// Arrange
+		type testObj struct {
+			Cert string `env:"CERT,base64,encoding=hex"`
+		}
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv())
+
+		// Assert
+		assertErrorWithSubStr(t, err, "base64 and encoding struct tag attributes are mutually exclusive")
+	})
+
+	tRun(t, "values round trip", func(t *testing.T) {
+		// Arrange
+		in := testObj{Cert: pem, Token: "secret", Keys: []string{"a", "b"}}
+
+		// Act
+		err := RoundTripCheck(in)
+
+		// Assert
+		assertEqual(t, err, nil)
+	})
+}
+
+func TestProcess_Slices(t *testing.T) {
+	tRun(t, "comma separated by default", func(t *testing.T) {
+		// Arrange
+		type testObj struct {
+			Hosts  []string  `env:"HOSTS"`
+			Ports  []int     `env:"PORTS"`
+			Ratios []float64 `env:"RATIOS"`
+			Flags  []bool    `env:"FLAGS,default=true"`
+		}
+		mockEnvVarMap["HOSTS"] = "a.example.com,b.example.com"
+		mockEnvVarMap["PORTS"] = "80,443"
+		mockEnvVarMap["RATIOS"] = "0.5,1.5"
+
+		// Act
+		var in testObj
+		Process(&in, mockEnv())
+
+		// Assert
+		assertEqual(t, len(in.Hosts), 2)
+		assertEqual(t, in.Hosts[1], "b.example.com")
+		assertEqual(t, in.Ports[0], 80)
+		assertEqual(t, in.Ports[1], 443)
+		assertEqual(t, in.Ratios[1], 1.5)
+		assertEqual(t, in.Flags[0], true)
+	})
+
+	tRun(t, "custom separator", func(t *testing.T) {
+		// Arrange
+		type testObj struct {
+			DSNs  []string    `env:"DSNS,separator=;"`
+			Times []time.Time `env:"TIMES,separator= ,layout=DateOnly"`
+		}
+		mockEnvVarMap["DSNS"] = "host=a,port=1;host=b,port=2"
+		mockEnvVarMap["TIMES"] = "2024-01-01 2024-02-01"
+
+		// Act
+		var in testObj
+		Process(&in, mockEnv())
+
+		// Assert
+		assertEqual(t, len(in.DSNs), 2)
+		assertEqual(t, in.DSNs[0], "host=a,port=1")
+		assertEqual(t, in.Times[1], time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC))
+	})
+
+	tRun(t, "newline and whitespace separators", func(t *testing.T) {
+		// Arrange
+		type testObj struct {
+			Lines []string          `env:"LINES,separator=\n"`
+			Words []string          `env:"WORDS,separator=whitespace"`
+			Pairs map[string]string `env:"PAIRS,separator=whitespace,kvseparator=="`
+		}
+		mockEnvVarMap["LINES"] = "host=a,port=1\nhost=b,port=2"
+		mockEnvVarMap["WORDS"] = "\n  alpha\tbeta \n gamma\n"
+		mockEnvVarMap["PAIRS"] = "a=1 b=2"
+
+		// Act
+		var in testObj
+		Process(&in, mockEnv())
+
+		// Assert
+		assertEqual(t, len(in.Lines), 2)
+		assertEqual(t, in.Lines[1], "host=b,port=2")
+		assertEqual(t, len(in.Words), 3)
+		assertEqual(t, in.Words[2], "gamma")
+		assertEqual(t, in.Pairs["b"], "2")
+	})
+
+	tRun(t, "invalid element panics", func(t *testing.T) {
+		// Arrange
+		type testObj struct {
+			Ports []uint16 `env:"PORTS"`
+		}
+		mockEnvVarMap["PORTS"] = "80,70000"
+
+		// Assert
+		defer assertPanicWithSubStr(t, `invalid []uint16 value supplied: "80,70000"`)
+
+		// Act
+		var in testObj
+		Process(&in, mockEnv())
+	})
+
+	tRun(t, "slices round trip", func(t *testing.T) {
+		// Arrange
+		type testObj struct {
+			Hosts []string `env:"HOSTS,separator=;"`
+			Ports []int    `env:"PORTS"`
+			Words []string `env:"WORDS,separator=whitespace"`
+		}
+		in := testObj{Hosts: []string{"a,b", "c"}, Ports: []int{1, 2, 3}, Words: []string{"x", "y"}}
+
+		// Act
+		err := RoundTripCheck(in)
+
+		// Assert
+		assertEqual(t, err, nil)
+	})
+}
+
+func TestProcess_Maps(t *testing.T) {
+	tRun(t, "key:value pairs separated by commas by default", func(t *testing.T) {
+		// Arrange
+		type testObj struct {
+			Labels  map[string]string `env:"LABELS"`
+			Weights map[string]int    `env:"WEIGHTS"`
+			Enabled map[int]bool      `env:"ENABLED,default=1:true"`
+		}
+		mockEnvVarMap["LABELS"] = "team:core,tier:1"
+		mockEnvVarMap["WEIGHTS"] = "a:1,b:2"
+
+		// Act
+		var in testObj
+		Process(&in, mockEnv())
+
+		// Assert
+		assertEqual(t, len(in.Labels), 2)
+		assertEqual(t, in.Labels["team"], "core")
+		assertEqual(t, in.Labels["tier"], "1")
+		assertEqual(t, in.Weights["b"], 2)
+		assertEqual(t, in.Enabled[1], true)
+	})
+
+	tRun(t, "custom separators", func(t *testing.T) {
+		// Arrange
+		type testObj struct {
+			Overrides map[string]string `env:"OVERRIDES,separator=;,kvseparator=="`
+		}
+		mockEnvVarMap["OVERRIDES"] = "acme=host=a:1;globex=host=b:2"
+
+		// Act
+		var in testObj
+		Process(&in, mockEnv())
+
+		// Assert
+		assertEqual(t, in.Overrides["acme"], "host=a:1")
+		assertEqual(t, in.Overrides["globex"], "host=b:2")
+	})
+
+	tRun(t, "pair without separator is reported", func(t *testing.T) {
+		// Arrange
+		type testObj struct {
+			Labels map[string]string `env:"LABELS"`
+		}
+		mockEnvVarMap["LABELS"] = "team:core,tier"
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv())
+
+		// Assert
+		var pe *ParseError
+		assertEqual(t, errors.As(err, &pe), true)
+		assertEqual(t, pe.Err.Error(), `pair 1 ("tier"): missing ":" separator`)
+	})
+
+	tRun(t, "invalid value panics", func(t *testing.T) {
+		// Arrange
+		type testObj struct {
+			Weights map[string]int `env:"WEIGHTS"`
+		}
+		mockEnvVarMap["WEIGHTS"] = "a:1,b:x"
+
+		// Assert
+		defer assertPanicWithSubStr(t, `invalid map[string]int value supplied: "a:1,b:x"`)
+
+		// Act
+		var in testObj
+		Process(&in, mockEnv())
+	})
+
+	tRun(t, "keys and values are converted like fields", func(t *testing.T) {
+		// Arrange
+		type testObj struct {
+			Timeouts map[string]time.Duration `env:"TIMEOUTS,duration"`
+			Limits   map[uint16]float64       `env:"LIMITS"`
+			Windows  map[time.Weekday]bool    `env:"WINDOWS"`
+		}
+		mockEnvVarMap["TIMEOUTS"] = "read:5s,write:1m"
+		mockEnvVarMap["LIMITS"] = "80:0.5,443:1_000"
+		mockEnvVarMap["WINDOWS"] = "0:true,6:false"
+
+		// Act
+		var in testObj
+		Process(&in, mockEnv())
+
+		// Assert
+		assertEqual(t, in.Timeouts["write"], time.Minute)
+		assertEqual(t, in.Limits[443], 1000.0)
+		assertEqual(t, in.Windows[time.Sunday], true)
+	})
+
+	tRun(t, "invalid keys and values name the pair", func(t *testing.T) {
+		tests := map[string]string{
+			"80:1,http:2":   `pair 1 ("http:2"): invalid uint16 key: strconv.ParseUint: parsing "http": invalid syntax`,
+			"80:1,443:fast": `pair 1 ("443:fast"): invalid float64 value: strconv.ParseFloat: parsing "fast": invalid syntax`,
+		}
+		for val, msg := range tests {
+			// Arrange
+			type testObj struct {
+				Limits map[uint16]float64 `env:"LIMITS"`
+			}
+			mockEnvVarMap["LIMITS"] = val
+
+			// Act
+			var in testObj
+			err := ProcessE(&in, mockEnv())
+
+			// Assert
+			var pe *ParseError
+			assertEqual(t, errors.As(err, &pe), true)
+			assertEqual(t, pe.Err.Error(), msg)
+		}
+	})
+
+	tRun(t, "maps round trip", func(t *testing.T) {
+		// Arrange
+		type testObj struct {
+			Labels  map[string]string `env:"LABELS"`
+			Weights map[string]int    `env:"WEIGHTS,separator=;"`
+		}
+		in := testObj{
+			Labels:  map[string]string{"team": "core", "tier": "1"},
+			Weights: map[string]int{"a": 1, "b": 2},
+		}
+
+		// Act
+		err := RoundTripCheck(in)
+
+		// Assert
+		assertEqual(t, err, nil)
+	})
+}
+
+func TestProcess_Arrays(t *testing.T) {
+	tRun(t, "populated from delimited values", func(t *testing.T) {
+		// Arrange
+		type testObj struct {
+			Names [4]string `env:"NAMES"`
+			Mask  [4]uint8  `env:"MASK,separator=."`
+			Ports [3]int    `env:"PORTS,default=1"`
+		}
+		mockEnvVarMap["NAMES"] = "a,b,c,d"
+		mockEnvVarMap["MASK"] = "255.255.255.0"
+		mockEnvVarMap["PORTS"] = "80,443,8080"
+
+		// Act
+		var in testObj
+		Process(&in, mockEnv())
+
+		// Assert
+		assertEqual(t, in.Names, [4]string{"a", "b", "c", "d"})
+		assertEqual(t, in.Mask, [4]uint8{255, 255, 255, 0})
+		assertEqual(t, in.Ports, [3]int{80, 443, 8080})
+	})
+
+	tRun(t, "element count mismatch is reported", func(t *testing.T) {
+		// Arrange
+		type testObj struct {
+			Ports [3]int `env:"PORTS"`
+		}
+		mockEnvVarMap["PORTS"] = "80,443"
+
+		// Act
+		in := testObj{Ports: [3]int{1, 2, 3}}
+		err := ProcessE(&in, mockEnv())
+
+		// Assert
+		var pe *ParseError
+		assertEqual(t, errors.As(err, &pe), true)
+		assertEqual(t, pe.Kind, "[3]int")
+		assertEqual(t, pe.Err.Error(), "expected 3 elements, got 2")
+		assertEqual(t, in.Ports, [3]int{1, 2, 3})
+	})
+
+	tRun(t, "invalid element panics", func(t *testing.T) {
+		// Arrange
+		type testObj struct {
+			Ports [2]uint16 `env:"PORTS"`
+		}
+		mockEnvVarMap["PORTS"] = "80,70000"
+
+		// Assert
+		defer assertPanicWithSubStr(t, `invalid [2]uint16 value supplied: "80,70000"`)
+
+		// Act
+		var in testObj
+		Process(&in, mockEnv())
+	})
+
+	tRun(t, "arrays round trip", func(t *testing.T) {
+		// Arrange
+		type testObj struct {
+			Mask [4]uint8 `env:"MASK,separator=."`
+		}
+		in := testObj{Mask: [4]uint8{255, 255, 0, 0}}
+
+		// Act
+		err := RoundTripCheck(in)
+
+		// Assert
+		assertEqual(t, err, nil)
+	})
+}
+
+// testLevel is a TextUnmarshaler with a pointer receiver.
+type testLevel int
+
+func (l *testLevel) UnmarshalText(b []byte) error {
+	switch string(b) {
+	case "low":
+		*l = 1
+	case "high":
+		*l = 2
+	default:
+		return fmt.Errorf("unknown level %q", b)
+	}
+	return nil
+}
+
+func (l testLevel) MarshalText() ([]byte, error) {
+	return []byte(map[testLevel]string{1: "low", 2: "high"}[l]), nil
+}
+
+func TestProcess_TextUnmarshaler(t *testing.T) {
+	type testObj struct {
+		Addr   netip.Addr           `env:"ADDR"`
+		Prefix *netip.Prefix        `env:"PREFIX"`
+		Level  testLevel            `env:"LEVEL,default=low"`
+		Peers  []netip.AddrPort     `env:"PEERS"`
+		Levels map[string]testLevel `env:"LEVELS"`
+		Unset  *netip.Addr          `env:"UNSET"`
+		Nested struct {
+			Port *int `env:"PORT"`
+		}
+	}
+
+	tRun(t, "values are passed to UnmarshalText", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["ADDR"] = "10.0.0.1"
+		mockEnvVarMap["PREFIX"] = "10.0.0.0/8"
+		mockEnvVarMap["PEERS"] = "10.0.0.2:80,[::1]:443"
+		mockEnvVarMap["LEVELS"] = "db:high"
+		mockEnvVarMap["PORT"] = "8080"
+
+		// Act
+		var in testObj
+		Process(&in, mockEnv())
+
+		// Assert
+		assertEqual(t, in.Addr, netip.MustParseAddr("10.0.0.1"))
+		assertEqual(t, *in.Prefix, netip.MustParsePrefix("10.0.0.0/8"))
+		assertEqual(t, in.Level, testLevel(1))
+		assertEqual(t, in.Peers[1], netip.MustParseAddrPort("[::1]:443"))
+		assertEqual(t, in.Levels["db"], testLevel(2))
+		assertEqual(t, in.Unset == nil, true)
+		assertEqual(t, *in.Nested.Port, 8080)
+	})
+
+	tRun(t, "errors are reported with the type name", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["ADDR"] = "10.0.0.256"
+		mockEnvVarMap["LEVEL"] = "extreme"
+
+		// Act
+		in := testObj{Level: 2}
+		err := ProcessE(&in, mockEnv())
+
+		// Assert
+		assertErrorWithSubStr(t, err, `invalid netip.Addr value supplied: "10.0.0.256"`)
+		assertErrorWithSubStr(t, err, `invalid envconf.testLevel value supplied: "extreme"`)
+		assertEqual(t, in.Level, testLevel(2))
+	})
+
+	tRun(t, "values round trip", func(t *testing.T) {
+		// Arrange
+		prefix := netip.MustParsePrefix("192.168.0.0/16")
+		port := 80
+		in := testObj{
+			Addr:   netip.MustParseAddr("::1"),
+			Prefix: &prefix,
+			Level:  2,
+			Peers:  []netip.AddrPort{netip.MustParseAddrPort("1.2.3.4:5")},
+			Levels: map[string]testLevel{"a": 1},
+		}
+		in.Nested.Port = &port
+
+		// Act
+		err := RoundTripCheck(in)
+
+		// Assert
+		assertEqual(t, err, nil)
+	})
+}
+
+// testKey is a BinaryUnmarshaler accepting exactly 4 bytes.
+type testKey struct{ b [4]byte }
+
+func (k *testKey) UnmarshalBinary(b []byte) error {
+	if len(b) != len(k.b) {
+		return fmt.Errorf("expected %d bytes, got %d", len(k.b), len(b))
+	}
+	copy(k.b[:], b)
+	return nil
+}
+
+func (k testKey) MarshalBinary() ([]byte, error) {
+	return k.b[:], nil
+}
+
+func TestProcess_BinaryUnmarshaler(t *testing.T) {
+	type testObj struct {
+		Raw    testKey    `env:"RAW"`
+		Key    testKey    `env:"KEY,encoding=base64"`
+		KeyPtr *testKey   `env:"KEY_PTR,encoding=hex"`
+		Addr   netip.Addr `env:"ADDR,encoding=hex"`
+	}
+
+	tRun(t, "decoded values are passed to UnmarshalBinary", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["RAW"] = "abcd"
+		mockEnvVarMap["KEY"] = "AQIDBA=="
+		mockEnvVarMap["KEY_PTR"] = "01020304"
+		mockEnvVarMap["ADDR"] = "0a000001"
+
+		// Act
+		var in testObj
+		Process(&in, mockEnv())
+
+		// Assert
+		assertEqual(t, in.Raw, testKey{[4]byte{'a', 'b', 'c', 'd'}})
+		assertEqual(t, in.Key, testKey{[4]byte{1, 2, 3, 4}})
+		assertEqual(t, *in.KeyPtr, testKey{[4]byte{1, 2, 3, 4}})
+		assertEqual(t, in.Addr, netip.MustParseAddr("10.0.0.1"))
+	})
+
+	tRun(t, "errors are reported with the type name", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["KEY"] = "AQID"
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv())
+
+		// Assert
+		var pe *ParseError
+		assertEqual(t, errors.As(err, &pe), true)
+		assertEqual(t, pe.Kind, "envconf.testKey")
+		assertEqual(t, pe.Err.Error(), "expected 4 bytes, got 3")
+	})
+
+	tRun(t, "decoded size limits apply", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["KEY"] = "AQIDBAU="
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv(), WithMaxDecodedSize(4))
+
+		// Assert
+		assertEqual(t, errors.Is(err, ErrValueTooLarge), true)
+	})
+
+	tRun(t, "values round trip", func(t *testing.T) {
+		// Arrange
+		in := testObj{
+			Raw:    testKey{[4]byte{'w', 'x', 'y', 'z'}},
+			Key:    testKey{[4]byte{0xff, 0, 1, 2}},
+			KeyPtr: &testKey{[4]byte{9, 8, 7, 6}},
+			Addr:   netip.MustParseAddr("fe80::1"),
+		}
+
+		// Act
+		err := RoundTripCheck(in)
+
+		// Assert
+		assertEqual(t, err, nil)
+	})
+}
+
+// testLogFormat is a Setter that also implements encoding.TextUnmarshaler.
+type testLogFormat string
+
+func (f *testLogFormat) SetEnvValue(val string) error {
+	switch val {
+	case "json", "text":
+		*f = testLogFormat(val)
+		return nil
+	}
+	return fmt.Errorf("unknown log format %q", val)
+}
+
+func (f *testLogFormat) UnmarshalText([]byte) error {
+	return errors.New("UnmarshalText must not be called")
+}
+
+func TestProcess_Setter(t *testing.T) {
+	type testObj struct {
+		Format    testLogFormat   `env:"FORMAT,default=text"`
+		FormatPtr *testLogFormat  `env:"FORMAT_PTR"`
+		Formats   []testLogFormat `env:"FORMATS"`
+	}
+
+	tRun(t, "values are passed to SetEnvValue", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["FORMAT_PTR"] = "json"
+		mockEnvVarMap["FORMATS"] = "json,text"
+
+		// Act
+		var in testObj
+		Process(&in, mockEnv())
+
+		// Assert
+		assertEqual(t, in.Format, testLogFormat("text"))
+		assertEqual(t, *in.FormatPtr, testLogFormat("json"))
+		assertEqual(t, in.Formats[1], testLogFormat("text"))
+	})
+
+	tRun(t, "errors are reported with the type name", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["FORMAT"] = "xml"
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv())
+
+		// Assert
+		var pe *ParseError
+		assertEqual(t, errors.As(err, &pe), true)
+		assertEqual(t, pe.Kind, "envconf.testLogFormat")
+		assertEqual(t, pe.Err.Error(), `unknown log format "xml"`)
+		assertEqual(t, in.Format, testLogFormat(""))
+	})
+}
+
+func TestProcess_URL(t *testing.T) {
+	type testObj struct {
+		Webhook  url.URL   `env:"WEBHOOK,url=absolute"`
+		Issuer   *url.URL  `env:"ISSUER"`
+		Callback *url.URL  `env:"CALLBACK,url=relative"`
+		Mirrors  []url.URL `env:"MIRRORS,separator= "`
+	}
+
+	tRun(t, "values are parsed with url.Parse", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["WEBHOOK"] = "https://hooks.example.com/a?b=c"
+		mockEnvVarMap["ISSUER"] = "https://id.example.com"
+		mockEnvVarMap["CALLBACK"] = "/oauth/callback"
+		mockEnvVarMap["MIRRORS"] = "https://a.example.com https://b.example.com"
+
+		// Act
+		var in testObj
+		Process(&in, mockEnv())
+
+		// Assert
+		assertEqual(t, in.Webhook.Host, "hooks.example.com")
+		assertEqual(t, in.Webhook.RawQuery, "b=c")
+		assertEqual(t, in.Issuer.String(), "https://id.example.com")
+		assertEqual(t, in.Callback.Path, "/oauth/callback")
+		assertEqual(t, in.Mirrors[1].Host, "b.example.com")
+	})
+
+	tRun(t, "absolute and relative URLs are enforced", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["WEBHOOK"] = "hooks.example.com/a"
+		mockEnvVarMap["CALLBACK"] = "https://evil.example.com/callback"
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv())
+
+		// Assert
+		assertErrorWithSubStr(t, err, `invalid url.URL value supplied: "hooks.example.com/a"`)
+		assertErrorWithSubStr(t, err, `invalid *url.URL value supplied: "https://evil.example.com/callback"`)
+		assertEqual(t, in.Callback == nil, true)
+	})
+
+	tRun(t, "absolute URLs require a host", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["WEBHOOK"] = "https:///path"
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv())
+
+		// Assert
+		var pe *ParseError
+		assertEqual(t, errors.As(err, &pe), true)
+		assertEqual(t, pe.Err.Error(), "expected an absolute URL with a host")
+	})
+
+	tRun(t, "values round trip", func(t *testing.T) {
+		// Arrange
+		issuer, _ := url.Parse("https://id.example.com/realms/a")
+		in := testObj{Webhook: url.URL{Scheme: "https", Host: "h.example.com", Path: "/x y"}, Issuer: issuer}
+
+		// Act
+		err := RoundTripCheck(in)
+
+		// Assert
+		assertEqual(t, err, nil)
+	})
+}
+
+func TestProcess_IP(t *testing.T) {
+	type testObj struct {
+		Listen    net.IP         `env:"LISTEN"`
+		Addr      netip.Addr     `env:"ADDR"`
+		Network   net.IPNet      `env:"NETWORK"`
+		Allowlist []netip.Prefix `env:"ALLOWLIST"`
+		Trusted   []*net.IPNet   `env:"TRUSTED"`
+	}
+
+	tRun(t, "addresses and ranges are parsed", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["LISTEN"] = "::1"
+		mockEnvVarMap["ADDR"] = "192.0.2.1"
+		mockEnvVarMap["NETWORK"] = "10.1.2.3/8"
+		mockEnvVarMap["ALLOWLIST"] = "192.0.2.0/24,2001:db8::/32"
+		mockEnvVarMap["TRUSTED"] = "172.16.0.0/12"
+
+		// Act
+		var in testObj
+		Process(&in, mockEnv())
+
+		// Assert
+		assertEqual(t, in.Listen.Equal(net.IPv6loopback), true)
+		assertEqual(t, in.Addr, netip.MustParseAddr("192.0.2.1"))
+		assertEqual(t, in.Network.String(), "10.0.0.0/8")
+		assertEqual(t, in.Allowlist[1], netip.MustParsePrefix("2001:db8::/32"))
+		assertEqual(t, in.Trusted[0].Contains(net.ParseIP("172.16.5.4")), true)
+	})
+
+	tRun(t, "malformed values are reported", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["LISTEN"] = "localhost"
+		mockEnvVarMap["ADDR"] = "192.0.2"
+		mockEnvVarMap["NETWORK"] = "10.0.0.0"
+		mockEnvVarMap["ALLOWLIST"] = "192.0.2.0/33"
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv())
+
+		// Assert
+		assertErrorWithSubStr(t, err, `invalid net.IP value supplied: "localhost"`)
+		assertErrorWithSubStr(t, err, `invalid netip.Addr value supplied: "192.0.2"`)
+		assertErrorWithSubStr(t, err, `invalid net.IPNet value supplied: "10.0.0.0"`)
+		assertErrorWithSubStr(t, err, `invalid []netip.Prefix value supplied: "192.0.2.0/33"`)
+	})
+
+	tRun(t, "values round trip", func(t *testing.T) {
+		// Arrange
+		_, n, _ := net.ParseCIDR("10.0.0.0/8")
+		in := testObj{
+			Listen:    net.ParseIP("192.0.2.7"),
+			Addr:      netip.MustParseAddr("::1"),
+			Network:   *n,
+			Allowlist: []netip.Prefix{netip.MustParsePrefix("192.0.2.0/24")},
+			Trusted:   []*net.IPNet{n},
+		}
+
+		// Act
+		err := RoundTripCheck(in)
+
+		// Assert
+		assertEqual(t, err, nil)
+	})
+}
+
+func TestProcess_Big(t *testing.T) {
+	type testObj struct {
+		Supply  big.Int    `env:"SUPPLY"`
+		Modulus *big.Int   `env:"MODULUS"`
+		Price   big.Float  `env:"PRICE"`
+		Ratio   *big.Rat   `env:"RATIO"`
+		Limits  []*big.Int `env:"LIMITS"`
+	}
+
+	tRun(t, "values beyond int64 and float64 are parsed exactly", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["SUPPLY"] = "123456789012345678901234567890"
+		mockEnvVarMap["MODULUS"] = "0xffffffffffffffffffffffffffffffff"
+		mockEnvVarMap["PRICE"] = "12345678901234567890.0123456789"
+		mockEnvVarMap["RATIO"] = "1/3"
+		mockEnvVarMap["LIMITS"] = "1,-2"
+
+		// Act
+		var in testObj
+		Process(&in, mockEnv())
+
+		// Assert
+		assertEqual(t, in.Supply.String(), "123456789012345678901234567890")
+		assertEqual(t, in.Modulus.BitLen(), 128)
+		assertEqual(t, in.Price.Text('f', 10), "12345678901234567890.0123456789")
+		assertEqual(t, in.Ratio.String(), "1/3")
+		assertEqual(t, in.Limits[1].Int64(), int64(-2))
+	})
+
+	tRun(t, "malformed values are reported", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["SUPPLY"] = "12abc"
+		mockEnvVarMap["PRICE"] = "1e99999999999"
+		mockEnvVarMap["RATIO"] = "1/0"
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv())
+
+		// Assert
+		assertErrorWithSubStr(t, err, `invalid big.Int value supplied: "12abc"`)
+		assertErrorWithSubStr(t, err, `invalid big.Float value supplied: "1e99999999999"`)
+		assertErrorWithSubStr(t, err, `invalid *big.Rat value supplied: "1/0"`)
+	})
+
+	tRun(t, "values are marshalled exactly", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["SUPPLY"] = "123456789012345678901234567890"
+		mockEnvVarMap["PRICE"] = "0.1000000000000000000001"
+		mockEnvVarMap["RATIO"] = "0.25"
+		var in testObj
+		Process(&in, mockEnv())
+
+		// Act
+		env, err := Marshal(&in)
+
+		// Assert
+		assertEqual(t, err, nil)
+		assertEqual(t, env["SUPPLY"], "123456789012345678901234567890")
+		assertEqual(t, env["MODULUS"], "")
+		assertEqual(t, env["PRICE"], "0.1000000000000000000001")
+		assertEqual(t, env["RATIO"], "1/4")
+	})
+}
+
+func TestProcess_JSON(t *testing.T) {
+	type retry struct {
+		Attempts int           `json:"attempts"`
+		Backoff  time.Duration `json:"backoff"`
+	}
+	type testObj struct {
+		Retry    retry             `env:"RETRY,json"`
+		Limits   *retry            `env:"LIMITS,json"`
+		Labels   map[string]any    `env:"LABELS,json"`
+		Hosts    []string          `env:"HOSTS,json"`
+		Raw      json.RawMessage   `env:"RAW"`
+		Optional map[string]string `env:"OPTIONAL,json"`
+	}
+
+	tRun(t, "values are decoded as JSON", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["RETRY"] = `{"attempts":3,"backoff":1000}`
+		mockEnvVarMap["LIMITS"] = `{"attempts":5}`
+		mockEnvVarMap["LABELS"] = `{"team":"core","tier":1}`
+		mockEnvVarMap["HOSTS"] = `["a,b","c"]`
+		mockEnvVarMap["RAW"] = `{"nested": [1, 2]}`
+
+		// Act
+		var in testObj
+		Process(&in, mockEnv())
+
+		// Assert
+		assertEqual(t, in.Retry, retry{Attempts: 3, Backoff: 1000})
+		assertEqual(t, in.Limits.Attempts, 5)
+		assertEqual(t, in.Labels["tier"], any(float64(1)))
+		assertEqual(t, in.Hosts[0], "a,b")
+		assertEqual(t, string(in.Raw), `{"nested": [1, 2]}`)
+		assertEqual(t, in.Optional == nil, true)
+	})
+
+	tRun(t, "malformed documents are reported", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["RETRY"] = `{"attempts":"three"}`
+		mockEnvVarMap["RAW"] = `{"nested":`
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv())
+
+		// Assert
+		assertErrorWithSubStr(t, err, `invalid envconf.retry value supplied`)
+		assertErrorWithSubStr(t, err, `invalid json.RawMessage value supplied`)
+	})
+
+	tRun(t, "values round trip", func(t *testing.T) {
+		// Arrange
+		in := testObj{
+			Retry:  retry{Attempts: 2},
+			Labels: map[string]any{"team": "core"},
+			Hosts:  []string{"a"},
+			Raw:    json.RawMessage(`[1,2]`),
+		}
+
+		// Act
+		err := RoundTripCheck(in)
+
+		// Assert
+		assertEqual(t, err, nil)
+	})
+}
+
+func TestProcess_YAML(t *testing.T) {
+	type probe struct {
+		Path   string `json:"path"`
+		Period int    `json:"period"`
+	}
+	type testObj struct {
+		Probe  probe          `env:"PROBE,yaml"`
+		Labels map[string]any `env:"LABELS,yaml"`
+	}
+
+	tRun(t, "the attribute requires a registered implementation", func(t *testing.T) {
+		// Arrange
+		codecsMu.Lock()
+		saved, ok := codecs[formatYAML]
+		delete(codecs, formatYAML)
+		codecsMu.Unlock()
+		defer func() {
+			if ok {
+				RegisterYAML(saved.marshal, saved.unmarshal)
+			}
+		}()
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv())
+
+		// Assert
+		assertErrorWithSubStr(t, err, `no decoder registered (see RegisterYAML)`)
+	})
+
+	// JSON is a subset of YAML, so it stands in for a YAML implementation.
+	RegisterYAML(json.Marshal, json.Unmarshal)
+
+	tRun(t, "values are decoded as YAML", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["PROBE"] = `{"path":"/healthz","period":10}`
+		mockEnvVarMap["LABELS"] = `{"team":"core"}`
+
+		// Act
+		var in testObj
+		Process(&in, mockEnv())
+
+		// Assert
+		assertEqual(t, in.Probe, probe{Path: "/healthz", Period: 10})
+		assertEqual(t, in.Labels["team"], any("core"))
+	})
+
+	tRun(t, "json and yaml are mutually exclusive", func(t *testing.T) {
+		// Arrange
+		var in struct {
+			Probe probe `env:"PROBE,json,yaml"`
+		}
+
+		// Act
+		err := ProcessE(&in, mockEnv())
+
+		// Assert
+		assertErrorWithSubStr(t, err, `json and yaml struct tag attributes are mutually exclusive`)
+	})
+
+	tRun(t, "values round trip", func(t *testing.T) {
+		// Arrange
+		in := testObj{
+			Probe:  probe{Path: "/ready", Period: 5},
+			Labels: map[string]any{"team": "core"},
+		}
+
+		// Act
+		err := RoundTripCheck(in)
+
+		// Assert
+		assertEqual(t, err, nil)
+	})
+}
+
+func TestProcess_SlogLevel(t *testing.T) {
+	type testObj struct {
+		Level   slog.Level   `env:"LOG_LEVEL,default=info"`
+		Audit   *slog.Level  `env:"AUDIT_LEVEL"`
+		Modules []slog.Level `env:"MODULE_LEVELS"`
+	}
+
+	tRun(t, "names, offsets and integers are accepted", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["AUDIT_LEVEL"] = "WARN+2"
+		mockEnvVarMap["MODULE_LEVELS"] = "DEBUG,error,-8,12"
+
+		// Act
+		var in testObj
+		Process(&in, mockEnv())
+
+		// Assert
+		assertEqual(t, in.Level, slog.LevelInfo)
+		assertEqual(t, *in.Audit, slog.LevelWarn+2)
+		assertEqual(t, in.Modules[0], slog.LevelDebug)
+		assertEqual(t, in.Modules[1], slog.LevelError)
+		assertEqual(t, in.Modules[2], slog.Level(-8))
+		assertEqual(t, in.Modules[3], slog.Level(12))
+	})
+
+	tRun(t, "unknown levels are reported", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["LOG_LEVEL"] = "verbose"
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv())
+
+		// Assert
+		assertErrorWithSubStr(t, err, `invalid slog.Level value supplied: "verbose"`)
+	})
+
+	tRun(t, "values round trip", func(t *testing.T) {
+		// Arrange
+		audit := slog.LevelError + 1
+		in := testObj{Level: slog.LevelDebug, Audit: &audit, Modules: []slog.Level{-8, 12}}
+
+		// Act
+		err := RoundTripCheck(in)
+
+		// Assert
+		assertEqual(t, err, nil)
+	})
+}
+
+func TestProcess_MailAddress(t *testing.T) {
+	type testObj struct {
+		From     mail.Address   `env:"ALERT_FROM"`
+		To       []mail.Address `env:"ALERT_TO"`
+		CC       []mail.Address `env:"ALERT_CC,separator=;"`
+		Escalate *mail.Address  `env:"ESCALATE_TO"`
+	}
+
+	tRun(t, "addresses and address lists are parsed", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["ALERT_FROM"] = "Alerts <alerts@example.com>"
+		mockEnvVarMap["ALERT_TO"] = `"Doe, Jane" <jane@example.com>, ops@example.com`
+		mockEnvVarMap["ALERT_CC"] = "a@example.com;B <b@example.com>"
+		mockEnvVarMap["ESCALATE_TO"] = "oncall@example.com"
+
+		// Act
+		var in testObj
+		Process(&in, mockEnv())
+
+		// Assert
+		assertEqual(t, in.From, mail.Address{Name: "Alerts", Address: "alerts@example.com"})
+		assertEqual(t, len(in.To), 2)
+		assertEqual(t, in.To[0], mail.Address{Name: "Doe, Jane", Address: "jane@example.com"})
+		assertEqual(t, in.To[1], mail.Address{Address: "ops@example.com"})
+		assertEqual(t, in.CC[1], mail.Address{Name: "B", Address: "b@example.com"})
+		assertEqual(t, in.Escalate.Address, "oncall@example.com")
+	})
+
+	tRun(t, "malformed addresses name the variable", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["ALERT_TO"] = "ops@example.com, not an address"
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv())
+
+		// Assert
+		var pe *ParseError
+		assertEqual(t, errors.As(err, &pe), true)
+		assertEqual(t, pe.Var, "ALERT_TO")
+		assertEqual(t, pe.Kind, "[]mail.Address")
+	})
+
+	tRun(t, "values round trip", func(t *testing.T) {
+		// Arrange
+		in := testObj{
+			From: mail.Address{Name: "Alerts", Address: "alerts@example.com"},
+			To:   []mail.Address{{Name: "Doe, Jane", Address: "jane@example.com"}, {Address: "ops@example.com"}},
+			CC:   []mail.Address{{Address: "a@example.com"}},
+		}
+
+		// Act
+		err := RoundTripCheck(in)
+
+		// Assert
+		assertEqual(t, err, nil)
+	})
+}
+
+func TestProcess_IntegerBase(t *testing.T) {
+	type testObj struct {
+		Mask  uint32 `env:"MASK,base=0"`
+		Perms int    `env:"PERMS,base=0"`
+		Flags uint8  `env:"FLAGS,base=0"`
+		Plain int    `env:"PLAIN,base=0"`
+		Hex   uint64 `env:"HEX,base=16"`
+		Bits  []int8 `env:"BITS,base=2"`
+	}
+
+	tRun(t, "prefixed literals are parsed", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["MASK"] = "0xFFFF_0000"
+		mockEnvVarMap["PERMS"] = "0o755"
+		mockEnvVarMap["FLAGS"] = "0b1010"
+		mockEnvVarMap["PLAIN"] = "-42"
+		mockEnvVarMap["HEX"] = "deadBEEF"
+		mockEnvVarMap["BITS"] = "101,-11"
+
+		// Act
+		var in testObj
+		Process(&in, mockEnv())
+
+		// Assert
+		assertEqual(t, in.Mask, uint32(0xFFFF0000))
+		assertEqual(t, in.Perms, 0o755)
+		assertEqual(t, in.Flags, uint8(0b1010))
+		assertEqual(t, in.Plain, -42)
+		assertEqual(t, in.Hex, uint64(0xdeadbeef))
+		assertEqual(t, in.Bits[0], int8(5))
+		assertEqual(t, in.Bits[1], int8(-3))
+	})
+
+	tRun(t, "out of range literals are reported", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["FLAGS"] = "0x100"
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv())
+
+		// Assert
+		assertErrorWithSubStr(t, err, `invalid uint8 value supplied: "0x100"`)
+	})
+
+	tRun(t, "unsupported bases are rejected", func(t *testing.T) {
+		// Arrange
+		var in struct {
+			N int `env:"N,base=36"`
+		}
+
+		// Act
+		err := ProcessE(&in, mockEnv())
+
+		// Assert
+		assertErrorWithSubStr(t, err, `invalid base struct tag attribute: "36"`)
+	})
+
+	tRun(t, "values round trip", func(t *testing.T) {
+		// Arrange
+		in := testObj{Mask: 0xFF, Perms: 0o644, Hex: 0xCAFE, Bits: []int8{-1, 6}}
+
+		// Act
+		env, err := Marshal(in)
+		rtErr := RoundTripCheck(in)
+
+		// Assert
+		assertEqual(t, err, nil)
+		assertEqual(t, env["HEX"], "cafe")
+		assertEqual(t, env["BITS"], "-1,110")
+		assertEqual(t, rtErr, nil)
+	})
+}
+
+func TestProcess_DigitSeparators(t *testing.T) {
+	type testObj struct {
+		Limit   int64   `env:"LIMIT"`
+		Budget  uint    `env:"BUDGET,default=1_000_000"`
+		Ratio   float64 `env:"RATIO"`
+		Mask    uint32  `env:"MASK,base=16"`
+		Offsets []int   `env:"OFFSETS"`
+	}
+
+	tRun(t, "underscores between digits are ignored", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["LIMIT"] = "-2_500"
+		mockEnvVarMap["RATIO"] = "1_000.000_5"
+		mockEnvVarMap["MASK"] = "ff_ff"
+		mockEnvVarMap["OFFSETS"] = "1_0,2_0"
+
+		// Act
+		var in testObj
+		Process(&in, mockEnv())
+
+		// Assert
+		assertEqual(t, in.Limit, int64(-2500))
+		assertEqual(t, in.Budget, uint(1000000))
+		assertEqual(t, in.Ratio, 1000.0005)
+		assertEqual(t, in.Mask, uint32(0xffff))
+		assertEqual(t, in.Offsets[1], 20)
+	})
+
+	tRun(t, "misplaced underscores are reported", func(t *testing.T) {
+		for _, val := range []string{"_1", "1_", "1__0", "-_1"} {
+			// Arrange
+			mockEnvVarMap["LIMIT"] = val
+
+			// Act
+			var in testObj
+			err := ProcessE(&in, mockEnv())
+
+			// Assert
+			var pe *ParseError
+			assertEqual(t, errors.As(err, &pe), true)
+			assertErrorWithSubStr(t, pe.Err, "underscores must separate digits")
+		}
+	})
+}
+
+func TestProcess_LenientBool(t *testing.T) {
+	type testObj struct {
+		Cache   bool   `env:"CACHE,lenientbool"`
+		Metrics *bool  `env:"METRICS,lenientbool,default=on"`
+		Flags   []bool `env:"FLAGS,lenientbool"`
+		Strict  bool   `env:"STRICT"`
+	}
+
+	tRun(t, "ops-friendly values are accepted in any case", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["CACHE"] = "Enabled"
+		mockEnvVarMap["FLAGS"] = "YES,no,Off,y,1,false"
+
+		// Act
+		var in testObj
+		Process(&in, mockEnv())
+
+		// Assert
+		assertEqual(t, in.Cache, true)
+		assertEqual(t, *in.Metrics, true)
+		assertEqual(t, len(in.Flags), 6)
+		for i, want := range []bool{true, false, false, true, true, false} {
+			assertEqual(t, in.Flags[i], want)
+		}
+	})
+
+	tRun(t, "values are strict without the attribute", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["STRICT"] = "yes"
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv())
+
+		// Assert
+		assertErrorWithSubStr(t, err, `invalid bool value supplied: "yes"`)
+	})
+
+	tRun(t, "unknown values are reported", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["CACHE"] = "maybe"
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv())
+
+		// Assert
+		assertErrorWithSubStr(t, err, `invalid bool value supplied: "maybe"`)
+	})
+}
+
+func TestProcess_FileMode(t *testing.T) {
+	type testObj struct {
+		Umask  fs.FileMode   `env:"UMASK,default=0022"`
+		Data   fs.FileMode   `env:"DATA_MODE"`
+		Shared *fs.FileMode  `env:"SHARED_MODE"`
+		Modes  []fs.FileMode `env:"MODES"`
+	}
+
+	tRun(t, "modes are parsed as octal", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["DATA_MODE"] = "640"
+		mockEnvVarMap["SHARED_MODE"] = "0o2775"
+		mockEnvVarMap["MODES"] = "0600,1777"
+
+		// Act
+		var in testObj
+		Process(&in, mockEnv())
+
+		// Assert
+		assertEqual(t, in.Umask, fs.FileMode(0o022))
+		assertEqual(t, in.Data, fs.FileMode(0o640))
+		assertEqual(t, *in.Shared, fs.ModeSetgid|0o775)
+		assertEqual(t, in.Modes[0], fs.FileMode(0o600))
+		assertEqual(t, in.Modes[1], fs.ModeSticky|0o777)
+	})
+
+	tRun(t, "invalid modes are reported", func(t *testing.T) {
+		tests := map[string]string{
+			"0644x": "invalid syntax",
+			"0800":  "invalid syntax",
+			"10000": "file mode 10000 out of range [0, 07777]",
+		}
+		for val, msg := range tests {
+			// Arrange
+			mockEnvVarMap["DATA_MODE"] = val
+
+			// Act
+			var in testObj
+			err := ProcessE(&in, mockEnv())
+
+			// Assert
+			var pe *ParseError
+			assertEqual(t, errors.As(err, &pe), true)
+			assertEqual(t, pe.Kind, "fs.FileMode")
+			assertErrorWithSubStr(t, pe.Err, msg)
+		}
+	})
+
+	tRun(t, "values round trip", func(t *testing.T) {
+		// Arrange
+		shared := fs.ModeSetuid | 0o755
+		in := testObj{Umask: 0o027, Data: 0o600, Shared: &shared, Modes: []fs.FileMode{0o644}}
+
+		// Act
+		env, err := Marshal(in)
+		rtErr := RoundTripCheck(in)
+
+		// Assert
+		assertEqual(t, err, nil)
+		assertEqual(t, env["SHARED_MODE"], "4755")
+		assertEqual(t, env["UMASK"], "0027")
+		assertEqual(t, rtErr, nil)
+	})
+}
+
+func TestProcess_Percent(t *testing.T) {
+	type testObj struct {
+		Sampling  float64   `env:"TRACE_SAMPLING,percent,default=10%"`
+		Threshold float32   `env:"CPU_THRESHOLD,percent"`
+		Ratios    []float64 `env:"RATIOS,percent"`
+	}
+
+	tRun(t, "percentages and fractions are normalised", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["CPU_THRESHOLD"] = "0.75"
+		mockEnvVarMap["RATIOS"] = "100%,12.5 %,0,1"
+
+		// Act
+		var in testObj
+		Process(&in, mockEnv())
+
+		// Assert
+		assertEqual(t, in.Sampling, 0.1)
+		assertEqual(t, in.Threshold, float32(0.75))
+		assertEqual(t, in.Ratios[0], 1.0)
+		assertEqual(t, in.Ratios[1], 0.125)
+		assertEqual(t, in.Ratios[3], 1.0)
+	})
+
+	tRun(t, "values outside the range are reported", func(t *testing.T) {
+		tests := map[string]string{
+			"75":   "75 out of range [0, 1]",
+			"101%": "101% out of range [0, 1]",
+			"-5%":  "-5% out of range [0, 1]",
+			"NaN":  "NaN out of range [0, 1]",
+			"half": "invalid syntax",
+		}
+		for val, msg := range tests {
+			// Arrange
+			mockEnvVarMap["TRACE_SAMPLING"] = val
+
+			// Act
+			var in testObj
+			err := ProcessE(&in, mockEnv())
+
+			// Assert
+			var pe *ParseError
+			assertEqual(t, errors.As(err, &pe), true)
+			assertErrorWithSubStr(t, pe.Err, msg)
+		}
+	})
+
+	tRun(t, "values round trip", func(t *testing.T) {
+		// Arrange
+		in := testObj{Sampling: 0.05, Threshold: 0.9, Ratios: []float64{0.5}}
+
+		// Act
+		err := RoundTripCheck(in)
+
+		// Assert
+		assertEqual(t, err, nil)
+	})
+}
@@ -0,0 +1,132 @@
+package envconf
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// processPrefixMap populates the map `fv` at path `field` from every variable
+// whose name starts with `prefix` (see the `prefixmap` attribute), keyed by
+// the remainder of the name. `isZero` reports whether `fv` held its zero value
+// beforehand.
+func (o *options) processPrefixMap(fv reflect.Value, prefix, field string, tag fieldTag, isZero bool) error {
+	if fv.Kind() != reflect.Map || isLeafType(fv.Type()) {
+		return fmt.Errorf("field %q: %s attribute requires a map", field, tagAttrPrefixMap)
+	}
+
+	keys, vals, err := o.lookupPrefixed(prefix, tag)
+	if err != nil {
+		return fmt.Errorf("field %q: %w", field, err)
+	}
+
+	switch {
+	case len(vals) > 0:
+	case o.overwrite == OverwriteIfSet && !isZero:
+		return nil // Only a value from the source may replace this one.
+	case tag.defaultVal != "":
+		// Defaults are delimited lists of pairs, as for other maps.
+		val, ok, err := o.prepareValue(prefix, field, tag.defaultVal, tag, fv.Type())
+		if err != nil || !ok {
+			return err
+		}
+		if err := setField(fv, val, tag); err != nil {
+			return &ParseError{Var: prefix, Field: field, Value: tag.redact(val),
+				Kind: typeName(fv.Type()), Err: tag.redactErr(err)}
+		}
+		return nil
+	case tag.required:
+		return &MissingError{Var: prefix + "*", Field: field}
+	default:
+		return nil
+	}
+
+	var (
+		errs []error
+		m    = reflect.MakeMapWithSize(fv.Type(), len(vals))
+	)
+	for i, val := range vals {
+		val, ok, err := o.prepareValue(keys[i], field, val, tag, fv.Type().Elem())
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if !ok {
+			return errors.Join(errs...) // Leave the field untouched.
+		}
+
+		k := reflect.New(fv.Type().Key()).Elem()
+		if err := setField(k, strings.TrimPrefix(keys[i], prefix), tag); err != nil {
+			errs = append(errs, &ParseError{Var: keys[i], Field: field,
+				Value: strings.TrimPrefix(keys[i], prefix),
+				Kind:  typeName(fv.Type().Key()), Err: err})
+			continue
+		}
+		elem := reflect.New(fv.Type().Elem()).Elem()
+		if err := setField(elem, val, tag); err != nil {
+			errs = append(errs, &ParseError{Var: keys[i], Field: field, Value: tag.redact(val),
+				Kind: typeName(fv.Type().Elem()), Err: tag.redactErr(err)})
+			continue
+		}
+		m.SetMapIndex(k, elem)
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...) // Leave the field untouched.
+	}
+	fv.Set(m)
+
+	return nil
+}
+
+// lookupPrefixed returns, in sorted order, the names and values of every
+// non-empty variable whose name extends `prefix`. The variables are
+// discovered by listing the keys of the sources named by the field's `source`
+// attribute or, if there are none, of the configured Lookuper, each of which
+// must implement Enumerator.
+func (o *options) lookupPrefixed(prefix string, tag fieldTag) ([]string, []string, error) {
+	ls := []Lookuper{o.lookuper}
+	if len(tag.sources) > 0 {
+		ls = ls[:0]
+		for _, name := range tag.sources {
+			l, ok := o.sources[name]
+			if !ok {
+				return nil, nil, fmt.Errorf("unknown source %q", name)
+			}
+			ls = append(ls, l)
+		}
+	}
+
+	var candidates []string
+	for _, l := range ls {
+		enum, ok := l.(Enumerator)
+		if !ok {
+			return nil, nil, fmt.Errorf(
+				"%s attribute requires a Lookuper that implements Enumerator",
+				tagAttrPrefixMap)
+		}
+		for _, k := range enum.Keys() {
+			if len(k) > len(prefix) && strings.HasPrefix(k, prefix) {
+				candidates = append(candidates, k)
+			}
+		}
+	}
+	sort.Strings(candidates)
+
+	var keys, vals []string
+	for i, k := range candidates {
+		if i > 0 && candidates[i-1] == k {
+			continue // Listed by more than one source.
+		}
+		val, _, err := o.lookupField(k, tag)
+		if err != nil {
+			return nil, nil, &SourceError{Var: k, Op: opLookup, Err: err}
+		}
+		if val != "" {
+			keys, vals = append(keys, k), append(vals, val)
+		}
+	}
+
+	return keys, vals, nil
+}
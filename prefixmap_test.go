@@ -0,0 +1,112 @@
+package envconf
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestProcess_PrefixMap(t *testing.T) {
+	tRun(t, "collects variables keyed by their suffix", func(t *testing.T) {
+		// Arrange
+		type testObj struct {
+			Features map[string]string `env:"FEATURE_,prefixmap"`
+		}
+		mockEnvVarMap["FEATURE_SEARCH"] = "on"
+		mockEnvVarMap["FEATURE_BETA_UI"] = "off"
+		mockEnvVarMap["FEATURE_"] = "ignored"
+		mockEnvVarMap["FEATURES"] = "ignored"
+		mockEnvVarMap["FEATURE_EMPTY"] = ""
+
+		// Act
+		var in testObj
+		Process(&in, mockEnv())
+
+		// Assert
+		assertEqual(t, len(in.Features), 2)
+		assertEqual(t, in.Features["SEARCH"], "on")
+		assertEqual(t, in.Features["BETA_UI"], "off")
+	})
+
+	tRun(t, "values are parsed as the map's element type", func(t *testing.T) {
+		// Arrange
+		type testObj struct {
+			Limits map[string]int `env:"LIMIT_,prefixmap"`
+		}
+		mockEnvVarMap["LIMIT_READ"] = "10"
+		mockEnvVarMap["LIMIT_WRITE"] = "x"
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv())
+
+		// Assert
+		var pe *ParseError
+		assertEqual(t, errors.As(err, &pe), true)
+		assertEqual(t, pe.Var, "LIMIT_WRITE")
+		assertEqual(t, in.Limits == nil, true)
+	})
+
+	tRun(t, "defaults and required apply when nothing matches", func(t *testing.T) {
+		// Arrange
+		type testObj struct {
+			Features map[string]string `env:"FEATURE_,prefixmap,default=a:1"`
+			Labels   map[string]string `env:"LABEL_,prefixmap,required"`
+		}
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv())
+
+		// Assert
+		var me *MissingError
+		assertEqual(t, errors.As(err, &me), true)
+		assertEqual(t, me.Var, "LABEL_*")
+		assertEqual(t, in.Features["a"], "1")
+	})
+
+	tRun(t, "the lookuper must enumerate its keys", func(t *testing.T) {
+		// Arrange
+		type testObj struct {
+			Features map[string]string `env:"FEATURE_,prefixmap"`
+		}
+		l := LookuperFunc(func(string) (string, bool) { return "", false })
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, WithLookuper(l))
+
+		// Assert
+		assertErrorWithSubStr(t, err, `prefixmap attribute requires a Lookuper that implements Enumerator`)
+	})
+
+	tRun(t, "non-map fields are rejected", func(t *testing.T) {
+		// Arrange
+		type testObj struct {
+			Features []string `env:"FEATURE_,prefixmap"`
+		}
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv())
+
+		// Assert
+		assertErrorWithSubStr(t, err, `field "Features": prefixmap attribute requires a map`)
+	})
+
+	tRun(t, "values round trip", func(t *testing.T) {
+		// Arrange
+		type testObj struct {
+			Features map[string]string `env:"FEATURE_,prefixmap"`
+		}
+		in := testObj{Features: map[string]string{"SEARCH": "on", "BETA": "a,b"}}
+
+		// Act
+		env, err := Marshal(in)
+		rtErr := RoundTripCheck(in)
+
+		// Assert
+		assertEqual(t, err, nil)
+		assertEqual(t, env["FEATURE_BETA"], "a,b")
+		assertEqual(t, rtErr, nil)
+	})
+}
@@ -0,0 +1,64 @@
+package envconf
+
+import (
+	"context"
+	"fmt"
+	"runtime/pprof"
+)
+
+// profileSection runs `fn` under the pprof label envconf_section=`name`. `fn`
+// receives a copy of `o` whose context carries the label, so that labels
+// applied to nested lookups retain the section.
+func (o *options) profileSection(name string, fn func(*options)) {
+	pprof.Do(o.ctx, pprof.Labels("envconf_section", name),
+		func(ctx context.Context) {
+			so := *o
+			so.ctx = ctx
+			fn(&so)
+		})
+}
+
+// lookup retrieves `key` from the configured Lookuper, applying pprof labels
+// if enabled.
+func (o *options) lookup(key string) (string, bool, error) {
+	return o.lookupIn(o.lookuper, key)
+}
+
+// lookupIn retrieves `key` from `l`, applying pprof labels if enabled.
+func (o *options) lookupIn(l Lookuper, key string) (string, bool, error) {
+	if !o.profileLabels {
+		return lookupContext(o.ctx, l, key)
+	}
+
+	return profiledLookup(o.ctx, l, key)
+}
+
+// profiledLookup retrieves `key` from `l` under the pprof label
+// envconf_source. The members of a MultiLookuper are labelled individually.
+func profiledLookup(ctx context.Context, l Lookuper, key string) (string, bool, error) {
+	if m, ok := l.(multiLookuper); ok {
+		for _, ml := range m {
+			val, ok, err := profiledLookup(ctx, ml, key)
+			if err != nil || ok {
+				return val, ok, err
+			}
+		}
+		return "", false, nil
+	}
+
+	var (
+		val string
+		ok  bool
+		err error
+	)
+	pprof.Do(ctx, sourceLabels(l), func(ctx context.Context) {
+		val, ok, err = lookupContext(ctx, l, key)
+	})
+
+	return val, ok, err
+}
+
+// sourceLabels returns the pprof label set identifying the source `src`.
+func sourceLabels(src any) pprof.LabelSet {
+	return pprof.Labels("envconf_source", fmt.Sprintf("%T", src))
+}
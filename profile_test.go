@@ -0,0 +1,48 @@
+package envconf
+
+import (
+	"runtime/pprof"
+	"testing"
+)
+
+func TestProcess_ProfilerLabels(t *testing.T) {
+	tRun(t, "processing is unaffected", func(t *testing.T) {
+		// Arrange
+		type testObj struct {
+			Server struct {
+				Host string `env:"HOST"`
+			}
+			Password string `env:"PASSWORD"`
+		}
+		mockEnvVarMap["HOST"] = "localhost"
+		mockEnvVarMap["PASSWORD"] = "ref://password"
+
+		// Act
+		var in testObj
+		Process(&in,
+			WithProfilerLabels(),
+			WithLookuper(MultiLookuper(MapLookuper{}, mockEnvVarMap)),
+			WithResolver("ref", ResolverFunc(func(string) (string, error) {
+				return "secret", nil
+			})),
+		)
+
+		// Assert
+		assertEqual(t, in.Server.Host, "localhost")
+		assertEqual(t, in.Password, "secret")
+	})
+
+	tRun(t, "section label is carried by the context", func(t *testing.T) {
+		// Arrange
+		o := newOptions([]Option{WithProfilerLabels()})
+
+		// Act
+		var label string
+		o.profileSection("Database", func(so *options) {
+			label, _ = pprof.Label(so.ctx, "envconf_section")
+		})
+
+		// Assert
+		assertEqual(t, label, "Database")
+	})
+}
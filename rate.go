@@ -0,0 +1,93 @@
+package envconf
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// rateUnits maps the bare units accepted as periods by ParseRate to their
+// length.
+var rateUnits = map[string]time.Duration{
+	"ms": time.Millisecond,
+	"s":  time.Second,
+	"m":  time.Minute,
+	"h":  time.Hour,
+	"d":  24 * time.Hour,
+}
+
+// Rate is a rate limit, parsed from values such as "100/s", "5000/m" or
+// "10/100ms:20" (see ParseRate). Its fields map directly onto
+// golang.org/x/time/rate:
+//
+//	limiter := rate.NewLimiter(rate.Limit(cfg.API.Limit), cfg.API.Burst)
+type Rate struct {
+	Limit float64 // Events per second; +Inf for no limit.
+	Burst int     // The largest number of events permitted at once.
+}
+
+// ParseRate parses `s` as a Rate, in the form EVENTS/PERIOD[:BURST]. PERIOD
+// is a unit ("ms", "s", "m", "h" or "d") or a duration such as "100ms" or
+// "1d12h" (see the `duration` attribute), and BURST defaults to EVENTS. "inf"
+// is an unlimited rate, with a burst of zero unless one is given.
+func ParseRate(s string) (Rate, error) {
+	spec, burst, hasBurst := strings.Cut(s, ":")
+
+	var r Rate
+	if spec == "inf" {
+		r.Limit = math.Inf(1)
+	} else {
+		count, period, ok := strings.Cut(spec, "/")
+		if !ok {
+			return Rate{}, fmt.Errorf("invalid rate %q: expected EVENTS/PERIOD", s)
+		}
+		n, err := strconv.ParseFloat(count, 64)
+		if err != nil || n < 0 || math.IsInf(n, 0) || math.IsNaN(n) {
+			return Rate{}, fmt.Errorf("invalid rate %q: invalid event count", s)
+		}
+		d, ok := rateUnits[period]
+		if !ok {
+			if d, err = parseDuration(period); err != nil || d <= 0 {
+				return Rate{}, fmt.Errorf("invalid rate %q: invalid period", s)
+			}
+		}
+		r.Limit = n / d.Seconds()
+		r.Burst = int(math.Ceil(n))
+	}
+
+	if hasBurst {
+		b, err := strconv.Atoi(burst)
+		if err != nil || b < 0 {
+			return Rate{}, fmt.Errorf("invalid rate %q: invalid burst", s)
+		}
+		r.Burst = b
+	}
+
+	return r, nil
+}
+
+// String returns the rate in the form accepted by ParseRate, in events per
+// second.
+func (r Rate) String() string {
+	if math.IsInf(r.Limit, 1) {
+		return "inf:" + strconv.Itoa(r.Burst)
+	}
+	return strconv.FormatFloat(r.Limit, 'g', -1, 64) + "/s:" + strconv.Itoa(r.Burst)
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (r *Rate) UnmarshalText(b []byte) error {
+	parsed, err := ParseRate(string(b))
+	if err != nil {
+		return err
+	}
+	*r = parsed
+	return nil
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (r Rate) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
@@ -0,0 +1,100 @@
+package envconf
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestParseRate(t *testing.T) {
+	tRun(t, "rates are parsed", func(t *testing.T) {
+		tests := map[string]Rate{
+			"100/s":       {Limit: 100, Burst: 100},
+			"6000/m":      {Limit: 100, Burst: 6000},
+			"10/100ms:20": {Limit: 100, Burst: 20},
+			"1/1d12h":     {Limit: 1 / (36 * time.Hour).Seconds(), Burst: 1},
+			"0.5/s:1":     {Limit: 0.5, Burst: 1},
+			"inf":         {Limit: math.Inf(1)},
+			"inf:5":       {Limit: math.Inf(1), Burst: 5},
+		}
+		for s, want := range tests {
+			// Act
+			r, err := ParseRate(s)
+
+			// Assert
+			assertEqual(t, err, nil)
+			assertEqual(t, r, want)
+		}
+	})
+
+	tRun(t, "malformed rates are rejected", func(t *testing.T) {
+		tests := map[string]string{
+			"100":     "expected EVENTS/PERIOD",
+			"x/s":     "invalid event count",
+			"-1/s":    "invalid event count",
+			"100/y":   "invalid period",
+			"100/0s":  "invalid period",
+			"100/s:x": "invalid burst",
+		}
+		for s, msg := range tests {
+			// Act
+			_, err := ParseRate(s)
+
+			// Assert
+			assertErrorWithSubStr(t, err, msg)
+		}
+	})
+}
+
+func TestProcess_Rate(t *testing.T) {
+	type testObj struct {
+		API    Rate   `env:"API_RATE,default=100/s"`
+		Export *Rate  `env:"EXPORT_RATE"`
+		Tiers  []Rate `env:"TIER_RATES,separator=;"`
+	}
+
+	tRun(t, "rates are parsed", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["EXPORT_RATE"] = "5000/m:50"
+		mockEnvVarMap["TIER_RATES"] = "10/s;inf"
+
+		// Act
+		var in testObj
+		Process(&in, mockEnv())
+
+		// Assert
+		assertEqual(t, in.API, Rate{Limit: 100, Burst: 100})
+		assertEqual(t, in.Export.Burst, 50)
+		assertEqual(t, math.IsInf(in.Tiers[1].Limit, 1), true)
+	})
+
+	tRun(t, "invalid rates are reported", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["API_RATE"] = "fast"
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv())
+
+		// Assert
+		var pe *ParseError
+		assertEqual(t, errors.As(err, &pe), true)
+		assertEqual(t, pe.Var, "API_RATE")
+		assertEqual(t, pe.Kind, "envconf.Rate")
+	})
+
+	tRun(t, "values round trip", func(t *testing.T) {
+		// Arrange
+		in := testObj{
+			API:   Rate{Limit: 5000.0 / 60, Burst: 10},
+			Tiers: []Rate{{Limit: 0.25, Burst: 1}, {Limit: math.Inf(1)}},
+		}
+
+		// Act
+		err := RoundTripCheck(in)
+
+		// Assert
+		assertEqual(t, err, nil)
+	})
+}
@@ -0,0 +1,131 @@
+package envconf
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Redactor masks secret values (see the `secret` attribute) wherever envconf
+// exports or reports them.
+type Redactor interface {
+	Redact(val string) string
+}
+
+// RedactorFunc is an adapter allowing an ordinary function to be used as a
+// Redactor.
+type RedactorFunc func(val string) string
+
+// Redact calls f(val).
+func (f RedactorFunc) Redact(val string) string {
+	return f(val)
+}
+
+// Names of the built-in redaction strategies, selected per field with
+// `secret=NAME`.
+const (
+	// RedactDefault is used by fields marked with a plain `secret`
+	// attribute. It initially masks values as RedactFull does; register a
+	// Redactor under this name to change the strategy globally.
+	RedactDefault = "default"
+
+	// RedactFull replaces the value entirely.
+	RedactFull = "full"
+
+	// RedactLast4 reveals only the last four characters of values of at
+	// least 12 characters, e.g. "****************wxyz".
+	RedactLast4 = "last4"
+
+	// RedactHash replaces the value with a short SHA-256 fingerprint, e.g.
+	// "sha256:9f86d081", so that values can be compared without being
+	// revealed.
+	RedactHash = "hash"
+)
+
+// redactedMask replaces redacted values.
+const redactedMask = "********"
+
+var fullRedactor = RedactorFunc(func(string) string { return redactedMask })
+
+// redactors holds the registered redaction strategies.
+var redactors = map[string]Redactor{
+	RedactDefault: fullRedactor,
+	RedactFull:    fullRedactor,
+	RedactLast4: RedactorFunc(func(val string) string {
+		if len(val) < 12 {
+			return redactedMask
+		}
+		return strings.Repeat("*", len(val)-4) + val[len(val)-4:]
+	}),
+	RedactHash: RedactorFunc(func(val string) string {
+		sum := sha256.Sum256([]byte(val))
+		return "sha256:" + hex.EncodeToString(sum[:4])
+	}),
+}
+
+var redactorsMu sync.RWMutex
+
+// RegisterRedactor registers `r` as the redaction strategy called `name`,
+// replacing any existing strategy of that name. Registering under
+// RedactDefault changes how plain `secret` fields are masked. RegisterRedactor
+// is safe for concurrent use but is intended to be called during
+// initialisation.
+func RegisterRedactor(name string, r Redactor) {
+	redactorsMu.Lock()
+	defer redactorsMu.Unlock()
+	redactors[name] = r
+}
+
+// lookupRedactor returns the redaction strategy called `name`.
+func lookupRedactor(name string) (Redactor, bool) {
+	redactorsMu.RLock()
+	defer redactorsMu.RUnlock()
+	r, ok := redactors[name]
+	return r, ok
+}
+
+// redact masks `val` if the field described by `tag` is secret.
+func (t fieldTag) redact(val string) string {
+	if !t.secret || val == "" {
+		return val
+	}
+
+	name := t.redaction
+	if name == "" {
+		name = RedactDefault
+	}
+	if r, ok := lookupRedactor(name); ok {
+		return r.Redact(val)
+	}
+	return redactedMask
+}
+
+// errSecretRejected replaces the conversion errors of secret fields, which
+// may echo the value.
+var errSecretRejected = errors.New("value rejected (details withheld for secret field)")
+
+// redactErr returns `err`, an error converting a value for the field
+// described by `tag`, or an error that cannot reveal the value if the field
+// is secret. Errors from package strconv are reduced to their underlying
+// cause, e.g. strconv.ErrRange.
+func (t fieldTag) redactErr(err error) error {
+	if !t.secret || err == nil {
+		return err
+	}
+
+	var ne *strconv.NumError
+	if errors.As(err, &ne) {
+		return ne.Err
+	}
+	return errSecretRejected
+}
+
+// Redacted is like Marshal but masks the values of fields marked `secret`
+// according to their redaction strategy, for support bundles, diagnostics
+// and other reports that must remain safe to share.
+func Redacted(v any, opts ...Option) (map[string]string, error) {
+	return newOptions(opts).marshal(v, true)
+}
@@ -0,0 +1,145 @@
+package envconf
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestRedacted(t *testing.T) {
+	type testObj struct {
+		User     string   `env:"USER"`
+		Password string   `env:"PASSWORD,secret"`
+		APIKey   string   `env:"API_KEY,secret=last4"`
+		Token    string   `env:"TOKEN,secret=hash"`
+		Short    string   `env:"SHORT,secret=last4"`
+		Keys     []string `env:"KEY,indexed,secret=full"`
+	}
+	in := testObj{
+		User:     "admin",
+		Password: "hunter2",
+		APIKey:   "sk_live_0123456789abcd",
+		Token:    "test",
+		Short:    "abcd",
+		Keys:     []string{"k0", "k1"},
+	}
+
+	tRun(t, "secrets are masked per strategy", func(t *testing.T) {
+		// Act
+		env, err := Redacted(in)
+
+		// Assert
+		assertEqual(t, err, nil)
+		assertEqual(t, env["USER"], "admin")
+		assertEqual(t, env["PASSWORD"], "********")
+		assertEqual(t, env["API_KEY"], "******************abcd")
+		assertEqual(t, env["TOKEN"], "sha256:9f86d081")
+		assertEqual(t, env["SHORT"], "********")
+		assertEqual(t, env["KEY_1"], "********")
+	})
+
+	tRun(t, "the default strategy can be replaced", func(t *testing.T) {
+		// Arrange
+		RegisterRedactor(RedactDefault, RedactorFunc(func(val string) string {
+			return strings.Repeat("x", len(val))
+		}))
+		defer RegisterRedactor(RedactDefault, fullRedactor)
+
+		// Act
+		env, _ := Redacted(in)
+
+		// Assert
+		assertEqual(t, env["PASSWORD"], "xxxxxxx")
+		assertEqual(t, env["API_KEY"], "******************abcd")
+	})
+
+	tRun(t, "templates are redacted", func(t *testing.T) {
+		// Arrange
+		var sb strings.Builder
+
+		// Act
+		err := WriteDotEnv(&sb, in)
+
+		// Assert
+		assertEqual(t, err, nil)
+		assertEqual(t, strings.Contains(sb.String(), "hunter2"), false)
+		assertEqual(t, strings.Contains(sb.String(), `PASSWORD="********"`), true)
+	})
+
+	tRun(t, "unknown strategies are rejected", func(t *testing.T) {
+		// Arrange
+		type testObj struct {
+			Password string `env:"PASSWORD,secret=rot13"`
+		}
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv())
+
+		// Assert
+		assertErrorWithSubStr(t, err, `unknown redaction strategy "rot13"`)
+	})
+}
+
+func TestProcess_SecretParseErrors(t *testing.T) {
+	type testObj struct {
+		PIN     int            `env:"PIN,secret"`
+		Weights map[string]int `env:"WEIGHT_,prefixmap,secret=last4"`
+		Ports   []int          `env:"PORT,indexed,secret"`
+		Port    int            `env:"PUBLIC_PORT"`
+	}
+
+	tRun(t, "values of secret fields are masked", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["PIN"] = "12ab"
+		mockEnvVarMap["WEIGHT_A"] = "not-a-number-1234"
+		mockEnvVarMap["PORT_0"] = "hunter2"
+		mockEnvVarMap["PUBLIC_PORT"] = "eighty"
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv())
+
+		// Assert
+		for _, secret := range []string{"12ab", "not-a-number", "hunter2"} {
+			if strings.Contains(err.Error(), secret) {
+				t.Errorf("error %q reveals secret %q", err, secret)
+			}
+		}
+		assertErrorWithSubStr(t, err, `invalid int value supplied: "********"`)
+		assertErrorWithSubStr(t, err, `invalid int value supplied: "*************1234"`)
+		assertErrorWithSubStr(t, err, `invalid int value supplied: "eighty"`)
+	})
+
+	tRun(t, "underlying errors of secret fields do not reveal values", func(t *testing.T) {
+		// Arrange
+		type testObj struct {
+			PIN   int    `env:"PIN,secret"`
+			Level string `env:"LEVEL,secret,oneof=debug|info"`
+		}
+		mockEnvVarMap["PIN"] = "99999999999999999999"
+		mockEnvVarMap["LEVEL"] = "hunter2"
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv())
+
+		// Assert
+		for _, e := range err.(interface{ Unwrap() []error }).Unwrap() {
+			var pe *ParseError
+			if !errors.As(e, &pe) {
+				t.Fatalf("expected a *ParseError, got %T", e)
+			}
+			for _, secret := range []string{"99999999999999999999", "hunter2"} {
+				if s := fmt.Sprintf("%+v", pe.Err); strings.Contains(s, secret) {
+					t.Errorf("underlying error %q reveals secret %q", s, secret)
+				}
+			}
+		}
+		if !errors.Is(err, strconv.ErrRange) {
+			t.Errorf("expected error to match strconv.ErrRange, got: %v", err)
+		}
+	})
+}
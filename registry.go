@@ -0,0 +1,148 @@
+package envconf
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// parsers holds the parsers registered with RegisterParser, keyed by the
+// reflect.Type they produce.
+var parsers sync.Map // reflect.Type -> func(string) (reflect.Value, error)
+
+// RegisterParser teaches Process to parse values of type T using `parse`,
+// for third-party types that cannot be given a SetEnvValue method, such as
+// decimal.Decimal or zapcore.Level:
+//
+//	func init() {
+//		envconf.RegisterParser(decimal.NewFromString)
+//	}
+//
+// A registered parser takes precedence over every other form of parsing for
+// T, including Setter and encoding.TextUnmarshaler, and applies wherever T
+// appears (e.g. as a slice element or map value). Registering a parser for a
+// type replaces any previous registration. RegisterParser is safe for
+// concurrent use but is intended to be called during initialisation.
+func RegisterParser[T any](parse func(string) (T, error)) {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	parsers.Store(t, func(val string) (reflect.Value, error) {
+		v, err := parse(val)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(&v).Elem(), nil
+	})
+}
+
+// registeredParser returns the parser registered for `t`, if any.
+func registeredParser(t reflect.Type) (func(string) (reflect.Value, error), bool) {
+	p, ok := parsers.Load(t)
+	if !ok {
+		return nil, false
+	}
+	return p.(func(string) (reflect.Value, error)), true
+}
+
+// implKey identifies an implementation registered with
+// RegisterImplementation.
+type implKey struct {
+	iface reflect.Type
+	name  string
+}
+
+var (
+	// implementations holds the constructors registered with
+	// RegisterImplementation.
+	implementations sync.Map // implKey -> func() (reflect.Value, error)
+
+	// implNames records the name under which each concrete type was first
+	// constructed for an interface, so that Marshal can name the
+	// implementation held by a field.
+	implNames sync.Map // [2]reflect.Type{interface, concrete} -> string
+)
+
+// RegisterImplementation registers `ctor` as the implementation of the
+// interface I named `name`, turning interface fields into driver selectors:
+// a field of type I is populated by calling the constructor registered under
+// the field's value.
+//
+//	func init() {
+//		envconf.RegisterImplementation[StorageBackend]("s3", newS3Backend)
+//		envconf.RegisterImplementation[StorageBackend]("local", newLocalBackend)
+//	}
+//
+//	type Config struct {
+//		Storage StorageBackend `env:"STORAGE_BACKEND,default=local"`
+//	}
+//
+// An unregistered name is reported as a ParseError listing the registered
+// ones, as is an error returned by the constructor. Registering a name again
+// replaces the previous constructor. RegisterImplementation panics if I is
+// not an interface type. It is safe for concurrent use but is intended to be
+// called during initialisation.
+func RegisterImplementation[I any](name string, ctor func() (I, error)) {
+	t := reflect.TypeOf((*I)(nil)).Elem()
+	if t.Kind() != reflect.Interface {
+		panic("envconf: RegisterImplementation requires an interface type, got " + t.String())
+	}
+	implementations.Store(implKey{t, name}, func() (reflect.Value, error) {
+		impl, err := ctor()
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(&impl).Elem(), nil
+	})
+}
+
+// hasImplementations reports whether any implementations of the interface
+// `t` are registered.
+func hasImplementations(t reflect.Type) bool {
+	return t.Kind() == reflect.Interface && len(implementationNames(t)) > 0
+}
+
+// implementationNames returns the sorted names of the implementations of `t`.
+func implementationNames(t reflect.Type) []string {
+	var names []string
+	implementations.Range(func(k, _ any) bool {
+		if key := k.(implKey); key.iface == t {
+			names = append(names, key.name)
+		}
+		return true
+	})
+	sort.Strings(names)
+	return names
+}
+
+// setImplementation populates `fv`, of a registered interface type, with the
+// implementation named `name`.
+func setImplementation(fv reflect.Value, name string) error {
+	ctor, ok := implementations.Load(implKey{fv.Type(), name})
+	if !ok {
+		return fmt.Errorf("unknown implementation %q (registered: %s)",
+			name, strings.Join(implementationNames(fv.Type()), ", "))
+	}
+	impl, err := ctor.(func() (reflect.Value, error))()
+	if err != nil {
+		return err
+	}
+	if !impl.IsNil() {
+		implNames.LoadOrStore([2]reflect.Type{fv.Type(), impl.Elem().Type()}, name)
+	}
+	fv.Set(impl)
+	return nil
+}
+
+// implementationName returns the name of the implementation held by `v`, of
+// a registered interface type, as recorded when it was constructed.
+func implementationName(v reflect.Value) (string, bool) {
+	if v.IsNil() {
+		return "", true
+	}
+	name, ok := implNames.Load([2]reflect.Type{v.Type(), v.Elem().Type()})
+	if !ok {
+		return "", false
+	}
+	return name.(string), true
+}
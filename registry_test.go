@@ -0,0 +1,170 @@
+package envconf
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testMoney stands in for a third-party type without a usable parsing method.
+type testMoney struct{ cents int64 }
+
+func parseTestMoney(val string) (testMoney, error) {
+	units, cents, ok := strings.Cut(val, ".")
+	if !ok || len(cents) != 2 {
+		return testMoney{}, errors.New("expected UNITS.CC")
+	}
+	n, err := strconv.ParseInt(units+cents, 10, 64)
+	return testMoney{n}, err
+}
+
+func TestRegisterParser(t *testing.T) {
+	RegisterParser(parseTestMoney)
+	RegisterParser(time.ParseDuration)
+	RegisterParser(func(string) (testLevel, error) { return 3, nil })
+	t.Cleanup(func() {
+		for _, v := range []any{testMoney{}, time.Duration(0), testLevel(0)} {
+			parsers.Delete(reflect.TypeOf(v))
+		}
+	})
+
+	type testObj struct {
+		Price   testMoney            `env:"PRICE"`
+		Prices  map[string]testMoney `env:"PRICES"`
+		Timeout time.Duration        `env:"TIMEOUT,default=1m30s"`
+		Level   testLevel            `env:"LEVEL"`
+	}
+
+	tRun(t, "registered parsers are used", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["PRICE"] = "12.34"
+		mockEnvVarMap["PRICES"] = "a:1.00,b:0.50"
+		mockEnvVarMap["LEVEL"] = "low"
+
+		// Act
+		var in testObj
+		Process(&in, mockEnv())
+
+		// Assert
+		assertEqual(t, in.Price, testMoney{1234})
+		assertEqual(t, in.Prices["b"], testMoney{50})
+		assertEqual(t, in.Timeout, 90*time.Second)
+		assertEqual(t, in.Level, testLevel(3)) // In preference to UnmarshalText.
+	})
+
+	tRun(t, "errors are reported with the type name", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["PRICE"] = "12"
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv())
+
+		// Assert
+		assertErrorWithSubStr(t, err, `invalid envconf.testMoney value supplied: "12"`)
+	})
+}
+
+// testBackend stands in for a driver interface such as a storage backend.
+type testBackend interface{ Name() string }
+
+type testS3Backend struct{ region string }
+
+func (b *testS3Backend) Name() string { return "s3:" + b.region }
+
+type testLocalBackend struct{}
+
+func (testLocalBackend) Name() string { return "local" }
+
+func TestRegisterImplementation(t *testing.T) {
+	RegisterImplementation[testBackend]("s3", func() (testBackend, error) {
+		return &testS3Backend{region: "eu-west-1"}, nil
+	})
+	RegisterImplementation[testBackend]("local", func() (testBackend, error) {
+		return testLocalBackend{}, nil
+	})
+	RegisterImplementation[testBackend]("gcs", func() (testBackend, error) {
+		return nil, errors.New("gcs credentials not found")
+	})
+	t.Cleanup(func() {
+		for _, name := range []string{"s3", "local", "gcs"} {
+			implementations.Delete(implKey{reflect.TypeOf((*testBackend)(nil)).Elem(), name})
+		}
+	})
+
+	type testObj struct {
+		Storage testBackend   `env:"STORAGE_BACKEND,default=local"`
+		Caches  []testBackend `env:"CACHE_BACKENDS"`
+	}
+
+	tRun(t, "implementations are selected by name", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["CACHE_BACKENDS"] = "s3,local"
+
+		// Act
+		var in testObj
+		Process(&in, mockEnv())
+
+		// Assert
+		assertEqual(t, in.Storage.Name(), "local")
+		assertEqual(t, in.Caches[0].Name(), "s3:eu-west-1")
+		assertEqual(t, in.Caches[1].Name(), "local")
+	})
+
+	tRun(t, "unknown names are reported", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["STORAGE_BACKEND"] = "azure"
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv())
+
+		// Assert
+		var pe *ParseError
+		assertEqual(t, errors.As(err, &pe), true)
+		assertEqual(t, pe.Kind, "envconf.testBackend")
+		assertErrorWithSubStr(t, pe.Err, `unknown implementation "azure" (registered: gcs, local, s3)`)
+	})
+
+	tRun(t, "constructor errors are reported", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["CACHE_BACKENDS"] = "local,gcs"
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv())
+
+		// Assert
+		var pe *ParseError
+		assertEqual(t, errors.As(err, &pe), true)
+		assertEqual(t, pe.Var, "CACHE_BACKENDS")
+		assertErrorWithSubStr(t, pe.Err, "element 1: gcs credentials not found")
+	})
+
+	tRun(t, "implementations are marshalled by name", func(t *testing.T) {
+		// Arrange
+		in := testObj{Storage: &testS3Backend{}, Caches: []testBackend{testLocalBackend{}}}
+
+		// Act
+		env, err := Marshal(in)
+
+		// Assert
+		assertEqual(t, err, nil)
+		assertEqual(t, env["STORAGE_BACKEND"], "s3")
+		assertEqual(t, env["CACHE_BACKENDS"], "local")
+	})
+
+	tRun(t, "non-interface types are rejected", func(t *testing.T) {
+		defer func() {
+			assertEqual(t, recover() != nil, true)
+		}()
+
+		// Act
+		RegisterImplementation[testLocalBackend]("local", func() (testLocalBackend, error) {
+			return testLocalBackend{}, nil
+		})
+	})
+}
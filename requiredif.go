@@ -0,0 +1,53 @@
+package envconf
+
+import (
+	"reflect"
+	"strings"
+)
+
+// requiredIf is a parsed `required_if` attribute: the field is required when
+// the variable `key` is set, or when it holds `value` if `hasValue` is set.
+type requiredIf struct {
+	key      string
+	value    string
+	hasValue bool
+}
+
+// parseRequiredIf parses the argument of a `required_if` attribute, either
+// VAR or VAR=VALUE.
+func parseRequiredIf(arg string) *requiredIf {
+	key, value, hasValue := strings.Cut(arg, "=")
+	return &requiredIf{key: key, value: value, hasValue: hasValue}
+}
+
+// met reports whether the condition holds. The variable is looked up with
+// the same prefix as the field (see the `prefix` attribute), so that
+// conditions refer to sibling fields in reusable structs. If a field of the
+// enclosing struct `t` is read from the variable, it is looked up as that
+// field is (see the `source` and `alias` attributes).
+func (c *requiredIf) met(o *options, t reflect.Type) (bool, error) {
+	val, _, ok, err := o.lookupAliased(o.keyPrefix+c.key, o.fieldTagOf(t, c.key))
+	if err != nil || !ok {
+		return false, err
+	}
+	if c.hasValue {
+		return val == c.value, nil
+	}
+	return val != "", nil
+}
+
+// fieldTagOf returns the tag of the field of the struct `t` read from the
+// variable `key`, or the zero tag if there is none. Malformed tags are
+// skipped; they are reported when their field is processed.
+func (o *options) fieldTagOf(t reflect.Type, key string) fieldTag {
+	for _, field := range reflect.VisibleFields(t) {
+		if !field.IsExported() || len(field.Index) > 1 {
+			continue
+		}
+		if tag, err := o.parseTag(field.Tag); err == nil && tag.key == key {
+			return tag
+		}
+	}
+
+	return fieldTag{}
+}
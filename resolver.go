@@ -0,0 +1,123 @@
+package envconf
+
+import (
+	"context"
+	"fmt"
+	"runtime/pprof"
+	"strings"
+)
+
+// Resolver resolves a secret reference, such as "op://vault/item/field", to
+// the secret value it refers to.
+type Resolver interface {
+	Resolve(ref string) (string, error)
+}
+
+// ContextResolver is implemented by Resolvers whose resolution may block (e.g.
+// network or subprocess backed). ProcessContext prefers ResolveContext over
+// Resolve when it is available.
+type ContextResolver interface {
+	Resolver
+	ResolveContext(ctx context.Context, ref string) (string, error)
+}
+
+// ResolverFunc is an adapter allowing an ordinary function to be used as a
+// Resolver.
+type ResolverFunc func(ref string) (string, error)
+
+// Resolve calls f(ref).
+func (f ResolverFunc) Resolve(ref string) (string, error) {
+	return f(ref)
+}
+
+// OnePasswordResolver resolves "op://vault/item/field" references using the
+// 1Password CLI (op read).
+type OnePasswordResolver struct{}
+
+// Resolve reads the secret referenced by `ref`.
+func (r OnePasswordResolver) Resolve(ref string) (string, error) {
+	return r.ResolveContext(context.Background(), ref)
+}
+
+// ResolveContext is like Resolve but kills the CLI if `ctx` is done first.
+func (OnePasswordResolver) ResolveContext(ctx context.Context, ref string) (string, error) {
+	out, err := runCommand(ctx, "op", "read", "--no-newline", ref)
+	if err != nil {
+		return "", err
+	}
+
+	return string(out), nil
+}
+
+// DopplerResolver resolves "doppler://project/config/NAME" references using
+// the Doppler CLI (doppler secrets get).
+type DopplerResolver struct{}
+
+// Resolve reads the secret referenced by `ref`.
+func (r DopplerResolver) Resolve(ref string) (string, error) {
+	return r.ResolveContext(context.Background(), ref)
+}
+
+// ResolveContext is like Resolve but kills the CLI if `ctx` is done first.
+func (DopplerResolver) ResolveContext(ctx context.Context, ref string) (string, error) {
+	parts := strings.Split(strings.TrimPrefix(ref, "doppler://"), "/")
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		return "", fmt.Errorf(
+			"invalid doppler reference %q: expected doppler://project/config/NAME",
+			ref)
+	}
+
+	out, err := runCommand(ctx, "doppler", "secrets", "get", parts[2], "--plain",
+		"--project", parts[0], "--config", parts[1])
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSuffix(string(out), "\n"), nil
+}
+
+// resolve returns `val` resolved through the resolver registered for its
+// scheme. Values that are not references to a registered scheme are returned
+// unchanged.
+func (o *options) resolve(val string) (string, error) {
+	r, ok := o.resolverFor(val)
+	if !ok {
+		return val, nil
+	}
+
+	if !o.profileLabels {
+		return resolveContext(o.ctx, r, val)
+	}
+
+	var (
+		resolved string
+		err      error
+	)
+	pprof.Do(o.ctx, sourceLabels(r), func(ctx context.Context) {
+		resolved, err = resolveContext(ctx, r, val)
+	})
+
+	return resolved, err
+}
+
+// resolverFor returns the Resolver registered for the scheme of `val`, if
+// `val` is a reference.
+func (o *options) resolverFor(val string) (Resolver, bool) {
+	scheme, _, ok := strings.Cut(val, "://")
+	if !ok {
+		return nil, false
+	}
+
+	r, ok := o.resolvers[scheme]
+	return r, ok
+}
+
+// resolveContext resolves `ref` using `r`, via ResolveContext if `r` is a
+// ContextResolver.
+func resolveContext(ctx context.Context, r Resolver, ref string) (string, error) {
+	if cr, ok := r.(ContextResolver); ok {
+		return cr.ResolveContext(ctx, ref)
+	}
+
+	return r.Resolve(ref)
+}
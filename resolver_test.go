@@ -0,0 +1,156 @@
+package envconf
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestProcess_Resolvers(t *testing.T) {
+	// Pre Arrange
+	type testObj struct {
+		Password string `env:"DB_PASSWORD"`
+	}
+	prefix := ResolverFunc(func(ref string) (string, error) {
+		return "resolved:" + ref, nil
+	})
+
+	tRun(t, "references with a registered scheme are resolved", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["DB_PASSWORD"] = "vault://db/password"
+
+		// Act
+		var in testObj
+		Process(&in, mockEnv(), WithResolver("vault", prefix))
+
+		// Assert
+		assertEqual(t, in.Password, "resolved:vault://db/password")
+	})
+
+	tRun(t, "values with unregistered schemes are used as is", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["DB_PASSWORD"] = "https://example.com"
+
+		// Act
+		var in testObj
+		Process(&in, mockEnv(), WithResolver("vault", prefix))
+
+		// Assert
+		assertEqual(t, in.Password, "https://example.com")
+	})
+
+	tRun(t, "resolver failures are reported", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["DB_PASSWORD"] = "vault://db/password"
+		failing := ResolverFunc(func(string) (string, error) {
+			return "", errors.New("permission denied")
+		})
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv(), WithResolver("vault", failing))
+
+		// Assert
+		assertErrorWithSubStr(t, err,
+			`failed to resolve env var "DB_PASSWORD": permission denied`)
+	})
+}
+
+func TestBuiltinResolvers(t *testing.T) {
+	// Pre Arrange
+	defer func(f func(context.Context, string, ...string) ([]byte, error)) {
+		runCommand = f
+	}(runCommand)
+	var gotArgs []string
+	runCommand = func(_ context.Context, name string, args ...string) ([]byte, error) {
+		gotArgs = append([]string{name}, args...)
+		return []byte("secret\n"), nil
+	}
+
+	tRun(t, "1password reads the reference", func(t *testing.T) {
+		// Act
+		_, err := OnePasswordResolver{}.Resolve("op://vault/item/field")
+
+		// Assert
+		assertEqual(t, err, nil)
+		assertEqual(t, slices.Equal(gotArgs, []string{
+			"op", "read", "--no-newline", "op://vault/item/field",
+		}), true)
+	})
+
+	tRun(t, "doppler splits project, config and name", func(t *testing.T) {
+		// Act
+		val, err := DopplerResolver{}.Resolve("doppler://billing/prd/DB_PASSWORD")
+
+		// Assert
+		assertEqual(t, err, nil)
+		assertEqual(t, val, "secret")
+		assertEqual(t, slices.Equal(gotArgs, []string{
+			"doppler", "secrets", "get", "DB_PASSWORD", "--plain",
+			"--project", "billing", "--config", "prd",
+		}), true)
+	})
+
+	tRun(t, "malformed doppler reference errors", func(t *testing.T) {
+		// Act
+		_, err := DopplerResolver{}.Resolve("doppler://billing/DB_PASSWORD")
+
+		// Assert
+		assertErrorWithSubStr(t, err, "invalid doppler reference")
+	})
+}
+
+func TestProcess_Diagnostics(t *testing.T) {
+	type testObj struct {
+		Port     int    `env:"PORT"`
+		Password string `env:"PASSWORD,required,secret"`
+		APIKey   string `env:"API_KEY"`
+		Plain    string `env:"PLAIN"`
+	}
+
+	tRun(t, "secret stores are not consulted", func(t *testing.T) {
+		// Arrange
+		var lookups []string
+		l := LookuperFunc(func(key string) (string, bool) {
+			lookups = append(lookups, key)
+			return mockEnvVarMap.Lookup(key)
+		})
+		mockEnvVarMap["PORT"] = "8080"
+		mockEnvVarMap["PASSWORD"] = "hunter2"
+		mockEnvVarMap["API_KEY"] = "vault://kv/api"
+		mockEnvVarMap["PLAIN"] = "https://example.com"
+		resolved := false
+		r := ResolverFunc(func(string) (string, error) {
+			resolved = true
+			return "resolved", nil
+		})
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, WithLookuper(l), WithResolver("vault", r), WithDiagnostics())
+
+		// Assert
+		assertEqual(t, err, nil)
+		assertEqual(t, in.Port, 8080)
+		assertEqual(t, in.Password, "")
+		assertEqual(t, in.APIKey, "")
+		assertEqual(t, in.Plain, "https://example.com")
+		assertEqual(t, resolved, false)
+		assertEqual(t, slices.Contains(lookups, "PASSWORD"), false)
+	})
+
+	tRun(t, "secrets are consulted otherwise", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["API_KEY"] = "vault://kv/api"
+		r := ResolverFunc(func(string) (string, error) { return "resolved", nil })
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv(), WithResolver("vault", r))
+
+		// Assert
+		assertErrorWithSubStr(t, err, `env var "PASSWORD" not set`)
+		assertEqual(t, in.APIKey, "resolved")
+	})
+}
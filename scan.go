@@ -0,0 +1,103 @@
+package envconf
+
+import (
+	"math"
+	"regexp"
+	"strings"
+)
+
+// SecretScanner inspects a value for signs that it holds a secret, returning a
+// short description of what was found (e.g. "AWS access key ID") if so.
+// Scanners must never include the value itself in the description.
+type SecretScanner interface {
+	Scan(val string) (finding string, ok bool)
+}
+
+// SecretScannerFunc is an adapter allowing an ordinary function to be used as
+// a SecretScanner.
+type SecretScannerFunc func(val string) (string, bool)
+
+// Scan calls f(val).
+func (f SecretScannerFunc) Scan(val string) (string, bool) {
+	return f(val)
+}
+
+// SecretWarning describes a value that a SecretScanner considers likely to be
+// a secret but which was assigned to a field not marked `secret`.
+type SecretWarning struct {
+	Var     string // The environment variable name.
+	Field   string // The dotted path of the struct field.
+	Finding string // The scanner's description of what was found.
+}
+
+var (
+	pemPrivateKeyRe = regexp.MustCompile(`-----BEGIN ([A-Z0-9]+ )*PRIVATE KEY( BLOCK)?-----`)
+	awsAccessKeyRe  = regexp.MustCompile(`\b(AKIA|ASIA|ABIA|ACCA)[0-9A-Z]{16}\b`)
+)
+
+var (
+	// PEMPrivateKeyScanner detects PEM encoded private keys.
+	PEMPrivateKeyScanner SecretScanner = SecretScannerFunc(func(val string) (string, bool) {
+		return "PEM private key", pemPrivateKeyRe.MatchString(val)
+	})
+
+	// AWSAccessKeyScanner detects AWS access key IDs.
+	AWSAccessKeyScanner SecretScanner = SecretScannerFunc(func(val string) (string, bool) {
+		return "AWS access key ID", awsAccessKeyRe.MatchString(val)
+	})
+)
+
+// EntropyScanner returns a SecretScanner detecting high-entropy strings, such
+// as API tokens and generated passwords: any whitespace-separated word of at
+// least `minLen` characters whose Shannon entropy is at least `threshold` bits
+// per character.
+func EntropyScanner(minLen int, threshold float64) SecretScanner {
+	return SecretScannerFunc(func(val string) (string, bool) {
+		for _, word := range strings.Fields(val) {
+			if len(word) >= minLen && entropy(word) >= threshold {
+				return "high-entropy string", true
+			}
+		}
+		return "", false
+	})
+}
+
+// DefaultSecretScanners returns the scanners used by WithSecretScanners when
+// none are supplied.
+func DefaultSecretScanners() []SecretScanner {
+	return []SecretScanner{
+		PEMPrivateKeyScanner,
+		AWSAccessKeyScanner,
+		EntropyScanner(20, 4.5),
+	}
+}
+
+// scanSecrets runs `val`, assigned to `field` from `key`, through every
+// configured scanner, reporting the first finding.
+func (o *options) scanSecrets(key, field, val string) {
+	for _, s := range o.scanners {
+		if finding, ok := s.Scan(val); ok {
+			o.warnSecret(SecretWarning{Var: key, Field: field, Finding: finding})
+			return
+		}
+	}
+}
+
+// entropy returns the Shannon entropy of `s` in bits per byte.
+func entropy(s string) float64 {
+	var counts [256]int
+	for i := 0; i < len(s); i++ {
+		counts[s[i]]++
+	}
+
+	var h float64
+	for _, c := range counts {
+		if c == 0 {
+			continue
+		}
+		p := float64(c) / float64(len(s))
+		h -= p * math.Log2(p)
+	}
+
+	return h
+}
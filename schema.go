@@ -0,0 +1,82 @@
+package envconf
+
+import (
+	"fmt"
+	"reflect"
+)
+
+const (
+	// SchemaVersionKey is the variable that must hold a struct's declared
+	// schema version when WithSchemaVersionCheck is used.
+	SchemaVersionKey = "CONFIG_SCHEMA_VERSION"
+
+	// schemaTagKey is the tag of the blank field declaring a struct's schema
+	// version.
+	schemaTagKey = "envschema"
+)
+
+// WithSchemaVersionCheck requires SchemaVersionKey to hold the schema version
+// declared by the target struct, so that manifests written for an older or
+// newer version of the struct are rejected with a clear error instead of
+// being partially applied. The version is declared with a blank field:
+//
+//	type Config struct {
+//		_ struct{} `envschema:"3"`
+//		// ...
+//	}
+//
+// A mismatch is reported as a *SchemaVersionError and no fields are
+// populated. Marshal, WriteDotEnv and WriteManifest stamp the declared
+// version into their output.
+func WithSchemaVersionCheck() Option {
+	return func(o *options) {
+		o.schemaCheck = true
+	}
+}
+
+// checkSchemaVersion verifies that SchemaVersionKey holds the schema version
+// declared by the struct type `t`.
+func (o *options) checkSchemaVersion(t reflect.Type) error {
+	declared, ok := schemaVersion(t)
+	if !ok {
+		return fmt.Errorf("%s declares no schema version (see WithSchemaVersionCheck)", t)
+	}
+
+	supplied, _, err := o.lookup(SchemaVersionKey)
+	if err != nil {
+		return &SourceError{Var: SchemaVersionKey, Op: opLookup, Err: err}
+	}
+	if supplied == "" {
+		return &MissingError{Var: SchemaVersionKey}
+	}
+	if supplied != declared {
+		return &SchemaVersionError{Declared: declared, Supplied: supplied}
+	}
+
+	return nil
+}
+
+// schemaVersion returns the schema version declared by the struct type `t`.
+func schemaVersion(t reflect.Type) (string, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		if f := t.Field(i); f.Name == "_" {
+			if v, ok := f.Tag.Lookup(schemaTagKey); ok && v != "" {
+				return v, true
+			}
+		}
+	}
+	return "", false
+}
+
+// schemaVersionOf is like schemaVersion for the struct (or pointer to struct)
+// `v`.
+func schemaVersionOf(v any) (string, bool) {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return "", false
+	}
+	return schemaVersion(t)
+}
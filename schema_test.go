@@ -0,0 +1,104 @@
+package envconf
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type schemaTestConfig struct {
+	_    struct{} `envschema:"3"`
+	Port int      `env:"PORT"`
+}
+
+func TestProcess_SchemaVersion(t *testing.T) {
+	tRun(t, "matching versions are accepted", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap[SchemaVersionKey] = "3"
+		mockEnvVarMap["PORT"] = "80"
+
+		// Act
+		var in schemaTestConfig
+		err := ProcessE(&in, mockEnv(), WithSchemaVersionCheck())
+
+		// Assert
+		assertEqual(t, err, nil)
+		assertEqual(t, in.Port, 80)
+	})
+
+	tRun(t, "mismatched versions are rejected", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap[SchemaVersionKey] = "2"
+		mockEnvVarMap["PORT"] = "80"
+
+		// Act
+		var in schemaTestConfig
+		err := ProcessE(&in, mockEnv(), WithSchemaVersionCheck())
+
+		// Assert
+		assertEqual(t, errors.Is(err, ErrSchemaVersion), true)
+		assertEqual(t, err.Error(),
+			`config schema version mismatch: environment provides version "2" but "3" is required`)
+		assertEqual(t, in.Port, 0)
+	})
+
+	tRun(t, "a missing version is reported", func(t *testing.T) {
+		// Act
+		var in schemaTestConfig
+		err := ProcessE(&in, mockEnv(), WithSchemaVersionCheck())
+
+		// Assert
+		assertEqual(t, errors.Is(err, ErrMissing), true)
+	})
+
+	tRun(t, "structs must declare a version", func(t *testing.T) {
+		// Arrange
+		type testObj struct {
+			Port int `env:"PORT"`
+		}
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv(), WithSchemaVersionCheck())
+
+		// Assert
+		assertErrorWithSubStr(t, err, "declares no schema version")
+	})
+
+	tRun(t, "versions are not checked by default", func(t *testing.T) {
+		// Act
+		var in schemaTestConfig
+		err := ProcessE(&in, mockEnv())
+
+		// Assert
+		assertEqual(t, err, nil)
+	})
+}
+
+func TestSchemaVersion_Stamping(t *testing.T) {
+	tRun(t, "marshalled output round trips", func(t *testing.T) {
+		// Act
+		env, err := Marshal(schemaTestConfig{Port: 80})
+		rtErr := RoundTripCheck(schemaTestConfig{Port: 80}, WithSchemaVersionCheck())
+
+		// Assert
+		assertEqual(t, err, nil)
+		assertEqual(t, env[SchemaVersionKey], "3")
+		assertEqual(t, rtErr, nil)
+	})
+
+	tRun(t, "generated templates are stamped", func(t *testing.T) {
+		// Arrange
+		var dotEnv, manifest bytes.Buffer
+
+		// Act
+		WriteDotEnv(&dotEnv, schemaTestConfig{})
+		WriteManifest(&manifest, schemaTestConfig{})
+
+		// Assert
+		assertEqual(t, strings.HasPrefix(dotEnv.String(), "CONFIG_SCHEMA_VERSION=3\n\nPORT="), true)
+		assertEqual(t, strings.HasPrefix(manifest.String(),
+			"env:\n  - name: CONFIG_SCHEMA_VERSION\n    value: \"3\"\n"), true)
+	})
+}
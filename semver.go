@@ -0,0 +1,281 @@
+package envconf
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var semVerType = reflect.TypeOf(SemVer{})
+
+// SemVer is a semantic version (see https://semver.org), parsed from values
+// such as "1.4.2", "v2.0.0-rc.1" or "1.0.0+build.5". A leading "v" is
+// optional, and a missing minor or patch version is taken to be zero.
+//
+// SemVer fields and string fields holding versions may be validated with the
+// `constraint` attribute:
+//
+//	MinPeer envconf.SemVer `env:"MIN_PEER_VERSION,constraint=>=1.2.0 <2"`
+type SemVer struct {
+	Major, Minor, Patch uint64
+	Prerelease          string // E.g. "rc.1", without the leading "-".
+	Build               string // E.g. "build.5", without the leading "+".
+}
+
+// ParseSemVer parses `s` as a semantic version.
+func ParseSemVer(s string) (SemVer, error) {
+	var v SemVer
+
+	rest, build, hasBuild := strings.Cut(strings.TrimPrefix(s, "v"), "+")
+	rest, pre, hasPre := strings.Cut(rest, "-")
+	if hasBuild && build == "" || hasPre && pre == "" {
+		return SemVer{}, fmt.Errorf("invalid semantic version %q", s)
+	}
+	v.Prerelease, v.Build = pre, build
+
+	parts := strings.Split(rest, ".")
+	if len(parts) > 3 {
+		return SemVer{}, fmt.Errorf("invalid semantic version %q", s)
+	}
+	nums := []*uint64{&v.Major, &v.Minor, &v.Patch}
+	for i, p := range parts {
+		n, err := parseSemVerNumber(p)
+		if err != nil {
+			return SemVer{}, fmt.Errorf("invalid semantic version %q", s)
+		}
+		*nums[i] = n
+	}
+	if !validSemVerIdents(v.Prerelease, true) || !validSemVerIdents(v.Build, false) {
+		return SemVer{}, fmt.Errorf("invalid semantic version %q", s)
+	}
+
+	return v, nil
+}
+
+// parseSemVerNumber parses a numeric version component, which may not have
+// leading zeros.
+func parseSemVerNumber(s string) (uint64, error) {
+	if s == "" || len(s) > 1 && s[0] == '0' {
+		return 0, errors.New("invalid number")
+	}
+	return strconv.ParseUint(s, 10, 64)
+}
+
+// validSemVerIdents reports whether `s` is empty or a valid dot-separated
+// list of pre-release (`pre`) or build identifiers.
+func validSemVerIdents(s string, pre bool) bool {
+	if s == "" {
+		return true
+	}
+	for _, id := range strings.Split(s, ".") {
+		if id == "" {
+			return false
+		}
+		numeric := true
+		for _, r := range id {
+			switch {
+			case r >= '0' && r <= '9':
+			case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r == '-':
+				numeric = false
+			default:
+				return false
+			}
+		}
+		if pre && numeric && len(id) > 1 && id[0] == '0' {
+			return false
+		}
+	}
+	return true
+}
+
+// String returns the version in its canonical form, without a leading "v".
+func (v SemVer) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.Prerelease != "" {
+		s += "-" + v.Prerelease
+	}
+	if v.Build != "" {
+		s += "+" + v.Build
+	}
+	return s
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (v *SemVer) UnmarshalText(b []byte) error {
+	parsed, err := ParseSemVer(string(b))
+	if err != nil {
+		return err
+	}
+	*v = parsed
+	return nil
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (v SemVer) MarshalText() ([]byte, error) {
+	return []byte(v.String()), nil
+}
+
+// Compare returns -1, 0 or +1 as `v` has lower, equal or higher precedence
+// than `w`. Build metadata is ignored.
+func (v SemVer) Compare(w SemVer) int {
+	for _, c := range [][2]uint64{{v.Major, w.Major}, {v.Minor, w.Minor}, {v.Patch, w.Patch}} {
+		if c[0] != c[1] {
+			if c[0] < c[1] {
+				return -1
+			}
+			return 1
+		}
+	}
+
+	switch {
+	case v.Prerelease == w.Prerelease:
+		return 0
+	case v.Prerelease == "":
+		return 1 // A release follows its pre-releases.
+	case w.Prerelease == "":
+		return -1
+	}
+
+	a, b := strings.Split(v.Prerelease, "."), strings.Split(w.Prerelease, ".")
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if c := compareSemVerIdent(a[i], b[i]); c != 0 {
+			return c
+		}
+	}
+	switch {
+	case len(a) < len(b):
+		return -1
+	case len(a) > len(b):
+		return 1
+	}
+	return 0
+}
+
+// compareSemVerIdent compares two pre-release identifiers: numerically if
+// both are numeric, numeric identifiers before alphanumeric ones, and
+// lexically otherwise.
+func compareSemVerIdent(a, b string) int {
+	an, aErr := strconv.ParseUint(a, 10, 64)
+	bn, bErr := strconv.ParseUint(b, 10, 64)
+	switch {
+	case aErr == nil && bErr == nil:
+		switch {
+		case an < bn:
+			return -1
+		case an > bn:
+			return 1
+		}
+		return 0
+	case aErr == nil:
+		return -1
+	case bErr == nil:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+// semVerConstraint is a parsed `constraint` attribute: a conjunction of
+// comparisons, every one of which a version must satisfy.
+type semVerConstraint struct {
+	expr  string
+	conds []semVerCond
+}
+
+// semVerCond is a single comparison, e.g. ">=1.2.0".
+type semVerCond struct {
+	op string
+	v  SemVer
+}
+
+// semVerOps are the comparison operators accepted in constraints, longest
+// first so that prefixes match correctly. "~" permits patch-level changes and
+// "^" changes that do not modify the left-most non-zero component.
+var semVerOps = []string{">=", "<=", "!=", ">", "<", "=", "~", "^"}
+
+// parseSemVerConstraint parses a space-separated list of comparisons such as
+// ">=1.2.0 <2.0.0". A version without an operator must match exactly.
+func parseSemVerConstraint(expr string) (*semVerConstraint, error) {
+	c := &semVerConstraint{expr: expr}
+	for _, term := range strings.Fields(expr) {
+		op := "="
+		for _, o := range semVerOps {
+			if strings.HasPrefix(term, o) {
+				op = o
+				term = term[len(o):]
+				break
+			}
+		}
+		v, err := ParseSemVer(term)
+		if err != nil {
+			return nil, err
+		}
+		c.conds = append(c.conds, semVerCond{op: op, v: v})
+	}
+	if len(c.conds) == 0 {
+		return nil, errors.New("empty version constraint")
+	}
+
+	return c, nil
+}
+
+// check returns an error if `v` does not satisfy the constraint.
+func (c *semVerConstraint) check(v SemVer) error {
+	for _, cond := range c.conds {
+		if !cond.matches(v) {
+			return fmt.Errorf("version %s does not satisfy %q", v, c.expr)
+		}
+	}
+	return nil
+}
+
+// matches reports whether `v` satisfies the comparison.
+func (c semVerCond) matches(v SemVer) bool {
+	cmp := v.Compare(c.v)
+	switch c.op {
+	case "=":
+		return cmp == 0
+	case "!=":
+		return cmp != 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case "~":
+		return cmp >= 0 && v.Major == c.v.Major && v.Minor == c.v.Minor
+	case "^":
+		switch {
+		case cmp < 0 || v.Major != c.v.Major:
+			return false
+		case c.v.Major > 0:
+			return true
+		case c.v.Minor > 0:
+			return v.Minor == c.v.Minor
+		}
+		return v.Minor == 0 && v.Patch == c.v.Patch
+	}
+	return false
+}
+
+// setSemVer parses `val` as a version satisfying `c` into `fv`, a SemVer or
+// string field. String fields receive `val` unchanged.
+func setSemVer(fv reflect.Value, val string, c *semVerConstraint) error {
+	v, err := ParseSemVer(val)
+	if err != nil {
+		return err
+	}
+	if err := c.check(v); err != nil {
+		return err
+	}
+	if fv.Kind() == reflect.String {
+		fv.SetString(val)
+		return nil
+	}
+	fv.Set(reflect.ValueOf(v))
+	return nil
+}
@@ -0,0 +1,120 @@
+package envconf
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseSemVer(t *testing.T) {
+	tRun(t, "versions are parsed", func(t *testing.T) {
+		tests := map[string]SemVer{
+			"1.4.2":             {Major: 1, Minor: 4, Patch: 2},
+			"v2.0.0-rc.1":       {Major: 2, Prerelease: "rc.1"},
+			"1.0.0+build.5":     {Major: 1, Build: "build.5"},
+			"1.2":               {Major: 1, Minor: 2},
+			"1.0.0-alpha-1+sha": {Major: 1, Prerelease: "alpha-1", Build: "sha"},
+		}
+		for s, want := range tests {
+			// Act
+			v, err := ParseSemVer(s)
+
+			// Assert
+			assertEqual(t, err, nil)
+			assertEqual(t, v, want)
+		}
+	})
+
+	tRun(t, "malformed versions are rejected", func(t *testing.T) {
+		for _, s := range []string{"", "1.2.3.4", "01.2.3", "1.2.3-", "1.2.3+", "1.2.3-01", "1.x"} {
+			// Act
+			_, err := ParseSemVer(s)
+
+			// Assert
+			assertErrorWithSubStr(t, err, "invalid semantic version")
+		}
+	})
+
+	tRun(t, "precedence follows the specification", func(t *testing.T) {
+		// Arrange
+		ordered := []string{"1.0.0-alpha", "1.0.0-alpha.1", "1.0.0-alpha.beta",
+			"1.0.0-beta", "1.0.0-beta.2", "1.0.0-beta.11", "1.0.0-rc.1", "1.0.0", "1.0.1"}
+
+		for i := 1; i < len(ordered); i++ {
+			// Act
+			a, _ := ParseSemVer(ordered[i-1])
+			b, _ := ParseSemVer(ordered[i])
+
+			// Assert
+			assertEqual(t, a.Compare(b), -1)
+			assertEqual(t, b.Compare(a), 1)
+		}
+	})
+}
+
+func TestProcess_SemVer(t *testing.T) {
+	type testObj struct {
+		Peer    SemVer  `env:"MIN_PEER_VERSION,constraint=>=1.2.0 <2"`
+		API     string  `env:"API_VERSION,constraint=^0.3.0"`
+		Release *SemVer `env:"RELEASE"`
+	}
+
+	tRun(t, "versions satisfying constraints are accepted", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["MIN_PEER_VERSION"] = "v1.4.0"
+		mockEnvVarMap["API_VERSION"] = "v0.3.7"
+		mockEnvVarMap["RELEASE"] = "3.0.0-rc.2"
+
+		// Act
+		var in testObj
+		Process(&in, mockEnv())
+
+		// Assert
+		assertEqual(t, in.Peer, SemVer{Major: 1, Minor: 4})
+		assertEqual(t, in.API, "v0.3.7")
+		assertEqual(t, in.Release.String(), "3.0.0-rc.2")
+	})
+
+	tRun(t, "versions violating constraints are reported", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["MIN_PEER_VERSION"] = "2.0.0"
+		mockEnvVarMap["API_VERSION"] = "0.4.0"
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv())
+
+		// Assert
+		var pe *ParseError
+		assertEqual(t, errors.As(err, &pe), true)
+		assertEqual(t, pe.Err.Error(), `version 2.0.0 does not satisfy ">=1.2.0 <2"`)
+		assertErrorWithSubStr(t, err, `invalid string value supplied: "0.4.0"`)
+	})
+
+	tRun(t, "malformed constraints are rejected", func(t *testing.T) {
+		// Arrange
+		var in struct {
+			V SemVer `env:"V,constraint=>=one"`
+		}
+
+		// Act
+		err := ProcessE(&in, mockEnv())
+
+		// Assert
+		assertErrorWithSubStr(t, err, `invalid constraint struct tag attribute: invalid semantic version "one"`)
+	})
+
+	tRun(t, "values round trip", func(t *testing.T) {
+		// Arrange
+		in := testObj{
+			Peer:    SemVer{Major: 1, Minor: 9, Patch: 1, Build: "42"},
+			API:     "0.3.1",
+			Release: &SemVer{Major: 3, Prerelease: "beta"},
+		}
+
+		// Act
+		err := RoundTripCheck(in)
+
+		// Assert
+		assertEqual(t, err, nil)
+	})
+}
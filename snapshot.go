@@ -0,0 +1,50 @@
+package envconf
+
+import (
+	"os"
+	"strings"
+)
+
+// Snapshot returns a copy of the process environment as it is at the time of
+// the call. The result may be passed to WithLookuper to process several
+// structs against a single consistent view of the environment.
+func Snapshot() MapLookuper {
+	environ := os.Environ()
+	env := make(MapLookuper, len(environ))
+	for _, kv := range environ {
+		k, v, _ := strings.Cut(kv, "=")
+		if _, ok := env[k]; !ok {
+			env[k] = v // The first entry wins, as for os.LookupEnv.
+		}
+	}
+
+	return env
+}
+
+// envSnapshot is a snapshot of the process environment standing in for an
+// OSLookuper (see WithSnapshot), so that variables read from it are still
+// known to come from the process environment.
+type envSnapshot struct {
+	MapLookuper
+}
+
+// snapshotLookuper returns `l` with every OSLookuper it consists of (directly
+// or within the Lookupers provided by this package) replaced by `env`.
+func snapshotLookuper(l Lookuper, env MapLookuper) Lookuper {
+	switch l := l.(type) {
+	case OSLookuper, *OSLookuper:
+		return envSnapshot{env}
+	case multiLookuper:
+		ls := make(multiLookuper, len(l))
+		for i, sub := range l {
+			ls[i] = snapshotLookuper(sub, env)
+		}
+		return ls
+	case mappedLookuper:
+		return mappedLookuper{l: snapshotLookuper(l.l, env), m: l.m}
+	case prefixLookuper:
+		return prefixLookuper{prefix: l.prefix, l: snapshotLookuper(l.l, env)}
+	}
+
+	return l
+}
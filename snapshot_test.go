@@ -0,0 +1,80 @@
+package envconf
+
+import (
+	"os"
+	"testing"
+)
+
+func TestProcess_Snapshot(t *testing.T) {
+	// mutating returns a Lookuper that sets ENVCONF_TEST_B in the process
+	// environment as soon as ENVCONF_TEST_A is looked up, simulating a
+	// concurrent mutation part way through processing.
+	mutating := func() Lookuper {
+		return LookuperFunc(func(key string) (string, bool) {
+			if key == "ENVCONF_TEST_A" {
+				os.Setenv("ENVCONF_TEST_B", "new")
+			}
+			return "", false
+		})
+	}
+	type testObj struct {
+		A string `env:"ENVCONF_TEST_A"`
+		B string `env:"ENVCONF_TEST_B"`
+	}
+
+	tRun(t, "without a snapshot later lookups observe mutations", func(t *testing.T) {
+		// Arrange
+		t.Setenv("ENVCONF_TEST_A", "old")
+		t.Setenv("ENVCONF_TEST_B", "old")
+		l := MultiLookuper(mutating(), OSLookuper{})
+
+		// Act
+		var in testObj
+		Process(&in, WithLookuper(l))
+
+		// Assert
+		assertEqual(t, in.A, "old")
+		assertEqual(t, in.B, "new")
+	})
+
+	tRun(t, "with a snapshot every lookup observes the initial environment", func(t *testing.T) {
+		// Arrange
+		t.Setenv("ENVCONF_TEST_A", "old")
+		t.Setenv("ENVCONF_TEST_B", "old")
+		l := MultiLookuper(mutating(), OSLookuper{})
+
+		// Act
+		var in testObj
+		Process(&in, WithLookuper(l), WithSnapshot())
+
+		// Assert
+		assertEqual(t, in.A, "old")
+		assertEqual(t, in.B, "old")
+	})
+
+	tRun(t, "other lookupers are unaffected", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["ENVCONF_TEST_A"] = "mock"
+
+		// Act
+		var in testObj
+		Process(&in, mockEnv(), WithSnapshot())
+
+		// Assert
+		assertEqual(t, in.A, "mock")
+	})
+}
+
+func TestSnapshot(t *testing.T) {
+	tRun(t, "is unaffected by later changes", func(t *testing.T) {
+		// Arrange
+		t.Setenv("ENVCONF_TEST_A", "old")
+
+		// Act
+		env := Snapshot()
+		os.Setenv("ENVCONF_TEST_A", "new")
+
+		// Assert
+		assertEqual(t, env["ENVCONF_TEST_A"], "old")
+	})
+}
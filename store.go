@@ -0,0 +1,79 @@
+package envconf
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Store holds a configuration of type T that may be replaced atomically while
+// being read concurrently, e.g. when configuration is reloaded at runtime.
+//
+// Reads made through Use are recorded, allowing fields that are never read to
+// be reported (see Unused). The accessor functions produced by WriteAccessors
+// read through Use. Replacements can be observed with Watch.
+type Store[T any] struct {
+	cfg   atomic.Pointer[T]
+	used  sync.Map // Field path -> struct{}.
+	watch watchState[T]
+}
+
+// NewStore returns a Store holding `cfg`, which may be nil, configured by
+// `opts`.
+func NewStore[T any](cfg *T, opts ...StoreOption) *Store[T] {
+	s := new(Store[T])
+	s.cfg.Store(cfg)
+	for _, opt := range opts {
+		opt(&s.watch.opts)
+	}
+	return s
+}
+
+// Load returns the current configuration. It must not be modified.
+func (s *Store[T]) Load() *T {
+	return s.cfg.Load()
+}
+
+// Store replaces the current configuration with `cfg`.
+func (s *Store[T]) Store(cfg *T) {
+	s.replace(cfg)
+}
+
+// Reload populates a new T as Load would and, if successful, replaces the
+// current configuration with it. On error the current configuration is left
+// in place.
+func (s *Store[T]) Reload(opts ...Option) error {
+	cfg, err := Load[T](opts...)
+	if err != nil {
+		return err
+	}
+	s.replace(cfg)
+
+	return nil
+}
+
+// Use records that the field at the dotted path `field` (e.g. "Server.Port")
+// has been read and returns the current configuration.
+func (s *Store[T]) Use(field string) *T {
+	if _, ok := s.used.Load(field); !ok {
+		s.used.Store(field, struct{}{})
+	}
+	return s.cfg.Load()
+}
+
+// Unused returns the dotted paths of every tagged field of T that has not been
+// read through Use, in canonical order.
+func (s *Store[T]) Unused() []string {
+	fields, err := collectFields(new(T))
+	if err != nil {
+		return nil
+	}
+
+	var unused []string
+	for _, fi := range fields {
+		if _, ok := s.used.Load(fi.Field); !ok {
+			unused = append(unused, fi.Field)
+		}
+	}
+
+	return unused
+}
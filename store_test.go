@@ -0,0 +1,61 @@
+package envconf
+
+import (
+	"slices"
+	"sync"
+	"testing"
+)
+
+type storeTestConfig struct {
+	Port   int `env:"PORT,default=8080"`
+	Server struct {
+		Host string `env:"HOST"`
+	}
+}
+
+func TestStore(t *testing.T) {
+	tRun(t, "reload replaces the configuration", func(t *testing.T) {
+		// Arrange
+		s := NewStore[storeTestConfig](nil)
+		mockEnvVarMap["PORT"] = "9090"
+
+		// Act
+		err := s.Reload(mockEnv())
+
+		// Assert
+		assertEqual(t, err, nil)
+		assertEqual(t, s.Load().Port, 9090)
+	})
+
+	tRun(t, "failed reload keeps the current configuration", func(t *testing.T) {
+		// Arrange
+		s := NewStore(&storeTestConfig{Port: 1})
+		mockEnvVarMap["PORT"] = "x"
+
+		// Act
+		err := s.Reload(mockEnv())
+
+		// Assert
+		assertErrorWithSubStr(t, err, `invalid int value supplied: "x"`)
+		assertEqual(t, s.Load().Port, 1)
+	})
+
+	tRun(t, "unused reports fields never read", func(t *testing.T) {
+		// Arrange
+		s := NewStore(&storeTestConfig{})
+
+		// Act
+		var wg sync.WaitGroup
+		for range 10 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_ = s.Use("Port").Port
+			}()
+		}
+		wg.Wait()
+
+		// Assert
+		assertEqual(t, slices.Equal(s.Unused(), []string{"Server.Host"}), true)
+	})
+}
@@ -0,0 +1,124 @@
+package envconf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const tenantIDPlaceholder = "<ID>"
+
+// ProcessTenants extracts one configuration per tenant from a shared
+// environment.
+//
+// `pattern` describes how tenant variables are named and must contain the
+// "<ID>" placeholder followed by a separator, e.g. "TENANT_<ID>_" (a trailing
+// "*" is permitted and ignored). Tenant IDs are discovered by scanning the
+// keys of the configured Lookuper, which must therefore implement Enumerator;
+// an ID extends from the end of the text preceding "<ID>" to the first
+// occurrence of the separator. For each discovered ID a T is populated exactly
+// as Process would, with the tenant prefix prepended to every variable name,
+// including those read from sources (see WithSource):
+//
+//	type TenantConfig struct {
+//		DSN string `env:"DSN,required"`
+//	}
+//
+//	// Reads TENANT_acme_DSN, TENANT_globex_DSN, ...
+//	tenants, err := envconf.ProcessTenants[TenantConfig]("TENANT_<ID>_*")
+//
+// The returned map is keyed by tenant ID. Failures for every tenant are
+// collected and returned as a single joined error.
+func ProcessTenants[T any](pattern string, opts ...Option) (map[string]T, error) {
+	before, after, ok := strings.Cut(strings.TrimSuffix(pattern, "*"),
+		tenantIDPlaceholder)
+	if !ok || after == "" {
+		return nil, fmt.Errorf(
+			"invalid tenant pattern %q: expected %s followed by a separator",
+			pattern, tenantIDPlaceholder)
+	}
+
+	o := newOptions(opts)
+	enum, ok := o.lookuper.(Enumerator)
+	if !ok {
+		return nil, errors.New("tenant discovery requires a Lookuper that implements Enumerator")
+	}
+
+	ids := make(map[string]struct{})
+	for _, key := range enum.Keys() {
+		rest, ok := strings.CutPrefix(key, before)
+		if !ok {
+			continue
+		}
+		if i := strings.Index(rest, after); i > 0 {
+			ids[rest[:i]] = struct{}{}
+		}
+	}
+
+	sorted := make([]string, 0, len(ids))
+	for id := range ids {
+		sorted = append(sorted, id)
+	}
+	sort.Strings(sorted)
+
+	var (
+		errs    []error
+		tenants = make(map[string]T, len(sorted))
+	)
+	for _, id := range sorted {
+		var (
+			cfg T
+			to  = *o
+		)
+		prefix := before + id + after
+		to.lookuper = prefixLookuper{prefix: prefix, l: o.lookuper}
+		if len(o.sources) > 0 {
+			to.sources = make(map[string]Lookuper, len(o.sources))
+			for name, l := range o.sources {
+				to.sources[name] = prefixLookuper{prefix: prefix, l: l}
+			}
+		}
+		if err := process(&cfg, &to); err != nil {
+			errs = append(errs, fmt.Errorf("tenant %q: %w", id, err))
+			continue
+		}
+		tenants[id] = cfg
+	}
+
+	return tenants, errors.Join(errs...)
+}
+
+// prefixLookuper is a Lookuper that prepends `prefix` to every key before
+// consulting `l`.
+type prefixLookuper struct {
+	prefix string
+	l      Lookuper
+}
+
+func (p prefixLookuper) Lookup(key string) (string, bool) {
+	return p.l.Lookup(p.prefix + key)
+}
+
+func (p prefixLookuper) LookupContext(ctx context.Context, key string) (string, bool, error) {
+	return lookupContext(ctx, p.l, p.prefix+key)
+}
+
+// Keys returns the keys of `l` that start with the prefix, with the prefix
+// removed. It returns nil if `l` does not implement Enumerator.
+func (p prefixLookuper) Keys() []string {
+	enum, ok := p.l.(Enumerator)
+	if !ok {
+		return nil
+	}
+
+	var keys []string
+	for _, k := range enum.Keys() {
+		if rest, ok := strings.CutPrefix(k, p.prefix); ok && rest != "" {
+			keys = append(keys, rest)
+		}
+	}
+
+	return keys
+}
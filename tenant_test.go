@@ -0,0 +1,83 @@
+package envconf
+
+import "testing"
+
+func TestProcessTenants(t *testing.T) {
+	// Pre Arrange
+	type tenantConfig struct {
+		DSN  string `env:"DSN,required"`
+		Port int    `env:"PORT,default=5432"`
+	}
+
+	tRun(t, "discovers and processes every tenant", func(t *testing.T) {
+		// Arrange
+		l := MapLookuper{
+			"TENANT_acme_DSN":     "postgres://acme",
+			"TENANT_acme_PORT":    "6432",
+			"TENANT_globex_DSN":   "postgres://globex",
+			"UNRELATED_VARIABLE":  "x",
+			"TENANT_malformed":    "x",
+			"TENANT__DSN_MISSING": "x",
+		}
+
+		// Act
+		tenants, err := ProcessTenants[tenantConfig]("TENANT_<ID>_*",
+			WithLookuper(l))
+
+		// Assert
+		assertEqual(t, err, nil)
+		assertEqual(t, len(tenants), 2)
+		assertEqual(t, tenants["acme"].DSN, "postgres://acme")
+		assertEqual(t, tenants["acme"].Port, 6432)
+		assertEqual(t, tenants["globex"].DSN, "postgres://globex")
+		assertEqual(t, tenants["globex"].Port, 5432)
+	})
+
+	tRun(t, "sources are read under the tenant prefix", func(t *testing.T) {
+		// Arrange
+		type tenantSecrets struct {
+			Token string `env:"TOKEN,source=file"`
+		}
+		l := MapLookuper{"TENANT_acme_ID": "1", "TENANT_globex_ID": "2"}
+		file := MapLookuper{"TENANT_acme_TOKEN": "a", "TOKEN": "shared"}
+
+		// Act
+		tenants, err := ProcessTenants[tenantSecrets]("TENANT_<ID>_",
+			WithLookuper(l), WithSource("file", file))
+
+		// Assert
+		assertEqual(t, err, nil)
+		assertEqual(t, tenants["acme"].Token, "a")
+		assertEqual(t, tenants["globex"].Token, "")
+	})
+
+	tRun(t, "failures name the tenant", func(t *testing.T) {
+		// Arrange
+		l := MapLookuper{"TENANT_acme_PORT": "6432"}
+
+		// Act
+		_, err := ProcessTenants[tenantConfig]("TENANT_<ID>_", WithLookuper(l))
+
+		// Assert
+		assertErrorWithSubStr(t, err, `tenant "acme": env var "DSN" not set`)
+	})
+
+	tRun(t, "pattern without placeholder errors", func(t *testing.T) {
+		// Act
+		_, err := ProcessTenants[tenantConfig]("TENANT_*", WithLookuper(MapLookuper{}))
+
+		// Assert
+		assertErrorWithSubStr(t, err, "invalid tenant pattern")
+	})
+
+	tRun(t, "non enumerable lookuper errors", func(t *testing.T) {
+		// Arrange
+		l := LookuperFunc(func(string) (string, bool) { return "", false })
+
+		// Act
+		_, err := ProcessTenants[tenantConfig]("TENANT_<ID>_", WithLookuper(l))
+
+		// Assert
+		assertErrorWithSubStr(t, err, "requires a Lookuper that implements Enumerator")
+	})
+}
@@ -0,0 +1,119 @@
+package envconf
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// Transformer preprocesses a value before it is parsed (see the `transform`
+// attribute), e.g. to decode or decompress it.
+type Transformer interface {
+	Transform(val string) (string, error)
+}
+
+// TransformerFunc is an adapter allowing an ordinary function to be used as a
+// Transformer.
+type TransformerFunc func(val string) (string, error)
+
+// Transform calls f(val).
+func (f TransformerFunc) Transform(val string) (string, error) {
+	return f(val)
+}
+
+// infallible adapts a function that cannot fail as a Transformer.
+func infallible(f func(string) string) Transformer {
+	return TransformerFunc(func(val string) (string, error) {
+		return f(val), nil
+	})
+}
+
+// decoding returns a Transformer decoding values with the named encoding
+// (see byteEncodings).
+func decoding(encoding string) Transformer {
+	return TransformerFunc(func(val string) (string, error) {
+		b, err := decodeBytes(val, encoding)
+		return string(b), err
+	})
+}
+
+// transformers holds the registered transformers. The `trim`, `lower` and
+// `upper` attributes are shorthand for the transformers of the same names.
+var transformers = map[string]Transformer{
+	tagAttrTrim:  infallible(strings.TrimSpace),
+	tagAttrLower: infallible(strings.ToLower),
+	tagAttrUpper: infallible(strings.ToUpper),
+	"base64":     decoding("base64"),
+	"base64url":  decoding("base64url"),
+	"hex":        decoding("hex"),
+	"gunzip":     gunzipper{limit: maxGunzipSize},
+}
+
+var transformersMu sync.RWMutex
+
+// RegisterTransformer registers `t` as the transformer called `name`, for use
+// in `transform` attributes, replacing any existing transformer of that name.
+// RegisterTransformer is safe for concurrent use but is intended to be called
+// during initialisation.
+func RegisterTransformer(name string, t Transformer) {
+	transformersMu.Lock()
+	defer transformersMu.Unlock()
+	transformers[name] = t
+}
+
+// lookupTransformer returns the transformer called `name`.
+func lookupTransformer(name string) (Transformer, bool) {
+	transformersMu.RLock()
+	defer transformersMu.RUnlock()
+	t, ok := transformers[name]
+	return t, ok
+}
+
+// transform applies the transformers `names` (see the `transform` attribute)
+// to `val` in order. The built-in "gunzip" transformer is further bounded by
+// the maximum value length, if lower than its own limit.
+func (o *options) transform(val string, names []string) (string, error) {
+	for _, name := range names {
+		t, ok := lookupTransformer(name)
+		if !ok {
+			return "", fmt.Errorf("unknown transformer %q", name)
+		}
+		if g, ok := t.(gunzipper); ok && o.maxLength > 0 {
+			t = gunzipper{limit: min(g.limit, o.maxLength)}
+		}
+		var err error
+		if val, err = t.Transform(val); err != nil {
+			return "", fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return val, nil
+}
+
+// maxGunzipSize bounds the size of values decompressed by the "gunzip"
+// transformer, so that a small compressed value cannot exhaust memory.
+const maxGunzipSize = 16 << 20
+
+// gunzipper is the "gunzip" transformer, which decompresses gzip streams of
+// at most `limit` bytes.
+type gunzipper struct {
+	limit int
+}
+
+// Transform decompresses `val`, a gzip stream.
+func (g gunzipper) Transform(val string) (string, error) {
+	r, err := gzip.NewReader(strings.NewReader(val))
+	if err != nil {
+		return "", err
+	}
+	b, err := io.ReadAll(io.LimitReader(r, int64(g.limit)+1))
+	if err != nil {
+		return "", err
+	}
+	if len(b) > g.limit {
+		return "", fmt.Errorf("decompressed value exceeds limit of %d bytes: %w",
+			g.limit, ErrValueTooLarge)
+	}
+	return string(b), r.Close()
+}
@@ -0,0 +1,147 @@
+package envconf
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestProcess_Transforms(t *testing.T) {
+	type testObj struct {
+		Format string `env:"LOG_FORMAT,trim,lower,oneof=json|text"`
+		Region string `env:"REGION,upper,default=eu-west-1"`
+		Port   int    `env:"PORT,trim"`
+		Name   string `env:"NAME"`
+	}
+
+	tRun(t, "values are transformed before parsing", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["LOG_FORMAT"] = " JSON\n"
+		mockEnvVarMap["PORT"] = " 8080 "
+		mockEnvVarMap["NAME"] = " my app "
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv())
+
+		// Assert
+		assertEqual(t, err, nil)
+		assertEqual(t, in.Format, "json")
+		assertEqual(t, in.Port, 8080)
+		assertEqual(t, in.Name, " my app ") // Without transforms.
+	})
+
+	tRun(t, "defaults are transformed", func(t *testing.T) {
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv())
+
+		// Assert
+		assertEqual(t, err, nil)
+		assertEqual(t, in.Region, "EU-WEST-1")
+	})
+
+	tRun(t, "transforms apply in order", func(t *testing.T) {
+		// Act
+		tag, err := parseTag(`env:"KEY,upper,trim"`)
+
+		// Assert
+		assertEqual(t, err, nil)
+		val, err := (&options{}).transform(" a ", tag.transforms)
+		assertEqual(t, err, nil)
+		assertEqual(t, val, "A")
+	})
+}
+
+func TestProcess_TransformPipeline(t *testing.T) {
+	type testObj struct {
+		Bundle string `env:"CA_BUNDLE,transform=trim|base64|gunzip"`
+		Token  string `env:"TOKEN,trim,transform=rot13"`
+	}
+
+	RegisterTransformer("rot13", TransformerFunc(func(val string) (string, error) {
+		return strings.Map(func(r rune) rune {
+			switch {
+			case r >= 'a' && r <= 'z':
+				return 'a' + (r-'a'+13)%26
+			case r >= 'A' && r <= 'Z':
+				return 'A' + (r-'A'+13)%26
+			}
+			return r
+		}, val), nil
+	}))
+
+	gzipped := func(s string) string {
+		var b bytes.Buffer
+		w := gzip.NewWriter(&b)
+		w.Write([]byte(s))
+		w.Close()
+		return base64.StdEncoding.EncodeToString(b.Bytes())
+	}
+
+	tRun(t, "values pass through the pipeline in order", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["CA_BUNDLE"] = " " + gzipped("-----BEGIN CERTIFICATE-----") + "\n"
+		mockEnvVarMap["TOKEN"] = " frperg "
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv())
+
+		// Assert
+		assertEqual(t, err, nil)
+		assertEqual(t, in.Bundle, "-----BEGIN CERTIFICATE-----")
+		assertEqual(t, in.Token, "secret")
+	})
+
+	tRun(t, "failing transformers are reported", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["CA_BUNDLE"] = base64.StdEncoding.EncodeToString([]byte("plain"))
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv())
+
+		// Assert
+		assertErrorWithSubStr(t, err, `failed to transform env var "CA_BUNDLE": gunzip: `)
+		assertEqual(t, in.Bundle, "")
+	})
+
+	tRun(t, "decompressed values are bounded by the maximum length", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["CA_BUNDLE"] = gzipped(strings.Repeat("a", 4096))
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv(), WithMaxValueLength(1024))
+
+		// Assert
+		assertErrorWithSubStr(t, err, "decompressed value exceeds limit of 1024 bytes")
+		assertEqual(t, errors.Is(err, ErrValueTooLarge), true)
+		assertEqual(t, in.Bundle, "")
+	})
+
+	tRun(t, "decompressed values are bounded by default", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["CA_BUNDLE"] = gzipped(strings.Repeat("a", maxGunzipSize+1))
+
+		// Act
+		var in testObj
+		err := ProcessE(&in, mockEnv())
+
+		// Assert
+		assertEqual(t, errors.Is(err, ErrValueTooLarge), true)
+		assertEqual(t, in.Bundle, "")
+	})
+
+	tRun(t, "unknown transformers are a tag error", func(t *testing.T) {
+		// Act
+		_, err := parseTag(`env:"KEY,transform=trim|rot47"`)
+
+		// Assert
+		assertErrorWithSubStr(t, err, `unknown transformer "rot47"`)
+	})
+}
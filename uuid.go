@@ -0,0 +1,65 @@
+package envconf
+
+import (
+	"encoding/hex"
+	"errors"
+	"reflect"
+	"strings"
+)
+
+// isUUIDType reports whether values of type `t` are UUIDs when the field has
+// the `uuid` attribute: strings, holding the canonical form, and 16 byte
+// arrays (such as github.com/google/uuid.UUID), holding the raw bytes.
+func isUUIDType(t reflect.Type) bool {
+	return t.Kind() == reflect.String ||
+		t.Kind() == reflect.Array && t.Len() == 16 && t.Elem().Kind() == reflect.Uint8
+}
+
+// parseUUID parses a UUID in its canonical form
+// (xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx), optionally prefixed with "urn:uuid:"
+// or enclosed in braces, or as 32 hex digits. Hex digits may be of either
+// case.
+func parseUUID(val string) ([16]byte, error) {
+	var u [16]byte
+
+	s := strings.TrimPrefix(strings.ToLower(val), "urn:uuid:")
+	if len(s) == 38 && s[0] == '{' && s[37] == '}' {
+		s = s[1:37]
+	}
+	switch len(s) {
+	case 36:
+		if s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+			return u, errors.New("invalid UUID format")
+		}
+		s = s[:8] + s[9:13] + s[14:18] + s[19:23] + s[24:]
+	case 32:
+	default:
+		return u, errors.New("invalid UUID length")
+	}
+	if _, err := hex.Decode(u[:], []byte(s)); err != nil {
+		return u, errors.New("invalid UUID format")
+	}
+
+	return u, nil
+}
+
+// formatUUID returns the canonical, lower-case form of `u`.
+func formatUUID(u [16]byte) string {
+	s := hex.EncodeToString(u[:])
+	return s[:8] + "-" + s[8:12] + "-" + s[12:16] + "-" + s[16:20] + "-" + s[20:]
+}
+
+// setUUID parses `val` as a UUID into `fv`, which must be of a type accepted
+// by isUUIDType.
+func setUUID(fv reflect.Value, val string) error {
+	u, err := parseUUID(val)
+	if err != nil {
+		return err
+	}
+	if fv.Kind() == reflect.String {
+		fv.SetString(formatUUID(u))
+		return nil
+	}
+	fv.Set(reflect.ValueOf(u).Convert(fv.Type()))
+	return nil
+}
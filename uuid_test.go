@@ -0,0 +1,100 @@
+package envconf
+
+import (
+	"errors"
+	"testing"
+)
+
+// testUUID mimics github.com/google/uuid.UUID, which implements both
+// encoding.TextUnmarshaler and encoding.BinaryUnmarshaler.
+type testUUID [16]byte
+
+func (u *testUUID) UnmarshalText(b []byte) error {
+	raw, err := parseUUID(string(b))
+	if err != nil {
+		return err
+	}
+	*u = raw
+	return nil
+}
+
+func (u testUUID) MarshalText() ([]byte, error) {
+	return []byte(formatUUID(u)), nil
+}
+
+func (u *testUUID) UnmarshalBinary(b []byte) error {
+	if len(b) != 16 {
+		return errors.New("invalid UUID length")
+	}
+	copy(u[:], b)
+	return nil
+}
+
+func TestProcess_UUID(t *testing.T) {
+	type testObj struct {
+		Tenant   string     `env:"TENANT_ID,uuid"`
+		Instance [16]byte   `env:"INSTANCE_ID,uuid"`
+		Request  testUUID   `env:"REQUEST_ID"`
+		Peers    []testUUID `env:"PEER_IDS,uuid"`
+	}
+
+	tRun(t, "uuids are parsed and normalised", func(t *testing.T) {
+		// Arrange
+		mockEnvVarMap["TENANT_ID"] = "{6BA7B810-9DAD-11D1-80B4-00C04FD430C8}"
+		mockEnvVarMap["INSTANCE_ID"] = "urn:uuid:6ba7b811-9dad-11d1-80b4-00c04fd430c8"
+		mockEnvVarMap["REQUEST_ID"] = "6ba7b8129dad11d180b400c04fd430c8"
+		mockEnvVarMap["PEER_IDS"] = "6ba7b814-9dad-11d1-80b4-00c04fd430c8"
+
+		// Act
+		var in testObj
+		Process(&in, mockEnv())
+
+		// Assert
+		assertEqual(t, in.Tenant, "6ba7b810-9dad-11d1-80b4-00c04fd430c8")
+		assertEqual(t, in.Instance[0], byte(0x6b))
+		assertEqual(t, in.Instance[15], byte(0xc8))
+		assertEqual(t, formatUUID(in.Request), "6ba7b812-9dad-11d1-80b4-00c04fd430c8")
+		assertEqual(t, formatUUID(in.Peers[0]), "6ba7b814-9dad-11d1-80b4-00c04fd430c8")
+	})
+
+	tRun(t, "malformed uuids are reported", func(t *testing.T) {
+		tests := map[string]string{
+			"6ba7b810-9dad-11d1-80b4":              "invalid UUID length",
+			"6ba7b810_9dad_11d1_80b4_00c04fd430c8": "invalid UUID format",
+			"zba7b810-9dad-11d1-80b4-00c04fd430c8": "invalid UUID format",
+		}
+		for val, msg := range tests {
+			// Arrange
+			mockEnvVarMap["TENANT_ID"] = val
+
+			// Act
+			var in testObj
+			err := ProcessE(&in, mockEnv())
+
+			// Assert
+			var pe *ParseError
+			assertEqual(t, errors.As(err, &pe), true)
+			assertEqual(t, pe.Var, "TENANT_ID")
+			assertErrorWithSubStr(t, pe.Err, msg)
+		}
+	})
+
+	tRun(t, "values round trip", func(t *testing.T) {
+		// Arrange
+		in := testObj{
+			Tenant:   "6ba7b810-9dad-11d1-80b4-00c04fd430c8",
+			Instance: [16]byte{0x6b, 15: 0xc8},
+			Request:  testUUID{1},
+			Peers:    []testUUID{{2}, {3}},
+		}
+
+		// Act
+		env, err := Marshal(in)
+		rtErr := RoundTripCheck(in)
+
+		// Assert
+		assertEqual(t, err, nil)
+		assertEqual(t, env["INSTANCE_ID"], "6b000000-0000-0000-0000-0000000000c8")
+		assertEqual(t, rtErr, nil)
+	})
+}
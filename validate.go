@@ -0,0 +1,30 @@
+package envconf
+
+import (
+	"errors"
+	"reflect"
+)
+
+// Validate runs the full processing pipeline (required checks, parsing and
+// any constraints) for the struct type of `v` against `l`, without mutating
+// `v`. It reports the same errors ProcessE would, allowing an environment to
+// be vetted (e.g. by an admission controller) before it is rolled out.
+//
+// The input `v` must be a struct or a pointer to a struct; only its type is
+// used. Any `opts` are applied as for Process, except that the lookuper is
+// always `l` and no variable is removed from the process environment (see the
+// `unset` attribute).
+func Validate(v any, l Lookuper, opts ...Option) error {
+	t := reflect.TypeOf(v)
+	if t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return errors.New("expected struct or pointer to struct")
+	}
+
+	opts = append(opts[:len(opts):len(opts)], WithLookuper(l), func(o *options) {
+		o.validating = true
+	})
+	return ProcessE(reflect.New(t).Interface(), opts...)
+}
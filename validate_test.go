@@ -0,0 +1,44 @@
+package envconf
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	// Pre Arrange
+	type testObj struct {
+		Host string `env:"HOST,required"`
+		Port int    `env:"PORT"`
+	}
+
+	tRun(t, "valid environment passes without mutation", func(t *testing.T) {
+		// Arrange
+		in := testObj{Host: "original"}
+
+		// Act
+		err := Validate(&in, MapLookuper{"HOST": "proposed", "PORT": "80"})
+
+		// Assert
+		assertEqual(t, err, nil)
+		assertEqual(t, in.Host, "original")
+		assertEqual(t, in.Port, 0)
+	})
+
+	tRun(t, "invalid environment reports every failure", func(t *testing.T) {
+		// Act
+		err := Validate(testObj{}, MapLookuper{"PORT": "eighty"})
+
+		// Assert
+		assertEqual(t, errors.Is(err, ErrMissing), true)
+		assertEqual(t, errors.Is(err, ErrParse), true)
+	})
+
+	tRun(t, "non struct errors", func(t *testing.T) {
+		// Act
+		err := Validate("nope", MapLookuper{})
+
+		// Assert
+		assertErrorWithSubStr(t, err, "expected struct or pointer to struct")
+	})
+}
@@ -0,0 +1,167 @@
+package envconf
+
+import "sync"
+
+// QueuePolicy determines what happens when a Store's configuration changes
+// while its watch queue is full.
+type QueuePolicy int
+
+const (
+	// QueueCoalesce merges the change into the most recently queued one, so
+	// watchers skip intermediate configurations but always see the latest.
+	// This is the default.
+	QueueCoalesce QueuePolicy = iota
+	// QueueDrop discards the change; watchers are not told about it.
+	QueueDrop
+	// QueueBlock makes Store and Reload wait until the queue has room.
+	QueueBlock
+)
+
+// defaultQueueSize is the number of changes a Store queues for its watchers
+// unless WithWatchQueue says otherwise.
+const defaultQueueSize = 16
+
+// StoreOption configures a Store.
+type StoreOption func(*storeOptions)
+
+// storeOptions holds the configuration assembled from the StoreOption values
+// supplied to NewStore.
+type storeOptions struct {
+	queueSize int
+	policy    QueuePolicy
+}
+
+// WithWatchQueue bounds the number of changes queued for a Store's watchers
+// to `size` (at least 1) and sets the `policy` applied when the queue is full.
+func WithWatchQueue(size int, policy QueuePolicy) StoreOption {
+	return func(o *storeOptions) {
+		o.queueSize = max(size, 1)
+		o.policy = policy
+	}
+}
+
+// change is a replacement of a Store's configuration, awaiting delivery to
+// its watchers.
+type change[T any] struct {
+	prev, cur *T
+}
+
+// watcher is a callback registered with Store.Watch.
+type watcher[T any] struct {
+	id int
+	fn func(prev, cur *T)
+}
+
+// watchState holds a Store's watchers and the changes queued for them.
+// Changes are delivered by at most one dispatching goroutine at a time, which
+// is what serialises the callbacks.
+type watchState[T any] struct {
+	publishMu sync.Mutex // Orders replacements with the changes they queue.
+
+	mu          sync.Mutex
+	room        *sync.Cond // Signalled when a change is taken off the queue.
+	opts        storeOptions
+	watchers    []watcher[T]
+	nextID      int
+	queue       []change[T]
+	dispatching bool
+}
+
+// Watch registers `fn` to be called whenever the configuration is replaced
+// by Store or a successful Reload, with the previous and the new
+// configuration. Neither may be modified.
+//
+// Callbacks for a Store are never run concurrently: changes are delivered one
+// at a time, in the order they were made, and each change to every watcher in
+// the order they were registered. Delivery is asynchronous, through a queue
+// bounded by WithWatchQueue; when changes arrive faster than the callbacks
+// handle them, the queue's policy decides whether they are coalesced, dropped
+// or wait. Under QueueBlock a callback must not itself replace the
+// configuration.
+//
+// The returned function unregisters `fn`; changes already being delivered
+// may still reach it.
+func (s *Store[T]) Watch(fn func(prev, cur *T)) (cancel func()) {
+	w := &s.watch
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	id := w.nextID
+	w.nextID++
+	w.watchers = append(w.watchers, watcher[T]{id: id, fn: fn})
+
+	return func() {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+
+		for i, wt := range w.watchers {
+			if wt.id == id {
+				w.watchers = append(w.watchers[:i:i], w.watchers[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// replace swaps in `cfg` and queues the change for the Store's watchers.
+func (s *Store[T]) replace(cfg *T) {
+	w := &s.watch
+	w.publishMu.Lock()
+	defer w.publishMu.Unlock()
+
+	prev := s.cfg.Swap(cfg)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.watchers) == 0 {
+		return
+	}
+	size := w.opts.queueSize
+	if size == 0 {
+		size = defaultQueueSize
+	}
+	for len(w.queue) >= size {
+		switch w.opts.policy {
+		case QueueDrop:
+			return
+		case QueueBlock:
+			if w.room == nil {
+				w.room = sync.NewCond(&w.mu)
+			}
+			w.room.Wait()
+			continue
+		default:
+			w.queue[len(w.queue)-1].cur = cfg
+			return
+		}
+	}
+	w.queue = append(w.queue, change[T]{prev: prev, cur: cfg})
+	if !w.dispatching {
+		w.dispatching = true
+		go s.dispatch()
+	}
+}
+
+// dispatch delivers queued changes to the watchers until the queue is empty.
+func (s *Store[T]) dispatch() {
+	w := &s.watch
+	w.mu.Lock()
+	for len(w.queue) > 0 {
+		c := w.queue[0]
+		w.queue = w.queue[1:]
+		if w.room != nil {
+			w.room.Broadcast()
+		}
+		watchers := w.watchers
+		w.mu.Unlock()
+
+		for _, wt := range watchers {
+			wt.fn(c.prev, c.cur)
+		}
+
+		w.mu.Lock()
+	}
+	w.dispatching = false
+	w.mu.Unlock()
+}
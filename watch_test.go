@@ -0,0 +1,149 @@
+package envconf
+
+import (
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestStore_Watch(t *testing.T) {
+	tRun(t, "changes are delivered in order", func(t *testing.T) {
+		// Arrange
+		s := NewStore(&storeTestConfig{Port: 0})
+		var (
+			mu    sync.Mutex
+			ports []int
+			done  = make(chan struct{})
+		)
+		s.Watch(func(prev, cur *storeTestConfig) {
+			mu.Lock()
+			defer mu.Unlock()
+			ports = append(ports, prev.Port, cur.Port)
+			if cur.Port == 3 {
+				close(done)
+			}
+		})
+
+		// Act
+		for port := 1; port <= 3; port++ {
+			s.Store(&storeTestConfig{Port: port})
+		}
+		<-done
+
+		// Assert
+		assertEqual(t, slices.Equal(ports, []int{0, 1, 1, 2, 2, 3}), true)
+	})
+
+	tRun(t, "callbacks are serialised", func(t *testing.T) {
+		// Arrange
+		s := NewStore(&storeTestConfig{}, WithWatchQueue(100, QueueBlock))
+		var (
+			running, overlaps int
+			mu                sync.Mutex
+			wg                sync.WaitGroup
+		)
+		callback := func(_, _ *storeTestConfig) {
+			mu.Lock()
+			running++
+			if running > 1 {
+				overlaps++
+			}
+			mu.Unlock()
+
+			mu.Lock()
+			running--
+			mu.Unlock()
+			wg.Done()
+		}
+		s.Watch(callback)
+		s.Watch(callback)
+
+		// Act
+		wg.Add(2 * 50)
+		for i := range 50 {
+			go s.Store(&storeTestConfig{Port: i})
+		}
+		wg.Wait()
+
+		// Assert
+		assertEqual(t, overlaps, 0)
+	})
+
+	tRun(t, "a full queue coalesces changes", func(t *testing.T) {
+		// Arrange
+		s := NewStore(&storeTestConfig{}, WithWatchQueue(1, QueueCoalesce))
+		release := make(chan struct{})
+		seen := make(chan int, 10)
+		s.Watch(func(_, cur *storeTestConfig) {
+			if cur.Port == 1 {
+				<-release
+			}
+			seen <- cur.Port
+		})
+
+		// Act
+		s.Store(&storeTestConfig{Port: 1})
+		waitDispatching(s)
+		for port := 2; port <= 4; port++ {
+			s.Store(&storeTestConfig{Port: port})
+		}
+		close(release)
+
+		// Assert
+		assertEqual(t, <-seen, 1)
+		assertEqual(t, <-seen, 4)
+	})
+
+	tRun(t, "a full queue drops changes", func(t *testing.T) {
+		// Arrange
+		s := NewStore(&storeTestConfig{}, WithWatchQueue(1, QueueDrop))
+		release := make(chan struct{})
+		seen := make(chan int, 10)
+		s.Watch(func(_, cur *storeTestConfig) {
+			if cur.Port == 1 {
+				<-release
+			}
+			seen <- cur.Port
+		})
+
+		// Act
+		s.Store(&storeTestConfig{Port: 1})
+		waitDispatching(s)
+		for port := 2; port <= 4; port++ {
+			s.Store(&storeTestConfig{Port: port})
+		}
+		close(release)
+
+		// Assert
+		assertEqual(t, <-seen, 1)
+		assertEqual(t, <-seen, 2)
+		assertEqual(t, s.Load().Port, 4)
+	})
+
+	tRun(t, "cancelled watchers are not called", func(t *testing.T) {
+		// Arrange
+		s := NewStore(&storeTestConfig{})
+		called := false
+		cancel := s.Watch(func(_, _ *storeTestConfig) { called = true })
+
+		// Act
+		cancel()
+		s.Store(&storeTestConfig{Port: 1})
+
+		// Assert
+		assertEqual(t, called, false)
+	})
+}
+
+// waitDispatching waits until the first queued change of `s` has been taken
+// off the queue for delivery.
+func waitDispatching(s *Store[storeTestConfig]) {
+	for {
+		s.watch.mu.Lock()
+		n := len(s.watch.queue)
+		s.watch.mu.Unlock()
+		if n == 0 {
+			return
+		}
+	}
+}